@@ -4,12 +4,20 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strings"
 
 	"github.com/bndr/gojenkins"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/accounts"
 	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket/cloud"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/confluence"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/github"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/gitlab"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/redact"
 	"github.com/evry-ace/link-unfurl-slack-bot/src/unfurl"
 	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
 	"github.com/sirupsen/logrus"
@@ -31,8 +39,42 @@ func main() {
 
 	b := bitbucket.Client{Server: c.BitbucketServer, PAT: c.BitbucketPAT}
 
+	var bitbucketCloud bitbucket.Backend
+	if c.BitbucketCloudToken != "" || c.BitbucketCloudUsername != "" {
+		bitbucketCloud = cloud.Client{
+			Username:    c.BitbucketCloudUsername,
+			AppPassword: c.BitbucketCloudAppPassword,
+			Token:       c.BitbucketCloudToken,
+		}
+	}
+
+	var githubClient *github.Client
+	if c.GitHubServer != "" {
+		githubClient = &github.Client{Server: c.GitHubServer, APIURL: c.GitHubAPIURL, Token: c.GitHubToken}
+	}
+
+	var gitlabClient *gitlab.Client
+	if c.GitLabServer != "" {
+		gitlabClient = &gitlab.Client{Server: c.GitLabServer, APIURL: c.GitLabAPIURL, Token: c.GitLabToken}
+	}
+
+	var jiraClient *jira.Client
+	if c.JiraServer != "" {
+		jiraClient = jira.NewClient(c.JiraServer, c.JiraPAT)
+	}
+
+	var confluenceClient *confluence.Client
+	if c.ConfluenceServer != "" {
+		confluenceClient = &confluence.Client{Server: c.ConfluenceServer, PAT: c.ConfluencePAT}
+	}
+
 	ctx := context.Background()
-	j, err := gojenkins.CreateJenkins(nil, fmt.Sprintf("https://%s/", c.JenkinsServer)).Init(ctx)
+	var jenkinsAuth []interface{}
+	if c.JenkinsUser != "" {
+		jenkinsAuth = []interface{}{c.JenkinsUser, c.JenkinsToken}
+	}
+
+	j, err := gojenkins.CreateJenkins(nil, fmt.Sprintf("https://%s/", c.JenkinsServer), jenkinsAuth...).Init(ctx)
 	if err != nil {
 		logrus.Fatal(err.Error(), "jenkins client init failed")
 	}
@@ -45,26 +87,77 @@ func main() {
 		logrus.Fatal("SLACK_BOT_TOKEN must have the prefix \"xoxb-\".")
 	}
 
+	redactor, err := redact.New(c.RedactPatterns)
+	if err != nil {
+		logrus.Fatal(err.Error(), "redact patterns are invalid")
+	}
+
+	jenkinsErrorPatterns, err := unfurl.CompileJenkinsErrorPatterns(c.JenkinsConsoleErrorPatterns)
+	if err != nil {
+		logrus.Fatal(err.Error(), "jenkins console error patterns are invalid")
+	}
+
 	// Slack SDK
 	api := slack.New(
 		c.SLackBotToken,
-		slack.OptionDebug(true),
-		slack.OptionLog(log.New(os.Stdout, "api: ", log.Lshortfile|log.LstdFlags)),
+		slack.OptionLog(log.New(redact.Writer(os.Stdout, redactor), "api: ", log.Lshortfile|log.LstdFlags)),
 		slack.OptionAppLevelToken(c.SlackAppToken),
 	)
 
+	// Account linking
+	var accountManager *accounts.Manager
+	if c.AccountsKey != "" {
+		store, err := accounts.NewFileStore(c.AccountsFile, c.AccountsKey)
+		if err != nil {
+			logrus.Fatal(err.Error(), "accounts store init failed")
+		}
+
+		accountManager = &accounts.Manager{
+			Logger:          logrus.StandardLogger(),
+			Store:           store,
+			Cipher:          store.Cipher,
+			Slack:           api,
+			BitbucketServer: c.BitbucketServer,
+			JenkinsServer:   c.JenkinsServer,
+		}
+
+		if c.BitbucketOAuthClientID != "" {
+			accountManager.OAuth = &accounts.OAuthConfig{
+				Server:       c.BitbucketServer,
+				ClientID:     c.BitbucketOAuthClientID,
+				ClientSecret: c.BitbucketOAuthClientSecret,
+				RedirectURL:  strings.TrimSuffix(c.PublicURL, "/") + "/oauth/bitbucket",
+			}
+
+			http.Handle("/oauth/bitbucket", accountManager)
+			go func() {
+				logrus.WithField("addr", c.HTTPAddr).Info("Starting HTTP server")
+				logrus.Fatal(http.ListenAndServe(c.HTTPAddr, nil))
+			}()
+		}
+	}
+
 	unfurl := unfurl.Unfurl{
-		Logger:    logrus.StandardLogger(),
-		Bitbucket: &b,
-		Jenkins:   j,
-		Config:    &c,
+		Logger:         logrus.StandardLogger(),
+		Bitbucket:      &b,
+		BitbucketCloud: bitbucketCloud,
+		GitHub:         githubClient,
+		GitLab:         gitlabClient,
+		Jira:           jiraClient,
+		Confluence:     confluenceClient,
+		Jenkins:        j,
+		Config:         &c,
+		Slack:          api,
+		Accounts:       accountManager,
+		Redactor:       redactor,
+
+		JenkinsErrorPatterns: jenkinsErrorPatterns,
 	}
 
 	// Slack Events API
 	client := socketmode.New(
 		api,
-		socketmode.OptionDebug(true),
-		socketmode.OptionLog(log.New(os.Stdout, "socketmode: ", log.Lshortfile|log.LstdFlags)),
+		socketmode.OptionLog(log.New(redact.Writer(os.Stdout, redactor), "socketmode: ", log.Lshortfile|log.LstdFlags)),
 	)
 
 	// Listen for events
@@ -140,12 +233,67 @@ func main() {
 					client.Debugf("unsupported Events API event received")
 				}
 
+			case socketmode.EventTypeSlashCommand:
+				cmd, ok := evt.Data.(slack.SlashCommand)
+				if !ok {
+					logrus.WithField("event", evt).Warn("Event type is not SlashCommand")
+					continue
+				}
+
+				logrus.WithFields(logrus.Fields{
+					"command": cmd.Command,
+					"user":    cmd.UserID,
+				}).Info("Slash command received")
+
+				text := "Account linking is not enabled."
+				if accountManager != nil {
+					text = accountManager.Command(cmd.UserID, cmd.TriggerID, cmd.Text)
+				}
+
+				if text == "" {
+					client.Ack(*evt.Request)
+					continue
+				}
+
+				client.Ack(*evt.Request, map[string]interface{}{
+					"response_type": "ephemeral",
+					"text":          text,
+				})
+
 			case socketmode.EventTypeHello:
 				//numConnections := evt.Request.NumConnections
 				logrus.WithField("evt", fmt.Sprintf("%v", evt)).Info("Hello event received")
 
 			case socketmode.EventTypeInteractive:
-				logrus.WithField("evt", fmt.Sprintf("%v", evt)).Info("Interactive event received")
+				callback, ok := evt.Data.(slack.InteractionCallback)
+				if !ok {
+					logrus.WithField("type", evt.Type).Warn("Event type is not InteractionCallback")
+					continue
+				}
+
+				// The payload is not logged as modal submissions may hold tokens
+				logrus.WithFields(logrus.Fields{
+					"type":     callback.Type,
+					"callback": callback.CallbackID,
+					"user":     callback.User.ID,
+				}).Info("Interactive event received")
+
+				if callback.Type == slack.InteractionTypeViewSubmission && callback.View.CallbackID == accounts.LinkCallbackID {
+					if accountManager != nil {
+						client.Ack(*evt.Request, accountManager.Submission(callback))
+					} else {
+						client.Ack(*evt.Request)
+					}
+					continue
+				}
+
+				client.Ack(*evt.Request)
+
+				go func() {
+					if err := unfurl.Interaction(callback); err != nil {
+						logrus.WithError(err).WithField("callback", callback.CallbackID).Error("Failed to handle interaction")
+					}
+				}()
 
 			default:
 				logrus.WithFields(logrus.Fields{
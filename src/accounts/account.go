@@ -0,0 +1,165 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Credential is a linked identity in a backend service
+type Credential struct {
+	User         string    `json:"user"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// IsExpired returns true if the credential has an expiry which has passed
+func (c Credential) IsExpired() bool {
+	return !c.Expiry.IsZero() && time.Now().After(c.Expiry.Add(-time.Minute))
+}
+
+// Account is a Slack user and the identities they have linked
+type Account struct {
+	SlackUserID string      `json:"slackUserId"`
+	Bitbucket   *Credential `json:"bitbucket,omitempty"`
+	Jenkins     *Credential `json:"jenkins,omitempty"`
+}
+
+// Store stores linked accounts
+type Store interface {
+	Get(slackUserID string) (Account, bool, error)
+	Put(account Account) error
+	Delete(slackUserID string) error
+}
+
+// FileStore is a Store that keeps accounts in a JSON file with all tokens
+// encrypted.
+type FileStore struct {
+	Path   string
+	Cipher *Cipher
+
+	mu sync.Mutex
+}
+
+// NewFileStore returns a FileStore for the given path and base64 encoded key.
+func NewFileStore(path string, key string) (*FileStore, error) {
+	c, err := NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{Path: path, Cipher: c}, nil
+}
+
+// Get returns the account for a Slack user
+func (s *FileStore) Get(slackUserID string) (Account, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.read()
+	if err != nil {
+		return Account{}, false, err
+	}
+
+	account, ok := accounts[slackUserID]
+	if !ok {
+		return Account{}, false, nil
+	}
+
+	account, err = s.crypt(account, s.Cipher.Decrypt)
+	return account, err == nil, err
+}
+
+// Put creates or replaces the account for a Slack user
+func (s *FileStore) Put(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := s.crypt(account, s.Cipher.Encrypt)
+	if err != nil {
+		return err
+	}
+
+	accounts[account.SlackUserID] = encrypted
+	return s.write(accounts)
+}
+
+// Delete removes the account for a Slack user
+func (s *FileStore) Delete(slackUserID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	delete(accounts, slackUserID)
+	return s.write(accounts)
+}
+
+// read returns all accounts in the file with their tokens still encrypted
+func (s *FileStore) read() (map[string]Account, error) {
+	accounts := map[string]Account{}
+
+	data, err := ioutil.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return accounts, nil
+	} else if err != nil {
+		return accounts, err
+	}
+
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return accounts, err
+	}
+
+	return accounts, nil
+}
+
+// write replaces the file with the given accounts
+func (s *FileStore) write(accounts map[string]Account) error {
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.Path)
+}
+
+// crypt returns a copy of the account with all tokens passed through fn
+func (s *FileStore) crypt(account Account, fn func(string) (string, error)) (Account, error) {
+	for _, c := range []**Credential{&account.Bitbucket, &account.Jenkins} {
+		if *c == nil {
+			continue
+		}
+
+		credential := **c
+		var err error
+
+		if credential.Token, err = fn(credential.Token); err != nil {
+			return account, err
+		}
+
+		if credential.RefreshToken, err = fn(credential.RefreshToken); err != nil {
+			return account, err
+		}
+
+		*c = &credential
+	}
+
+	return account, nil
+}
@@ -0,0 +1,54 @@
+package accounts
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+
+	s, err := NewFileStore(path, testKey)
+	assert.NilError(t, err)
+
+	t.Run("should not find unknown users", func(t *testing.T) {
+		_, ok, err := s.Get("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("should store accounts with encrypted tokens", func(t *testing.T) {
+		err := s.Put(Account{
+			SlackUserID: "U123",
+			Bitbucket:   &Credential{User: "user-d", Token: "bitbucket-token", RefreshToken: "refresh-token"},
+			Jenkins:     &Credential{User: "jdoe", Token: "jenkins-token"},
+		})
+		assert.NilError(t, err)
+
+		data, err := ioutil.ReadFile(path)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(string(data), "user-d"))
+		assert.Assert(t, !strings.Contains(string(data), "bitbucket-token"))
+		assert.Assert(t, !strings.Contains(string(data), "refresh-token"))
+		assert.Assert(t, !strings.Contains(string(data), "jenkins-token"))
+
+		account, ok, err := s.Get("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "bitbucket-token", account.Bitbucket.Token)
+		assert.Equal(t, "refresh-token", account.Bitbucket.RefreshToken)
+		assert.Equal(t, "jenkins-token", account.Jenkins.Token)
+	})
+
+	t.Run("should delete accounts", func(t *testing.T) {
+		assert.NilError(t, s.Delete("U123"))
+
+		_, ok, err := s.Get("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, false, ok)
+	})
+}
@@ -0,0 +1,113 @@
+package accounts
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Cipher encrypts and decrypts tokens with AES-GCM so they are never stored in
+// plain text, and signs values with HMAC-SHA256.
+type Cipher struct {
+	signKey []byte
+	aead    cipher.AEAD
+}
+
+// NewCipher returns a Cipher for a base64 encoded 32 byte key. Separate keys
+// for encrypting and signing are derived from it.
+func NewCipher(key string) (*Cipher, error) {
+	k, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	if len(k) != 32 {
+		return nil, fmt.Errorf("invalid encryption key: expected 32 bytes but got %d", len(k))
+	}
+
+	block, err := aes.NewCipher(deriveKey(k, "encrypt"))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{signKey: deriveKey(k, "sign"), aead: aead}, nil
+}
+
+// deriveKey returns a 32 byte key for a single purpose from the master key.
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// randomToken returns a random URL safe string with 32 bytes of entropy.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Encrypt returns the encrypted plain text as a base64 encoded string.
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plain text of a string encrypted with Encrypt.
+func (c *Cipher) Decrypt(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	nonce, text := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, text, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+// Sign returns a base64 encoded HMAC of the message.
+func (c *Cipher) Sign(message string) string {
+	mac := hmac.New(sha256.New, c.signKey)
+	mac.Write([]byte(message))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if the signature was made by Sign for the message.
+func (c *Cipher) Verify(message string, signature string) bool {
+	return hmac.Equal([]byte(c.Sign(message)), []byte(signature))
+}
@@ -0,0 +1,75 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const (
+	testKey  = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	otherKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func TestNewCipher(t *testing.T) {
+	t.Run("should reject keys that are not base64", func(t *testing.T) {
+		_, err := NewCipher("not base64!")
+		assert.ErrorContains(t, err, "invalid encryption key")
+	})
+
+	t.Run("should reject keys that are not 32 bytes", func(t *testing.T) {
+		_, err := NewCipher("c2hvcnQ=")
+		assert.ErrorContains(t, err, "expected 32 bytes but got 5")
+	})
+}
+
+func TestCipherEncrypt(t *testing.T) {
+	c, err := NewCipher(testKey)
+	assert.NilError(t, err)
+
+	t.Run("should decrypt what it encrypted", func(t *testing.T) {
+		encrypted, err := c.Encrypt("my-secret-token")
+		assert.NilError(t, err)
+		assert.Assert(t, encrypted != "my-secret-token")
+
+		plain, err := c.Decrypt(encrypted)
+		assert.NilError(t, err)
+		assert.Equal(t, "my-secret-token", plain)
+	})
+
+	t.Run("should not decrypt with another key", func(t *testing.T) {
+		encrypted, err := c.Encrypt("my-secret-token")
+		assert.NilError(t, err)
+
+		other, err := NewCipher(otherKey)
+		assert.NilError(t, err)
+
+		_, err = other.Decrypt(encrypted)
+		assert.Assert(t, err != nil)
+	})
+
+	t.Run("should keep empty strings empty", func(t *testing.T) {
+		encrypted, err := c.Encrypt("")
+		assert.NilError(t, err)
+		assert.Equal(t, "", encrypted)
+	})
+}
+
+func TestCipherSign(t *testing.T) {
+	c, err := NewCipher(testKey)
+	assert.NilError(t, err)
+
+	signature := c.Sign("U123")
+	assert.Assert(t, c.Verify("U123", signature))
+	assert.Assert(t, !c.Verify("U456", signature))
+
+	t.Run("should not sign with the master or encryption key", func(t *testing.T) {
+		key, err := base64.StdEncoding.DecodeString(testKey)
+		assert.NilError(t, err)
+
+		assert.Assert(t, !bytes.Equal(key, c.signKey))
+		assert.Assert(t, !bytes.Equal(deriveKey(key, "encrypt"), c.signKey))
+	})
+}
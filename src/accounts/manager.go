@@ -0,0 +1,516 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// ServiceBitbucket is the name of the Bitbucket service in commands
+	ServiceBitbucket = "bitbucket"
+	// ServiceJenkins is the name of the Jenkins service in commands
+	ServiceJenkins = "jenkins"
+
+	// LinkCallbackID is the callback ID of the modals asking for tokens
+	LinkCallbackID = "accounts_link"
+
+	// Block and action IDs of the token modal inputs
+	linkUser  = "user"
+	linkToken = "token"
+)
+
+// ErrUnknownService is returned for services that can not be linked
+var ErrUnknownService = errors.New("unknown service")
+
+// ViewOpener is the part of the Slack API used for opening the token modals
+type ViewOpener interface {
+	OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+}
+
+// Manager links Slack users to their Bitbucket and Jenkins accounts
+type Manager struct {
+	Logger          *logrus.Logger
+	Store           Store
+	Cipher          *Cipher
+	OAuth           *OAuthConfig
+	Slack           ViewOpener
+	BitbucketServer string
+	JenkinsServer   string
+
+	// links are the nonces of link URLs returned by the slash command, and
+	// states the OAuth states of users sent to Bitbucket.
+	links  nonces
+	states nonces
+
+	// refreshing locks the Bitbucket token of a user while it is refreshed
+	refreshing userLocks
+}
+
+// Bitbucket returns the Bitbucket credential of a Slack user, refreshing the
+// token if it has expired.
+func (m *Manager) Bitbucket(slackUserID string) (Credential, bool, error) {
+	account, ok, err := m.Store.Get(slackUserID)
+	if err != nil || !ok || account.Bitbucket == nil {
+		return Credential{}, false, err
+	}
+
+	credential := *account.Bitbucket
+	if !credential.IsExpired() {
+		return credential, true, nil
+	}
+
+	if credential.RefreshToken == "" || m.OAuth == nil {
+		return Credential{}, false, nil
+	}
+
+	// Refresh tokens are single use, so only one refresh per user may run at
+	// a time. The account is read again once the lock is held as another
+	// unfurl may already have refreshed the token.
+	unlock := m.refreshing.lock(slackUserID)
+	defer unlock()
+
+	account, ok, err = m.Store.Get(slackUserID)
+	if err != nil || !ok || account.Bitbucket == nil {
+		return Credential{}, false, err
+	}
+
+	credential = *account.Bitbucket
+	if !credential.IsExpired() {
+		return credential, true, nil
+	}
+
+	if credential.RefreshToken == "" {
+		return Credential{}, false, nil
+	}
+
+	token, err := m.OAuth.Refresh(credential.RefreshToken)
+	if err != nil {
+		return Credential{}, false, fmt.Errorf("failed to refresh Bitbucket token: %w", err)
+	}
+
+	credential.Token = token.AccessToken
+	credential.Expiry = token.Expiry()
+	if token.RefreshToken != "" {
+		credential.RefreshToken = token.RefreshToken
+	}
+
+	account.Bitbucket = &credential
+	return credential, true, m.Store.Put(account)
+}
+
+// Jenkins returns the Jenkins credential of a Slack user
+func (m *Manager) Jenkins(slackUserID string) (Credential, bool, error) {
+	account, ok, err := m.Store.Get(slackUserID)
+	if err != nil || !ok || account.Jenkins == nil {
+		return Credential{}, false, err
+	}
+
+	return *account.Jenkins, true, nil
+}
+
+// BitbucketLinkURL returns the single use URL a Slack user visits to link
+// their Bitbucket account using OAuth.
+func (m *Manager) BitbucketLinkURL(slackUserID string) (string, error) {
+	if m.OAuth == nil {
+		return "", errors.New("bitbucket OAuth is not configured")
+	}
+
+	nonce, err := m.links.add(slackUserID, linkTTL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s?%s", m.OAuth.RedirectURL, url.Values{"link": {nonce}}.Encode()), nil
+}
+
+// LinkBitbucket links a Bitbucket token to a Slack user after checking that
+// the token is valid.
+func (m *Manager) LinkBitbucket(slackUserID string, token Token) (Credential, error) {
+	client := bitbucket.Client{Server: m.BitbucketServer, PAT: token.AccessToken}
+
+	user, err := client.CurrentUser()
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to verify Bitbucket token: %w", err)
+	}
+
+	credential := Credential{
+		User:         user,
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry(),
+	}
+
+	return credential, m.update(slackUserID, func(a *Account) { a.Bitbucket = &credential })
+}
+
+// LinkJenkins links a Jenkins user and API token to a Slack user after
+// checking that the token is valid.
+func (m *Manager) LinkJenkins(slackUserID string, user string, token string) (Credential, error) {
+	httpClient := http.Client{Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://%s/me/api/json", m.JenkinsServer), nil)
+	if err != nil {
+		return Credential{}, err
+	}
+	req.SetBasicAuth(user, token)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return Credential{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return Credential{}, fmt.Errorf("failed to verify Jenkins token: HTTP request failed with unexpected status code %d", res.StatusCode)
+	}
+
+	var me struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&me); err != nil {
+		return Credential{}, err
+	}
+
+	credential := Credential{User: me.ID, Token: token}
+	return credential, m.update(slackUserID, func(a *Account) { a.Jenkins = &credential })
+}
+
+// Unlink removes a linked service, or all of them if service is empty.
+func (m *Manager) Unlink(slackUserID string, service string) error {
+	switch service {
+	case "":
+		return m.Store.Delete(slackUserID)
+	case ServiceBitbucket:
+		return m.update(slackUserID, func(a *Account) { a.Bitbucket = nil })
+	case ServiceJenkins:
+		return m.update(slackUserID, func(a *Account) { a.Jenkins = nil })
+	}
+
+	return ErrUnknownService
+}
+
+// update changes the account of a Slack user, creating it if needed.
+func (m *Manager) update(slackUserID string, fn func(*Account)) error {
+	account, ok, err := m.Store.Get(slackUserID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		account = Account{SlackUserID: slackUserID}
+	}
+
+	fn(&account)
+	return m.Store.Put(account)
+}
+
+// userLocks is a mutex per Slack user. Mutexes are removed once no one holds
+// or waits for them.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[string]*userLock
+}
+
+// userLock is the mutex of a user and the number of holders and waiters
+type userLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of a Slack user and returns the function to unlock it
+func (l *userLocks) lock(slackUserID string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*userLock{}
+	}
+
+	ul, ok := l.locks[slackUserID]
+	if !ok {
+		ul = &userLock{}
+		l.locks[slackUserID] = ul
+	}
+	ul.refs++
+	l.mu.Unlock()
+
+	ul.mu.Lock()
+
+	return func() {
+		ul.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		ul.refs--
+		if ul.refs == 0 {
+			delete(l.locks, slackUserID)
+		}
+	}
+}
+
+// ServeHTTP handles the link URL returned by the slash command, and the
+// Bitbucket OAuth redirect after the user has authorized the bot.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.OAuth == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.Query().Get("link") != "" {
+		m.startOAuth(w, r)
+		return
+	}
+
+	if e := r.URL.Query().Get("error"); e != "" {
+		http.Error(w, fmt.Sprintf("Bitbucket authorization failed: %s", e), http.StatusBadRequest)
+		return
+	}
+
+	// The state must have been issued to this browser, so that a user can
+	// not be tricked into authorizing a flow started by someone else.
+	state := r.URL.Query().Get("state")
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil || !m.Cipher.Verify(state, cookie.Value) {
+		http.Error(w, "invalid state", http.StatusBadRequest)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: r.URL.Path, MaxAge: -1, HttpOnly: true, Secure: true})
+
+	slackUserID, err := m.states.take(state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, err := m.OAuth.Exchange(r.URL.Query().Get("code"))
+	if err != nil {
+		m.Logger.WithError(err).WithField("slackUser", slackUserID).Error("Failed to exchange Bitbucket OAuth code")
+		http.Error(w, "Failed to get a token from Bitbucket", http.StatusBadGateway)
+		return
+	}
+
+	credential, err := m.LinkBitbucket(slackUserID, token)
+	if err != nil {
+		m.Logger.WithError(err).WithField("slackUser", slackUserID).Error("Failed to link Bitbucket account")
+		http.Error(w, "Failed to link Bitbucket account", http.StatusInternalServerError)
+		return
+	}
+
+	m.Logger.WithFields(logrus.Fields{
+		"slackUser":     slackUserID,
+		"bitbucketUser": credential.User,
+	}).Info("Linked Bitbucket account")
+
+	fmt.Fprintf(w, "Your Slack account is now linked to Bitbucket user %s. You can close this window.", credential.User)
+}
+
+// startOAuth uses up a link URL and sends the browser to Bitbucket with a new
+// state, which is bound to the browser with a cookie.
+func (m *Manager) startOAuth(w http.ResponseWriter, r *http.Request) {
+	slackUserID, err := m.links.take(r.URL.Query().Get("link"))
+	if err != nil {
+		http.Error(w, "This link has expired or was already used. Run the link command again.", http.StatusBadRequest)
+		return
+	}
+
+	state, err := m.states.add(slackUserID, stateTTL)
+	if err != nil {
+		http.Error(w, "Failed to start linking", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    m.Cipher.Sign(state),
+		Path:     r.URL.Path,
+		MaxAge:   int(stateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, m.OAuth.AuthURL(state), http.StatusFound)
+}
+
+// Command handles the account linking slash command and returns the response
+// for the user. Tokens are entered in a modal opened with the trigger ID of
+// the command, as command text may be logged.
+//
+//	link bitbucket   link Bitbucket using OAuth, or a personal access token
+//	link jenkins     link Jenkins using an API token
+//	unlink [bitbucket|jenkins]
+//	status
+func (m *Manager) Command(slackUserID string, triggerID string, text string) string {
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return m.help()
+	}
+
+	switch args[0] {
+	case "link":
+		return m.linkCommand(slackUserID, triggerID, args[1:])
+
+	case "unlink":
+		service := ""
+		if len(args) > 1 {
+			service = args[1]
+		}
+
+		if err := m.Unlink(slackUserID, service); err != nil {
+			return fmt.Sprintf(":x: Failed to unlink: %s", err)
+		}
+
+		return ":wave: Unlinked."
+
+	case "status":
+		return m.status(slackUserID)
+	}
+
+	return m.help()
+}
+
+// linkCommand handles the link sub command
+func (m *Manager) linkCommand(slackUserID string, triggerID string, args []string) string {
+	if len(args) == 0 || (args[0] != ServiceBitbucket && args[0] != ServiceJenkins) {
+		return m.help()
+	}
+
+	if len(args) > 1 {
+		return fmt.Sprintf(":warning: Tokens are not accepted in commands. Revoke the token you entered and run `link %s` to enter a new one.", args[0])
+	}
+
+	if args[0] == ServiceBitbucket && m.OAuth != nil {
+		linkURL, err := m.BitbucketLinkURL(slackUserID)
+		if err != nil {
+			return fmt.Sprintf(":x: %s", err)
+		}
+
+		return fmt.Sprintf(":link: <%s|Link your Bitbucket account>", linkURL)
+	}
+
+	if m.Slack == nil {
+		return ":x: Linking with a token is not available."
+	}
+
+	if _, err := m.Slack.OpenView(triggerID, linkModal(args[0])); err != nil {
+		m.Logger.WithError(err).WithField("slackUser", slackUserID).Error("Failed to open account linking modal")
+		return fmt.Sprintf(":x: %s", err)
+	}
+
+	return ""
+}
+
+// linkModal returns the modal asking for the token of a service
+func linkModal(service string) slack.ModalViewRequest {
+	input := func(id string, label string) *slack.InputBlock {
+		return slack.NewInputBlock(id,
+			slack.NewTextBlockObject(slack.PlainTextType, label, false, false),
+			slack.NewPlainTextInputBlockElement(nil, id))
+	}
+
+	title := "Link Bitbucket"
+	blocks := []slack.Block{input(linkToken, "Personal access token")}
+	if service == ServiceJenkins {
+		title = "Link Jenkins"
+		blocks = []slack.Block{input(linkUser, "User"), input(linkToken, "API token")}
+	}
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      LinkCallbackID,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Link", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: service,
+	}
+}
+
+// Submission handles the submission of a token modal. The response is sent
+// when acknowledging the submission, and shows why a token was rejected.
+func (m *Manager) Submission(cb slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	value := func(id string) string {
+		if cb.View.State == nil {
+			return ""
+		}
+
+		return strings.TrimSpace(cb.View.State.Values[id][id].Value)
+	}
+
+	var credential Credential
+	var err error
+
+	service := cb.View.PrivateMetadata
+	switch service {
+	case ServiceBitbucket:
+		credential, err = m.LinkBitbucket(cb.User.ID, Token{AccessToken: value(linkToken)})
+	case ServiceJenkins:
+		credential, err = m.LinkJenkins(cb.User.ID, value(linkUser), value(linkToken))
+	default:
+		err = ErrUnknownService
+	}
+
+	logger := m.Logger.WithFields(logrus.Fields{"slackUser": cb.User.ID, "service": service})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to link account")
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{linkToken: err.Error()})
+	}
+
+	logger.WithField("user", credential.User).Info("Linked account")
+
+	view := slack.ModalViewRequest{
+		Type:  slack.VTModal,
+		Title: cb.View.Title,
+		Close: slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf(":white_check_mark: Linked to %s user %s.", strings.Title(service), credential.User), false, false), nil, nil),
+		}},
+	}
+
+	return slack.NewUpdateViewSubmissionResponse(&view)
+}
+
+// status returns which services a Slack user has linked
+func (m *Manager) status(slackUserID string) string {
+	account, _, err := m.Store.Get(slackUserID)
+	if err != nil {
+		return fmt.Sprintf(":x: %s", err)
+	}
+
+	lines := []string{}
+	if account.Bitbucket != nil {
+		lines = append(lines, fmt.Sprintf("Bitbucket: %s", account.Bitbucket.User))
+	} else {
+		lines = append(lines, "Bitbucket: not linked")
+	}
+
+	if account.Jenkins != nil {
+		lines = append(lines, fmt.Sprintf("Jenkins: %s", account.Jenkins.User))
+	} else {
+		lines = append(lines, "Jenkins: not linked")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// help returns the usage of the slash command
+func (m *Manager) help() string {
+	return strings.Join([]string{
+		"Usage:",
+		"`link bitbucket` link your Bitbucket account",
+		"`link jenkins` link your Jenkins account with an API token",
+		"`unlink [bitbucket|jenkins]` remove linked accounts",
+		"`status` show linked accounts",
+	}, "\n")
+}
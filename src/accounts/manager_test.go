@@ -0,0 +1,249 @@
+package accounts
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "accounts.json"), testKey)
+	assert.NilError(t, err)
+
+	return &Manager{
+		Logger:          logrus.StandardLogger(),
+		Store:           s,
+		Cipher:          s.Cipher,
+		BitbucketServer: bitbucketServer,
+		JenkinsServer:   "jenkins.corp.org",
+	}
+}
+
+// fakeSlack records the modals opened by the manager
+type fakeSlack struct {
+	views []slack.ModalViewRequest
+}
+
+func (f *fakeSlack) OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	f.views = append(f.views, view)
+	return &slack.ViewResponse{}, nil
+}
+
+// submission returns the submission of a token modal with the given inputs
+func submission(view slack.ModalViewRequest, values map[string]string) slack.InteractionCallback {
+	cb := slack.InteractionCallback{Type: slack.InteractionTypeViewSubmission}
+	cb.User.ID = "U123"
+	cb.View.CallbackID = view.CallbackID
+	cb.View.PrivateMetadata = view.PrivateMetadata
+	cb.View.Title = view.Title
+	cb.View.State = &slack.ViewState{Values: map[string]map[string]slack.BlockAction{}}
+	for id, value := range values {
+		cb.View.State.Values[id] = map[string]slack.BlockAction{id: {Value: value}}
+	}
+
+	return cb
+}
+
+func TestManagerCommand(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.PluginPaths["base"], bitbucketServer, bitbucket.PluginPaths["whoami"]),
+		httpmock.NewStringResponder(200, "user-d"))
+	httpmock.RegisterResponder("GET", "https://jenkins.corp.org/me/api/json",
+		httpmock.NewStringResponder(200, `{"id":"jdoe"}`))
+
+	m := newTestManager(t)
+	slackClient := &fakeSlack{}
+	m.Slack = slackClient
+
+	t.Run("should show usage for unknown commands", func(t *testing.T) {
+		assert.Equal(t, m.help(), m.Command("U123", "trigger", "foo"))
+	})
+
+	t.Run("should refuse tokens in the command text", func(t *testing.T) {
+		assert.Equal(t, ":warning: Tokens are not accepted in commands. Revoke the token you entered and run `link jenkins` to enter a new one.",
+			m.Command("U123", "trigger", "link jenkins jdoe my-api-token"))
+		assert.Equal(t, 0, len(slackClient.views))
+
+		_, ok, err := m.Jenkins("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("should ask for a Bitbucket token when OAuth is not configured", func(t *testing.T) {
+		assert.Equal(t, "", m.Command("U123", "trigger", "link bitbucket"))
+		assert.Equal(t, 1, len(slackClient.views))
+		assert.Equal(t, ServiceBitbucket, slackClient.views[0].PrivateMetadata)
+
+		res := m.Submission(submission(slackClient.views[0], map[string]string{linkToken: "my-token"}))
+		assert.Equal(t, slack.RAUpdate, res.ResponseAction)
+
+		credential, ok, err := m.Bitbucket("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "my-token", credential.Token)
+	})
+
+	t.Run("should link a Jenkins token", func(t *testing.T) {
+		assert.Equal(t, "", m.Command("U123", "trigger", "link jenkins"))
+		assert.Equal(t, 2, len(slackClient.views))
+
+		res := m.Submission(submission(slackClient.views[1], map[string]string{linkUser: "jdoe", linkToken: "my-api-token"}))
+		assert.Equal(t, slack.RAUpdate, res.ResponseAction)
+		assert.Equal(t, "Bitbucket: user-d\nJenkins: jdoe", m.Command("U123", "trigger", "status"))
+	})
+
+	t.Run("should show why a token was rejected", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", "https://jenkins.corp.org/me/api/json", httpmock.NewStringResponder(401, ""))
+
+		res := m.Submission(submission(slackClient.views[1], map[string]string{linkUser: "jdoe", linkToken: "wrong"}))
+		assert.Equal(t, slack.RAErrors, res.ResponseAction)
+		assert.Equal(t, "failed to verify Jenkins token: HTTP request failed with unexpected status code 401", res.Errors[linkToken])
+	})
+
+	t.Run("should unlink a single service", func(t *testing.T) {
+		assert.Equal(t, ":wave: Unlinked.", m.Command("U123", "trigger", "unlink jenkins"))
+		assert.Equal(t, "Bitbucket: user-d\nJenkins: not linked", m.Command("U123", "trigger", "status"))
+	})
+}
+
+func TestManagerServeHTTP(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf(OAuthPaths["base"], bitbucketServer, OAuthPaths["token"]),
+		httpmock.NewStringResponder(200, `{"access_token":"access","refresh_token":"refresh","expires_in":3600}`))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.PluginPaths["base"], bitbucketServer, bitbucket.PluginPaths["whoami"]),
+		httpmock.NewStringResponder(200, "user-d"))
+
+	m := newTestManager(t)
+	m.OAuth = &oauth
+
+	// start opens the link URL of a user and returns the state and cookie
+	// for the Bitbucket redirect.
+	start := func(t *testing.T, slackUserID string) (string, *http.Cookie) {
+		linkURL, err := m.BitbucketLinkURL(slackUserID)
+		assert.NilError(t, err)
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, linkURL, nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+
+		location, err := url.Parse(rec.Header().Get("Location"))
+		assert.NilError(t, err)
+
+		cookies := rec.Result().Cookies()
+		assert.Equal(t, 1, len(cookies))
+
+		return location.Query().Get("state"), cookies[0]
+	}
+
+	callback := func(state string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/oauth/bitbucket?code=my-code&state="+url.QueryEscape(state), nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("should reject an unknown state", func(t *testing.T) {
+		rec := callback("foo", &http.Cookie{Name: stateCookie, Value: m.Cipher.Sign("foo")})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should only open a link URL once", func(t *testing.T) {
+		linkURL, err := m.BitbucketLinkURL("U123")
+		assert.NilError(t, err)
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, linkURL, nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+
+		rec = httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, linkURL, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("should reject a state from another browser", func(t *testing.T) {
+		state, _ := start(t, "U456")
+		_, cookie := start(t, "U789")
+
+		assert.Equal(t, http.StatusBadRequest, callback(state, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, callback(state, cookie).Code)
+
+		_, ok, err := m.Bitbucket("U456")
+		assert.NilError(t, err)
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("should link the account of the user in the state", func(t *testing.T) {
+		state, cookie := start(t, "U123")
+
+		rec := callback(state, cookie)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		credential, ok, err := m.Bitbucket("U123")
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "access", credential.Token)
+		assert.Equal(t, "refresh", credential.RefreshToken)
+
+		assert.Equal(t, http.StatusBadRequest, callback(state, cookie).Code)
+	})
+}
+
+func TestManagerBitbucketRefresh(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tokenURL := fmt.Sprintf(OAuthPaths["base"], bitbucketServer, OAuthPaths["token"])
+	httpmock.RegisterResponder("POST", tokenURL, func(req *http.Request) (*http.Response, error) {
+		// Keep the refresh running while the other lookups wait for it
+		time.Sleep(50 * time.Millisecond)
+		return httpmock.NewStringResponse(200, `{"access_token":"new-access","refresh_token":"new-refresh","expires_in":3600}`), nil
+	})
+
+	m := newTestManager(t)
+	m.OAuth = &oauth
+	assert.NilError(t, m.Store.Put(Account{
+		SlackUserID: "U123",
+		Bitbucket:   &Credential{User: "user-d", Token: "old-access", RefreshToken: "old-refresh", Expiry: time.Now().Add(-time.Hour)},
+	}))
+
+	t.Run("should only refresh the token once for concurrent lookups", func(t *testing.T) {
+		var wg sync.WaitGroup
+		tokens := make([]string, 5)
+		errs := make([]error, 5)
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				credential, _, err := m.Bitbucket("U123")
+				tokens[i], errs[i] = credential.Token, err
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range tokens {
+			assert.NilError(t, errs[i])
+			assert.Equal(t, "new-access", tokens[i])
+		}
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST "+tokenURL])
+		assert.Equal(t, 0, len(m.refreshing.locks))
+	})
+}
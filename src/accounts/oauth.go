@@ -0,0 +1,176 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// OAuthPaths are the Bitbucket Server OAuth 2.0 endpoints
+var OAuthPaths = map[string]string{
+	"base":      "https://%s/rest/oauth2/latest/%s",
+	"authorize": "authorize",
+	"token":     "token",
+}
+
+const (
+	// OAuthScope is the scope requested for linked Bitbucket accounts
+	OAuthScope = "REPO_WRITE"
+
+	// linkTTL is how long the link returned by the slash command can be
+	// opened, and stateTTL how long the user then has to authorize the bot.
+	linkTTL  = 5 * time.Minute
+	stateTTL = 15 * time.Minute
+
+	// stateCookie binds the OAuth state to the browser that opened the link
+	stateCookie = "unfurl_oauth_state"
+)
+
+// OAuthConfig is a Bitbucket Server OAuth 2.0 application
+type OAuthConfig struct {
+	Server       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Token is an OAuth 2.0 token response
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Scope        string `json:"scope"`
+}
+
+// Expiry returns when the token expires, or the zero time if it does not
+func (t Token) Expiry() time.Time {
+	if t.ExpiresIn == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// AuthURL returns the URL the user is sent to for authorizing the bot
+func (o OAuthConfig) AuthURL(state string) string {
+	q := url.Values{}
+	q.Set("client_id", o.ClientID)
+	q.Set("redirect_uri", o.RedirectURL)
+	q.Set("response_type", "code")
+	q.Set("scope", OAuthScope)
+	q.Set("state", state)
+
+	return fmt.Sprintf("%s?%s", fmt.Sprintf(OAuthPaths["base"], o.Server, OAuthPaths["authorize"]), q.Encode())
+}
+
+// Exchange exchanges an authorization code for a token
+func (o OAuthConfig) Exchange(code string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", o.RedirectURL)
+
+	return o.token(form)
+}
+
+// Refresh exchanges a refresh token for a new token
+func (o OAuthConfig) Refresh(refreshToken string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+
+	return o.token(form)
+}
+
+// token requests a token from the token endpoint
+func (o OAuthConfig) token(form url.Values) (Token, error) {
+	var token Token
+
+	form.Set("client_id", o.ClientID)
+	form.Set("client_secret", o.ClientSecret)
+
+	httpClient := http.Client{Timeout: 10 * time.Second}
+	res, err := httpClient.PostForm(fmt.Sprintf(OAuthPaths["base"], o.Server, OAuthPaths["token"]), form)
+	if err != nil {
+		return token, err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return token, err
+	}
+
+	if res.StatusCode != 200 {
+		return token, fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+	}
+
+	if err := json.Unmarshal(data, &token); err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+// pendingLink is a step of the OAuth flow a Slack user has started
+type pendingLink struct {
+	SlackUserID string
+	Expiry      time.Time
+}
+
+// nonces keeps random single use nonces for the steps of the OAuth flow in
+// memory, so pending flows are lost when the bot restarts.
+type nonces struct {
+	mu      sync.Mutex
+	pending map[string]pendingLink
+}
+
+// add returns a new nonce for a Slack user which is valid for ttl
+func (n *nonces) add(slackUserID string, ttl time.Duration) (string, error) {
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.pending == nil {
+		n.pending = map[string]pendingLink{}
+	}
+
+	now := time.Now()
+	for k, p := range n.pending {
+		if now.After(p.Expiry) {
+			delete(n.pending, k)
+		}
+	}
+
+	n.pending[nonce] = pendingLink{SlackUserID: slackUserID, Expiry: now.Add(ttl)}
+	return nonce, nil
+}
+
+// take returns the Slack user of a nonce and removes it so it can not be used
+// again.
+func (n *nonces) take(nonce string) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	p, ok := n.pending[nonce]
+	if !ok {
+		return "", errors.New("unknown or already used state")
+	}
+	delete(n.pending, nonce)
+
+	if time.Now().After(p.Expiry) {
+		return "", errors.New("state has expired")
+	}
+
+	return p.SlackUserID, nil
+}
@@ -0,0 +1,71 @@
+package accounts
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const bitbucketServer = "bitbucket.corp.org"
+
+var oauth = OAuthConfig{
+	Server:       bitbucketServer,
+	ClientID:     "client-id",
+	ClientSecret: "client-secret",
+	RedirectURL:  "https://unfurl.corp.org/oauth/bitbucket",
+}
+
+func TestOAuthAuthURL(t *testing.T) {
+	u, err := url.Parse(oauth.AuthURL("my-state"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, "/rest/oauth2/latest/authorize", u.Path)
+	assert.Equal(t, "client-id", u.Query().Get("client_id"))
+	assert.Equal(t, "code", u.Query().Get("response_type"))
+	assert.Equal(t, "my-state", u.Query().Get("state"))
+	assert.Equal(t, oauth.RedirectURL, u.Query().Get("redirect_uri"))
+}
+
+func TestOAuthExchange(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tokenAPI := fmt.Sprintf(OAuthPaths["base"], bitbucketServer, OAuthPaths["token"])
+	httpmock.RegisterResponder("POST", tokenAPI,
+		httpmock.NewStringResponder(200, `{"access_token":"access","refresh_token":"refresh","expires_in":3600,"token_type":"bearer"}`))
+
+	token, err := oauth.Exchange("my-code")
+	assert.NilError(t, err)
+
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "refresh", token.RefreshToken)
+	assert.Assert(t, !token.Expiry().IsZero())
+}
+
+func TestNonces(t *testing.T) {
+	var n nonces
+
+	t.Run("should return the user of a nonce once", func(t *testing.T) {
+		nonce, err := n.add("U123", time.Minute)
+		assert.NilError(t, err)
+
+		user, err := n.take(nonce)
+		assert.NilError(t, err)
+		assert.Equal(t, "U123", user)
+
+		_, err = n.take(nonce)
+		assert.Error(t, err, "unknown or already used state")
+	})
+
+	t.Run("should reject an expired nonce", func(t *testing.T) {
+		nonce, err := n.add("U123", -time.Minute)
+		assert.NilError(t, err)
+
+		_, err = n.take(nonce)
+		assert.Error(t, err, "state has expired")
+	})
+}
@@ -0,0 +1,21 @@
+package bitbucket
+
+// Backend is the part of the Bitbucket API shared by Bitbucket Server and
+// Bitbucket Cloud that pull request, commit and repository unfurls are read
+// from. On Bitbucket Cloud the project is the workspace.
+type Backend interface {
+	PullRequest(project string, repo string, id int) (PullRequest, error)
+	Repository(project string, repo string) (Repository, error)
+	Commits(project string, repo string, co CommitOptions) (CommitList, error)
+	Commit(project string, repo string, sha string) (Commit, error)
+	CommitStatus(project string, repo string, sha string) (StatusList, error)
+}
+
+// Client is the Bitbucket Server backend
+var _ Backend = Client{}
+
+// CommitStatus returns all statuses for a given commit. Bitbucket Server
+// stores build statuses by commit only, so project and repo are not used.
+func (c Client) CommitStatus(project string, repo string, sha string) (StatusList, error) {
+	return c.Status(sha)
+}
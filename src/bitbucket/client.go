@@ -1,13 +1,22 @@
 package bitbucket
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
+// ErrResponseTooLarge is returned when a response is larger than the client
+// is willing to read, such as the diff of a very large Pull Request.
+var ErrResponseTooLarge = errors.New("response is too large")
+
 // Client is a Bitbucket client that is used for storing server configuration,
 // authentiation and to run the actual Bitbucket requests.
 type Client struct {
@@ -41,19 +50,48 @@ func (c Client) Useragent() string {
 // helper method used by other Client functions.
 // https://docs.atlassian.com/bitbucket-server/rest/5.16.0/bitbucket-rest.html
 func (c Client) RawRequest(url string) ([]byte, int, error) {
+	return c.rawRequestWithBody(http.MethodGet, url, nil)
+}
+
+// rawRequestWithBody does a API request with the given method and JSON body
+// and returns content as a string.
+func (c Client) rawRequestWithBody(method string, url string, content interface{}) ([]byte, int, error) {
+	return c.request(method, url, content, time.Second*time.Duration(c.Timeout()), 0)
+}
+
+// request does a API request with the given timeout. Responses larger than
+// maxBytes fail with ErrResponseTooLarge, unless maxBytes is 0.
+func (c Client) request(method string, url string, content interface{}, timeout time.Duration, maxBytes int64) ([]byte, int, error) {
 	bearer := fmt.Sprintf("Bearer %s", c.PAT)
 
 	httpClient := http.Client{
-		Timeout: time.Second * time.Duration(c.Timeout()),
+		Timeout: timeout,
 	}
 
-	req, reqErr := http.NewRequest(http.MethodGet, url, nil)
+	var payload io.Reader
+	if content != nil {
+		b, err := json.Marshal(content)
+		if err != nil {
+			return []byte{}, 0, err
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, reqErr := http.NewRequest(method, url, payload)
 	if reqErr != nil {
 		return []byte{}, 0, reqErr
 	}
 
 	req.Header.Set("User-Agent", c.Useragent())
 	req.Header.Add("Authorization", bearer)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Bitbucket rejects write requests without this header as a XSRF check
+	if method != http.MethodGet {
+		req.Header.Set("X-Atlassian-Token", "no-check")
+	}
 
 	res, getErr := httpClient.Do(req)
 	if getErr != nil {
@@ -64,11 +102,20 @@ func (c Client) RawRequest(url string) ([]byte, int, error) {
 		defer res.Body.Close()
 	}
 
-	body, readErr := ioutil.ReadAll(res.Body)
+	var reader io.Reader = res.Body
+	if maxBytes > 0 {
+		reader = io.LimitReader(res.Body, maxBytes+1)
+	}
+
+	body, readErr := ioutil.ReadAll(reader)
 	if readErr != nil {
 		return []byte{}, 0, readErr
 	}
 
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return []byte{}, res.StatusCode, ErrResponseTooLarge
+	}
+
 	return body, res.StatusCode, nil
 }
 
@@ -108,6 +155,40 @@ func (c Client) PullRequests(project string, repo string) (PullRequests, error)
 	return prs, nil
 }
 
+// OpenPullRequestCount returns the number of open PullRequests in a given
+// repo, following the pages of the Pull Request list.
+func (c Client) OpenPullRequestCount(project string, repo string) (int, error) {
+	count := 0
+	start := 0
+
+	for {
+		var prs PullRequests
+
+		u := c.rawUrl(APIPaths, "pullRequests", project, repo)
+		u = fmt.Sprintf("%s?state=OPEN&limit=%d&start=%d", u, pullRequestPageSize, start)
+
+		data, status, err := c.RawRequest(u)
+		if err != nil {
+			return count, err
+		}
+
+		if status != 200 {
+			return count, responseError(data, status)
+		}
+
+		if err := json.Unmarshal(data, &prs); err != nil {
+			return count, err
+		}
+
+		count += prs.Size
+		if prs.IsLastPage || prs.Size == 0 {
+			return count, nil
+		}
+
+		start = prs.NextPageStart
+	}
+}
+
 // PullRequest returns a single PullRequest in a given repo in a given project.
 func (c Client) PullRequest(project string, repo string, id int) (PullRequest, error) {
 	var pr PullRequest
@@ -128,6 +209,104 @@ func (c Client) PullRequest(project string, repo string, id int) (PullRequest, e
 	return pr, nil
 }
 
+// PullRequestChanges returns up to limit files changed in a Pull Request.
+func (c Client) PullRequestChanges(project string, repo string, id int, limit int) (ChangeList, error) {
+	var changes ChangeList
+
+	url := c.rawUrl(APIPaths, "changes", project, repo, fmt.Sprint(id))
+	url = fmt.Sprintf("%s?limit=%d", url, limit)
+
+	data, status, err := c.RawRequest(url)
+	if err != nil {
+		return changes, err
+	}
+
+	if status != 200 {
+		return changes, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// PullRequestDiff returns the diff of a Pull Request without context lines.
+// Diffs of large Pull Requests take longer than other requests, so they get a
+// longer timeout, and fail with ErrResponseTooLarge above diffMaxBytes.
+func (c Client) PullRequestDiff(project string, repo string, id int) (Diff, error) {
+	var diff Diff
+
+	url := c.rawUrl(APIPaths, "diff", project, repo, fmt.Sprint(id))
+	url = fmt.Sprintf("%s?contextLines=0&withComments=false", url)
+
+	data, status, err := c.request(http.MethodGet, url, nil, diffTimeout, diffMaxBytes)
+	if err != nil {
+		return diff, err
+	}
+
+	if status != 200 {
+		return diff, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return diff, err
+	}
+
+	return diff, nil
+}
+
+// PullRequestFileDiff returns the diff of a single file in a Pull Request
+// with the given number of context lines.
+func (c Client) PullRequestFileDiff(project string, repo string, id int, path string, contextLines int) (Diff, error) {
+	var diff Diff
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	u := c.rawUrl(APIPaths, "fileDiff", project, repo, fmt.Sprint(id), strings.Join(segments, "/"))
+	u = fmt.Sprintf("%s?contextLines=%d&withComments=false", u, contextLines)
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return diff, err
+	}
+
+	if status != 200 {
+		return diff, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return diff, err
+	}
+
+	return diff, nil
+}
+
+// PullRequestComment returns a single comment on a Pull Request with its
+// replies.
+func (c Client) PullRequestComment(project string, repo string, id int, commentID int) (Comment, error) {
+	var comment Comment
+
+	data, status, err := c.RawRequest(c.rawUrl(APIPaths, "comment", project, repo, fmt.Sprint(id), fmt.Sprint(commentID)))
+	if err != nil {
+		return comment, err
+	}
+
+	if status != 200 {
+		return comment, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &comment); err != nil {
+		return comment, err
+	}
+
+	return comment, nil
+}
+
 // Repository returns a single Repo in a given project.
 func (c Client) Repository(project string, repo string) (Repository, error) {
 	var repository Repository
@@ -148,6 +327,62 @@ func (c Client) Repository(project string, repo string) (Repository, error) {
 	return repository, nil
 }
 
+// Project returns a single Project.
+func (c Client) Project(project string) (Project, error) {
+	var p Project
+
+	data, status, err := c.RawRequest(c.rawUrl(APIPaths, "project", project))
+	if err != nil {
+		return p, err
+	}
+
+	if status != 200 {
+		return p, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// Repositories returns up to limit repos in a given project, or in all
+// projects when project is empty.
+func (c Client) Repositories(project string, limit int) (RepositoryList, error) {
+	u := c.rawUrl(APIPaths, "repos")
+	if project != "" {
+		u = c.rawUrl(APIPaths, "projectRepos", project)
+	}
+
+	return c.repositories(fmt.Sprintf("%s?limit=%d", u, limit))
+}
+
+// PublicRepositories returns up to limit public repos in all projects.
+func (c Client) PublicRepositories(limit int) (RepositoryList, error) {
+	return c.repositories(fmt.Sprintf("%s?visibility=public&limit=%d", c.rawUrl(APIPaths, "repos"), limit))
+}
+
+// repositories returns the repos listed at the given url
+func (c Client) repositories(u string) (RepositoryList, error) {
+	var repos RepositoryList
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return repos, err
+	}
+
+	if status != 200 {
+		return repos, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &repos); err != nil {
+		return repos, err
+	}
+
+	return repos, nil
+}
+
 // Commits returns a list of Commits for a given repo in a given project
 func (c Client) Commits(project string, repo string, co CommitOptions) (CommitList, error) {
 	var commits CommitList
@@ -171,22 +406,488 @@ func (c Client) Commits(project string, repo string, co CommitOptions) (CommitLi
 	return commits, nil
 }
 
-// Status returns a status for a given commit
+// Commit returns a single Commit in a given repo in a given project.
+func (c Client) Commit(project string, repo string, sha string) (Commit, error) {
+	var commit Commit
+
+	data, status, err := c.RawRequest(c.rawUrl(APIPaths, "commit", project, repo, sha))
+	if err != nil {
+		return commit, err
+	}
+
+	if status != 200 {
+		return commit, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return commit, err
+	}
+
+	return commit, nil
+}
+
+// Branches returns up to limit branches in a given repo matching filter.
+func (c Client) Branches(project string, repo string, filter string, limit int) (RefList, error) {
+	return c.refs("branches", project, repo, filter, limit)
+}
+
+// Tags returns up to limit tags in a given repo matching filter.
+func (c Client) Tags(project string, repo string, filter string, limit int) (RefList, error) {
+	return c.refs("tags", project, repo, filter, limit)
+}
+
+// refs returns the branches or tags in a given repo matching filter.
+func (c Client) refs(path string, project string, repo string, filter string, limit int) (RefList, error) {
+	var refs RefList
+
+	u := c.rawUrl(APIPaths, path, project, repo)
+	u = fmt.Sprintf("%s?filterText=%s&limit=%d", u, url.QueryEscape(filter), limit)
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return refs, err
+	}
+
+	if status != 200 {
+		return refs, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return refs, err
+	}
+
+	return refs, nil
+}
+
+// DefaultBranch returns the default branch of a given repo.
+func (c Client) DefaultBranch(project string, repo string) (Ref, error) {
+	var ref Ref
+
+	data, status, err := c.RawRequest(c.rawUrl(APIPaths, "defaultBranch", project, repo))
+	if err != nil {
+		return ref, err
+	}
+
+	if status != 200 {
+		return ref, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return ref, err
+	}
+
+	return ref, nil
+}
+
+// AheadBehind returns the number of commits ref is ahead and behind base.
+func (c Client) AheadBehind(project string, repo string, ref string, base string) (AheadBehind, error) {
+	var ab AheadBehind
+
+	ahead, err := c.Commits(project, repo, CommitOptions{Since: base, Until: ref, WithCounts: true, Limit: 1})
+	if err != nil {
+		return ab, err
+	}
+
+	behind, err := c.Commits(project, repo, CommitOptions{Since: ref, Until: base, WithCounts: true, Limit: 1})
+	if err != nil {
+		return ab, err
+	}
+
+	ab.Ahead = ahead.TotalCount
+	ab.Behind = behind.TotalCount
+
+	return ab, nil
+}
+
+// OutgoingPullRequests returns the open Pull Requests from a given ref.
+func (c Client) OutgoingPullRequests(project string, repo string, ref string) (PullRequests, error) {
+	var prs PullRequests
+
+	u := c.rawUrl(APIPaths, "pullRequests", project, repo)
+	u = fmt.Sprintf("%s?at=%s&direction=OUTGOING&state=OPEN", u, url.QueryEscape(ref))
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return prs, err
+	}
+
+	if status != 200 {
+		return prs, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return prs, err
+	}
+
+	return prs, nil
+}
+
+// CompareChanges returns up to limit files changed on ref from compared to
+// ref to.
+func (c Client) CompareChanges(project string, repo string, from string, to string, limit int) (ChangeList, error) {
+	var changes ChangeList
+
+	u := c.rawUrl(APIPaths, "compareChanges", project, repo)
+	u = fmt.Sprintf("%s?from=%s&to=%s&limit=%d", u, url.QueryEscape(from), url.QueryEscape(to), limit)
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return changes, err
+	}
+
+	if status != 200 {
+		return changes, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// CompareDiff returns the diff of ref from compared to ref to without
+// context lines.
+func (c Client) CompareDiff(project string, repo string, from string, to string) (Diff, error) {
+	var diff Diff
+
+	u := c.rawUrl(APIPaths, "compareDiff", project, repo)
+	u = fmt.Sprintf("%s?from=%s&to=%s&contextLines=0", u, url.QueryEscape(from), url.QueryEscape(to))
+
+	data, status, err := c.request(http.MethodGet, u, nil, diffTimeout, diffMaxBytes)
+	if err != nil {
+		return diff, err
+	}
+
+	if status != 200 {
+		return diff, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return diff, err
+	}
+
+	return diff, nil
+}
+
+// Status returns all statuses for a given commit, following the pages of the
+// status list.
 func (c Client) Status(sha string) (StatusList, error) {
 	var s StatusList
 
-	data, status, err := c.RawRequest(c.rawUrl(StatusPaths, "status", sha))
+	start := 0
+	for {
+		var page StatusList
+
+		u := fmt.Sprintf("%s?start=%d&limit=%d", c.rawUrl(StatusPaths, "status", sha), start, statusPageSize)
+
+		data, status, err := c.RawRequest(u)
+		if err != nil {
+			return s, err
+		}
+
+		if status != 200 {
+			return s, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+		}
+
+		if err := json.Unmarshal(data, &page); err != nil {
+			return s, err
+		}
+
+		s.Values = append(s.Values, page.Values...)
+		s.Size = len(s.Values)
+		s.Limit = page.Limit
+		s.IsLastPage = true
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			return s, nil
+		}
+
+		start = page.NextPageStart
+	}
+}
+
+// Reports returns the Code Insights reports for a given commit.
+func (c Client) Reports(project string, repo string, sha string) (InsightReportList, error) {
+	var reports InsightReportList
+
+	data, status, err := c.RawRequest(c.rawUrl(InsightsPaths, "reports", project, repo, sha))
+	if err != nil {
+		return reports, err
+	}
+
+	if status != 200 {
+		return reports, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+// Annotations returns the Code Insights annotations of all reports for a
+// given commit.
+func (c Client) Annotations(project string, repo string, sha string) (InsightAnnotationList, error) {
+	var annotations InsightAnnotationList
+
+	data, status, err := c.RawRequest(c.rawUrl(InsightsPaths, "annotations", project, repo, sha))
+	if err != nil {
+		return annotations, err
+	}
+
+	if status != 200 {
+		return annotations, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return annotations, err
+	}
+
+	return annotations, nil
+}
+
+// HasRepositoryPermission returns true if the user with the given email
+// address has a permission, such as PermissionRepoRead, for a repo. The
+// permission may be granted directly or through a group or the project.
+func (c Client) HasRepositoryPermission(project string, repo string, email string, permission string) (bool, error) {
+	q := url.Values{}
+	q.Set("permission.projectKey", project)
+	q.Set("permission.repositorySlug", repo)
+
+	return c.hasPermission(email, permission, q)
+}
+
+// HasProjectPermission returns true if the user with the given email address
+// has a permission, such as PermissionProjectRead, for a project. The
+// permission may be granted directly or through a group.
+func (c Client) HasProjectPermission(project string, email string, permission string) (bool, error) {
+	q := url.Values{}
+	q.Set("permission.projectKey", project)
+
+	return c.hasPermission(email, permission, q)
+}
+
+// HasGlobalPermission returns true if the user with the given email address
+// has a global permission, such as PermissionLicensedUser.
+func (c Client) HasGlobalPermission(email string, permission string) (bool, error) {
+	return c.hasPermission(email, permission, url.Values{})
+}
+
+// hasPermission returns true if the user with the given email address has a
+// permission for the resource given by the permission query parameters.
+func (c Client) hasPermission(email string, permission string, q url.Values) (bool, error) {
+	var users UserList
+
+	q.Set("filter", email)
+	q.Set("permission", permission)
+
+	data, status, err := c.RawRequest(fmt.Sprintf("%s?%s", c.rawUrl(APIPaths, "users"), q.Encode()))
 	if err != nil {
-		return s, err
+		return false, err
 	}
 
 	if status != 200 {
-		return s, fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+		return false, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &users); err != nil {
+		return false, err
+	}
+
+	// The filter also does partial matches on user names and emails
+	for _, user := range users.Values {
+		if strings.EqualFold(user.Email, email) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// User returns a single User with a link to their avatar.
+func (c Client) User(slug string) (User, error) {
+	var user User
+
+	u := c.rawUrl(APIPaths, "user", url.PathEscape(slug))
+	u = fmt.Sprintf("%s?avatarSize=64", u)
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return user, err
+	}
+
+	if status != 200 {
+		return user, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &user); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// AuthoredPullRequests returns up to limit open Pull Requests authored by
+// the user in a given repo.
+func (c Client) AuthoredPullRequests(project string, repo string, slug string, limit int) (PullRequests, error) {
+	var prs PullRequests
+
+	u := c.rawUrl(APIPaths, "pullRequests", project, repo)
+	u = fmt.Sprintf("%s?state=OPEN&role.1=%s&username.1=%s&limit=%d", u, PullRequestUserRoleAuthor, url.QueryEscape(slug), limit)
+
+	data, status, err := c.RawRequest(u)
+	if err != nil {
+		return prs, err
+	}
+
+	if status != 200 {
+		return prs, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return prs, err
+	}
+
+	return prs, nil
+}
+
+// CurrentUser returns the name of the user the client is authenticated as.
+func (c Client) CurrentUser() (string, error) {
+	data, status, err := c.RawRequest(c.rawUrl(PluginPaths, "whoami"))
+	if err != nil {
+		return "", err
+	}
+
+	if status != 200 {
+		return "", fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	user := strings.TrimSpace(string(data))
+	if user == "" {
+		return "", errors.New("client is not authenticated")
+	}
+
+	return user, nil
+}
+
+// CurrentUserSlug returns the slug of the user the client is authenticated
+// as. The slug is used in URLs and may differ from the user name.
+func (c Client) CurrentUserSlug() (string, error) {
+	var users UserList
+
+	name, err := c.CurrentUser()
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("filter", name)
+
+	data, status, err := c.RawRequest(fmt.Sprintf("%s?%s", c.rawUrl(APIPaths, "users"), q.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	if status != 200 {
+		return "", responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &users); err != nil {
+		return "", err
+	}
+
+	// The filter also does partial matches on user names and emails
+	for _, user := range users.Values {
+		if strings.EqualFold(user.Name, name) {
+			return user.Slug, nil
+		}
+	}
+
+	return "", fmt.Errorf("user %s not found", name)
+}
+
+// SetReviewStatus sets the review status of a user on a Pull Request to one
+// of the PullRequestReviewStatus constants.
+func (c Client) SetReviewStatus(project string, repo string, id int, userSlug string, reviewStatus string) error {
+	u := c.rawUrl(APIPaths, "participant", project, repo, fmt.Sprint(id), url.PathEscape(userSlug))
+	body := map[string]string{"status": reviewStatus}
+
+	data, status, err := c.rawRequestWithBody(http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+
+	if status != 200 {
+		return responseError(data, status)
+	}
+
+	return nil
+}
+
+// MergeStatus returns whether a Pull Request can be merged and the merge
+// checks that are preventing it.
+func (c Client) MergeStatus(project string, repo string, id int) (MergeStatus, error) {
+	var ms MergeStatus
+
+	data, status, err := c.RawRequest(c.rawUrl(APIPaths, "merge", project, repo, fmt.Sprint(id)))
+	if err != nil {
+		return ms, err
+	}
+
+	if status != 200 {
+		return ms, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return ms, err
+	}
+
+	return ms, nil
+}
+
+// Merge merges a Pull Request. The version must match the current version of
+// the Pull Request to avoid merging changes the user has not seen.
+func (c Client) Merge(project string, repo string, id int, version int) (PullRequest, error) {
+	var pr PullRequest
+
+	url := c.rawUrl(APIPaths, "merge", project, repo, fmt.Sprint(id))
+	url = fmt.Sprintf("%s?version=%d", url, version)
+
+	data, status, err := c.rawRequestWithBody(http.MethodPost, url, map[string]string{})
+	if err != nil {
+		return pr, err
+	}
+
+	if status != 200 {
+		return pr, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return pr, err
+	}
+
+	return pr, nil
+}
+
+// responseError returns an error with the messages from a Bitbucket error
+// response, or just the status code if there are none.
+func responseError(data []byte, status int) error {
+	var res struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil || len(res.Errors) == 0 {
+		return fmt.Errorf("HTTP request failed with unexpected status code %d", status)
 	}
 
-	if err := json.Unmarshal(data, &s); err != nil {
-		return s, err
+	messages := make([]string, len(res.Errors))
+	for i, e := range res.Errors {
+		messages[i] = e.Message
 	}
 
-	return s, nil
+	return fmt.Errorf("HTTP request failed with status code %d: %s", status, strings.Join(messages, "; "))
 }
@@ -1,7 +1,9 @@
 package bitbucket
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/jarcoal/httpmock"
@@ -127,6 +129,32 @@ func TestBitbucketClientCommits(t *testing.T) {
 	assert.Equal(t, len(c.Values), 25)
 }
 
+func TestBitbucketClientCommit(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-commit.json")
+	reqPath := fmt.Sprintf(
+		APIPaths["base"],
+		bitbucketServer,
+		fmt.Sprintf(APIPaths["commit"], bitbucketProject, bitbucketRepo, sha),
+	)
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	c, err := client.Commit(bitbucketProject, bitbucketRepo, sha)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, c.ID, sha)
+	assert.DeepEqual(t, c.JIRAIssueKeys(), []string{"PROJ-1396"})
+}
+
 func TestBitbucketClientStatus(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
@@ -152,3 +180,533 @@ func TestBitbucketClientStatus(t *testing.T) {
 	assert.Equal(t, 1, len(status.Values))
 	assert.Equal(t, StatusInProgress, status.Values[0].State)
 }
+
+func TestBitbucketClientStatusPages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(StatusPaths["base"], bitbucketServer, fmt.Sprintf(StatusPaths["status"], bitbucketCommitSHA))
+
+	// Set up mock Bitbucket Server with two pages of statuses
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "start=0&limit=100",
+		httpmock.NewStringResponder(200, `{"size":1,"limit":1,"isLastPage":false,"start":0,"nextPageStart":1,"values":[{"state":"SUCCESSFUL","key":"build"}]}`))
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "start=1&limit=100",
+		httpmock.NewStringResponder(200, `{"size":1,"limit":1,"isLastPage":true,"start":1,"values":[{"state":"FAILED","key":"deploy"}]}`))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	status, err := client.Status(bitbucketCommitSHA)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 2, status.Size)
+	assert.Equal(t, StatusFailed, status.State())
+}
+
+func TestBitbucketClientCurrentUser(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(PluginPaths["base"], bitbucketServer, PluginPaths["whoami"])
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, "user-d\n"))
+
+	// Get current user using Client
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	user, err := client.CurrentUser()
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, "user-d", user)
+}
+
+func TestBitbucketClientCurrentUserSlug(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", fmt.Sprintf(PluginPaths["base"], bitbucketServer, PluginPaths["whoami"]),
+		httpmock.NewStringResponder(200, "jane.doe@corp.org"))
+	httpmock.RegisterResponderWithQuery("GET", fmt.Sprintf(APIPaths["base"], bitbucketServer, APIPaths["users"]), "filter=jane.doe%40corp.org",
+		httpmock.NewStringResponder(200, `{"values":[{"name":"jane.doe@corp.org.old","slug":"jane.doe_corp.org.old"},{"name":"jane.doe@corp.org","slug":"jane.doe_corp.org"}]}`))
+
+	// Get current user slug using Client
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	slug, err := client.CurrentUserSlug()
+	assert.NilError(t, err)
+
+	assert.Equal(t, "jane.doe_corp.org", slug)
+}
+
+func TestBitbucketClientSetReviewStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(
+		APIPaths["base"],
+		bitbucketServer,
+		fmt.Sprintf(APIPaths["participant"], bitbucketProject, bitbucketRepo, fmt.Sprint(bitbucketRRID), "user-d"),
+	)
+
+	// Set up mock Bitbucket Server
+	var body map[string]string
+	httpmock.RegisterResponder("PUT", reqPath,
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Atlassian-Token") != "no-check" {
+				return httpmock.NewStringResponse(403, ""), nil
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(200, "{}"), nil
+		})
+
+	// Approve Pull Request using Client
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	err := client.SetReviewStatus(bitbucketProject, bitbucketRepo, bitbucketRRID, "user-d", PullRequestReviewStatusApproved)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, PullRequestReviewStatusApproved, body["status"])
+}
+
+func TestBitbucketClientMergeStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-merge-297.json")
+	reqPath := fmt.Sprintf(
+		APIPaths["base"],
+		bitbucketServer,
+		fmt.Sprintf(APIPaths["merge"], bitbucketProject, bitbucketRepo, fmt.Sprint(bitbucketRRID)),
+	)
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	// Get merge status using Client
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	ms, err := client.MergeStatus(bitbucketProject, bitbucketRepo, bitbucketRRID)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, false, ms.CanMerge)
+	assert.Equal(t, 2, len(ms.Vetoes))
+}
+
+func TestBitbucketClientMerge(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(
+		APIPaths["base"],
+		bitbucketServer,
+		fmt.Sprintf(APIPaths["merge"], bitbucketProject, bitbucketRepo, fmt.Sprint(bitbucketRRID)),
+	)
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("POST", reqPath, "version=38",
+		httpmock.NewStringResponder(409, `{"errors":[{"message":"You are attempting to modify a pull request based on out-of-date information."}]}`))
+
+	// Merge Pull Request using Client
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	_, err := client.Merge(bitbucketProject, bitbucketRepo, bitbucketRRID, 38)
+
+	assert.Error(t, err, "HTTP request failed with status code 409: You are attempting to modify a pull request based on out-of-date information.")
+}
+
+func TestBitbucketClientHasRepositoryPermission(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-users-permission.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, APIPaths["users"])
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+
+	t.Run("should find user with exact email", func(t *testing.T) {
+		ok, err := client.HasRepositoryPermission(bitbucketProject, bitbucketRepo, "user.d@corp.org", PermissionRepoRead)
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+	})
+
+	t.Run("should ignore partial email matches", func(t *testing.T) {
+		ok, err := client.HasRepositoryPermission(bitbucketProject, bitbucketRepo, "d@corp.org", PermissionRepoRead)
+		assert.NilError(t, err)
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("should check project permissions", func(t *testing.T) {
+		ok, err := client.HasProjectPermission(bitbucketProject, "user.d@corp.org", PermissionProjectRead)
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+	})
+
+	t.Run("should check global permissions", func(t *testing.T) {
+		ok, err := client.HasGlobalPermission("user.d@corp.org", PermissionLicensedUser)
+		assert.NilError(t, err)
+		assert.Equal(t, true, ok)
+	})
+}
+
+func TestBitbucketClientPullRequestChanges(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-changes-297.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["changes"], bitbucketProject, bitbucketRepo, "297"))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "limit=1000",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	changes, err := client.PullRequestChanges(bitbucketProject, bitbucketRepo, 297, 1000)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 4, changes.Size)
+	assert.Equal(t, "src/main.go", changes.Values[0].Path.ToString)
+	assert.Equal(t, "DELETE", changes.Values[3].Type)
+}
+
+func TestBitbucketClientPullRequestDiff(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-diff-297.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["diff"], bitbucketProject, bitbucketRepo, "297"))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "contextLines=0&withComments=false",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	diff, err := client.PullRequestDiff(bitbucketProject, bitbucketRepo, 297)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 4, len(diff.Diffs))
+	assert.Equal(t, "docs/old.md", diff.Diffs[3].Path())
+}
+
+func TestBitbucketClientPullRequestComment(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-comment-456.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["comment"], bitbucketProject, bitbucketRepo, "297", "456"))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	comment, err := client.PullRequestComment(bitbucketProject, bitbucketRepo, 297, 456)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 456, comment.ID)
+	assert.Equal(t, "src/unfurl/bitbucket.go", comment.Anchor.Path)
+	assert.Equal(t, 42, comment.Anchor.Line)
+	assert.Equal(t, 3, comment.Replies())
+}
+
+func TestBitbucketClientPullRequestFileDiff(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-file-diff-297.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["fileDiff"], bitbucketProject, bitbucketRepo, "297", "src/unfurl/bitbucket.go"))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "contextLines=3&withComments=false",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	diff, err := client.PullRequestFileDiff(bitbucketProject, bitbucketRepo, 297, "src/unfurl/bitbucket.go", 3)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, len(diff.Diffs))
+	assert.Equal(t, 2, len(diff.Diffs[0].Hunks))
+}
+
+func TestBitbucketClientBranches(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-branches.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["branches"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "filterText=feature/foo&limit=25",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	branches, err := client.Branches(bitbucketProject, bitbucketRepo, "feature/foo", 25)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 2, len(branches.Values))
+	assert.Equal(t, "refs/heads/feature/foo", branches.Values[0].ID)
+	assert.Equal(t, RefTypeBranch, branches.Values[0].Type)
+}
+
+func TestBitbucketClientTags(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-tags.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["tags"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "filterText=v1.2.0&limit=25",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	tags, err := client.Tags(bitbucketProject, bitbucketRepo, "v1.2.0", 25)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, len(tags.Values))
+	assert.Equal(t, true, tags.Values[0].IsTag())
+}
+
+func TestBitbucketClientDefaultBranch(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-default-branch.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["defaultBranch"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	ref, err := client.DefaultBranch(bitbucketProject, bitbucketRepo)
+	assert.NilError(t, err)
+
+	assert.Equal(t, "master", ref.DisplayID)
+	assert.Equal(t, true, ref.IsDefault)
+}
+
+func TestBitbucketClientAheadBehind(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["repoCommits"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "since=refs/heads/master&until=refs/heads/feature/foo&withCounts=true&limit=1",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "bitbucket-repo-commits-ahead.json")).String()))
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "since=refs/heads/feature/foo&until=refs/heads/master&withCounts=true&limit=1",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "bitbucket-repo-commits-behind.json")).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	ab, err := client.AheadBehind(bitbucketProject, bitbucketRepo, "refs/heads/feature/foo", "refs/heads/master")
+	assert.NilError(t, err)
+
+	assert.Equal(t, AheadBehind{Ahead: 3, Behind: 1}, ab)
+}
+
+func TestBitbucketClientOutgoingPullRequests(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-requests-outgoing.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["pullRequests"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "at=refs/heads/feature/foo&direction=OUTGOING&state=OPEN",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	prs, err := client.OutgoingPullRequests(bitbucketProject, bitbucketRepo, "refs/heads/feature/foo")
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, len(prs.List))
+	assert.Equal(t, "feature/foo", prs.List[0].FromRef.DisplayID)
+}
+
+func TestBitbucketClientCompareChanges(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-changes-297.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["compareChanges"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "from=refs/heads/release&to=refs/heads/master&limit=1000",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	changes, err := client.CompareChanges(bitbucketProject, bitbucketRepo, "refs/heads/release", "refs/heads/master", 1000)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 4, changes.Size)
+}
+
+func TestBitbucketClientCompareDiff(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-diff-297.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["compareDiff"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "from=refs/heads/release&to=refs/heads/master&contextLines=0",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	diff, err := client.CompareDiff(bitbucketProject, bitbucketRepo, "refs/heads/release", "refs/heads/master")
+	assert.NilError(t, err)
+
+	assert.Equal(t, 57, diff.Stat().Added)
+}
+
+func TestBitbucketClientProject(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-project.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["project"], bitbucketProject))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	project, err := client.Project(bitbucketProject)
+	assert.NilError(t, err)
+
+	assert.Equal(t, "My Project", project.Name)
+	assert.Equal(t, "https://bitbucket.corp.org/projects/MY-PROJ", project.Links.Self[0].Href)
+}
+
+func TestBitbucketClientRepositories(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-project-repos.json")
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["projectRepos"], bitbucketProject)), "limit=25",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+	httpmock.RegisterResponderWithQuery("GET", fmt.Sprintf(APIPaths["base"], bitbucketServer, APIPaths["repos"]), "limit=25",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+	httpmock.RegisterResponderWithQuery("GET", fmt.Sprintf(APIPaths["base"], bitbucketServer, APIPaths["repos"]), "visibility=public&limit=10",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+
+	repos, err := client.Repositories(bitbucketProject, 25)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(repos.Values))
+
+	repos, err = client.Repositories("", 25)
+	assert.NilError(t, err)
+	assert.Equal(t, "my-other-repo", repos.Values[1].Slug)
+
+	repos, err = client.PublicRepositories(10)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(repos.Values))
+}
+
+func TestBitbucketClientUser(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-user.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["user"], "user-d"))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "avatarSize=64",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	user, err := client.User("user-d")
+	assert.NilError(t, err)
+
+	assert.Equal(t, "User D", user.DisplayName)
+	assert.Equal(t, "/users/user-d/avatar.png?s=64", user.AvatarURL)
+}
+
+func TestBitbucketClientAuthoredPullRequests(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-requests-outgoing.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["pullRequests"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "state=OPEN&role.1=AUTHOR&username.1=user-d&limit=100",
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	prs, err := client.AuthoredPullRequests(bitbucketProject, bitbucketRepo, "user-d", 100)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, prs.Size)
+	assert.Equal(t, "user-d", prs.List[0].Author.User.Slug)
+}
+
+func TestBitbucketClientOpenPullRequestCount(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	reqPath := fmt.Sprintf(APIPaths["base"], bitbucketServer, fmt.Sprintf(APIPaths["pullRequests"], bitbucketProject, bitbucketRepo))
+
+	// Set up mock Bitbucket Server with two pages of Pull Requests
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "state=OPEN&limit=100&start=0",
+		httpmock.NewStringResponder(200, `{"size":100,"limit":100,"isLastPage":false,"start":0,"nextPageStart":100,"values":[]}`))
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "state=OPEN&limit=100&start=100",
+		httpmock.NewStringResponder(200, `{"size":23,"limit":100,"isLastPage":true,"start":100,"values":[]}`))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	count, err := client.OpenPullRequestCount(bitbucketProject, bitbucketRepo)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 123, count)
+}
+
+func TestBitbucketClientReports(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-insights-reports.json")
+	reqPath := fmt.Sprintf(InsightsPaths["base"], bitbucketServer, fmt.Sprintf(InsightsPaths["reports"], bitbucketProject, bitbucketRepo, bitbucketCommitSHA))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	reports, err := client.Reports(bitbucketProject, bitbucketRepo, bitbucketCommitSHA)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 2, len(reports.Values))
+	assert.Equal(t, InsightResultFail, reports.Values[1].Result)
+}
+
+func TestBitbucketClientAnnotations(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-insights-annotations.json")
+	reqPath := fmt.Sprintf(InsightsPaths["base"], bitbucketServer, fmt.Sprintf(InsightsPaths["annotations"], bitbucketProject, bitbucketRepo, bitbucketCommitSHA))
+
+	// Set up mock Bitbucket Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: bitbucketServer, PAT: bitbucketPAT}
+	annotations, err := client.Annotations(bitbucketProject, bitbucketRepo, bitbucketCommitSHA)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 4, annotations.TotalCount)
+	assert.Equal(t, "2 high, 1 low", annotations.Counts("security-scan"))
+}
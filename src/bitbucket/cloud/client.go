@@ -0,0 +1,214 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+)
+
+// Client is a Bitbucket Cloud client that authenticates with an app password
+// or an OAuth access token and maps the API 2.0 resources onto the Bitbucket
+// Server model used by the unfurls.
+type Client struct {
+	// Server is the API server, api.bitbucket.org when empty
+	Server string
+
+	// Username and AppPassword authenticate with an app password
+	Username    string
+	AppPassword string
+
+	// Token authenticates with an OAuth access token
+	Token string
+
+	timeout   int
+	useragent string
+}
+
+// Client is the Bitbucket Cloud backend
+var _ bitbucket.Backend = Client{}
+
+// Timeout returns the configured connection timeout for the HTTP client.
+func (c Client) Timeout() int {
+	if c.timeout == 0 {
+		return 2
+	}
+
+	return c.timeout
+}
+
+// Useragent returns the configured client useragent or a default one.
+func (c Client) Useragent() string {
+	if c.useragent == "" {
+		return "bitbucket-cloud-go-sdk"
+	}
+
+	return c.useragent
+}
+
+// RawRequest does a API request and returns the content and the status code.
+// This is just a helper method used by other Client functions.
+// https://developer.atlassian.com/cloud/bitbucket/rest/intro/
+func (c Client) RawRequest(url string) ([]byte, int, error) {
+	httpClient := http.Client{
+		Timeout: time.Second * time.Duration(c.Timeout()),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", c.Useragent())
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	} else {
+		req.SetBasicAuth(c.Username, c.AppPassword)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	return body, res.StatusCode, nil
+}
+
+// rawUrl returns a URL for a given API path and a list of path parameters.
+func (c Client) rawUrl(path string, args ...interface{}) string {
+	server := c.Server
+	if server == "" {
+		server = DefaultServer
+	}
+
+	return fmt.Sprintf(APIPaths["base"], server, fmt.Sprintf(APIPaths[path], args...))
+}
+
+// get does a API request and decodes the JSON response into v.
+func (c Client) get(url string, v interface{}) error {
+	data, status, err := c.RawRequest(url)
+	if err != nil {
+		return err
+	}
+
+	if status != 200 {
+		return responseError(data, status)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// PullRequest returns a single PullRequest in a given repo in a given
+// workspace.
+func (c Client) PullRequest(workspace string, repo string, id int) (bitbucket.PullRequest, error) {
+	var pr PullRequest
+
+	if err := c.get(c.rawUrl("pullRequest", workspace, repo, fmt.Sprint(id)), &pr); err != nil {
+		return bitbucket.PullRequest{}, err
+	}
+
+	return pr.ToBitbucket(), nil
+}
+
+// Repository returns a single Repo in a given workspace.
+func (c Client) Repository(workspace string, repo string) (bitbucket.Repository, error) {
+	var r Repository
+
+	if err := c.get(c.rawUrl("repo", workspace, repo), &r); err != nil {
+		return bitbucket.Repository{}, err
+	}
+
+	return r.ToBitbucket(), nil
+}
+
+// Commits returns the latest Commits in a given repo in a given workspace.
+// The commits reachable from co.Until and not from co.Since are returned, and
+// co.Limit sets the page size. Bitbucket Cloud does not count commits, so the
+// total count is not set.
+func (c Client) Commits(workspace string, repo string, co bitbucket.CommitOptions) (bitbucket.CommitList, error) {
+	var commits CommitList
+
+	u := c.rawUrl("commits", workspace, repo)
+	if co.Until != "" {
+		u = c.rawUrl("refCommits", workspace, repo, url.PathEscape(bitbucket.RefName(co.Until)))
+	}
+
+	q := url.Values{}
+	if co.Since != "" {
+		q.Set("exclude", bitbucket.RefName(co.Since))
+	}
+	if co.Path != "" {
+		q.Set("path", co.Path)
+	}
+	if co.Limit > 0 {
+		q.Set("pagelen", fmt.Sprint(co.Limit))
+	}
+	if len(q) > 0 {
+		u = fmt.Sprintf("%s?%s", u, q.Encode())
+	}
+
+	if err := c.get(u, &commits); err != nil {
+		return bitbucket.CommitList{}, err
+	}
+
+	list := bitbucket.CommitList{
+		Size:       len(commits.Values),
+		Limit:      commits.PageLen,
+		IsLastPage: commits.Next == "",
+	}
+	for _, commit := range commits.Values {
+		list.Values = append(list.Values, commit.ToBitbucket())
+	}
+
+	return list, nil
+}
+
+// Commit returns a single Commit in a given repo in a given workspace.
+func (c Client) Commit(workspace string, repo string, sha string) (bitbucket.Commit, error) {
+	var commit Commit
+
+	if err := c.get(c.rawUrl("commit", workspace, repo, sha), &commit); err != nil {
+		return bitbucket.Commit{}, err
+	}
+
+	return commit.ToBitbucket(), nil
+}
+
+// CommitStatus returns all build statuses for a given commit, including
+// Pipelines builds, following the pages of the status list.
+func (c Client) CommitStatus(workspace string, repo string, sha string) (bitbucket.StatusList, error) {
+	var list bitbucket.StatusList
+
+	next := c.rawUrl("statuses", workspace, repo, sha)
+	for next != "" {
+		var page StatusList
+
+		if err := c.get(next, &page); err != nil {
+			return list, err
+		}
+
+		for _, s := range page.Values {
+			list.Values = append(list.Values, s.ToBitbucket())
+		}
+		next = page.Next
+	}
+
+	list.Size = len(list.Values)
+	list.IsLastPage = true
+
+	return list, nil
+}
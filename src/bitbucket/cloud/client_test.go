@@ -0,0 +1,108 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const (
+	workspace = "my-workspace"
+	repo      = "my-repo"
+	sha       = "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+
+	testdataDir = "../../../testdata"
+)
+
+var client = Client{Username: "jane", AppPassword: "my-app-password"}
+
+func TestCloudClientAuth(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", client.rawUrl("repo", workspace, repo),
+		func(req *http.Request) (*http.Response, error) {
+			auth := req.Header.Get("Authorization")
+			if auth != "Basic amFuZTpteS1hcHAtcGFzc3dvcmQ=" && auth != "Bearer my-token" {
+				return httpmock.NewStringResponse(401, `{"type":"error","error":{"message":"Unauthorized"}}`), nil
+			}
+
+			return httpmock.NewStringResponse(200, httpmock.File(testdataDir+"/bitbucket-cloud-repo.json").String()), nil
+		})
+
+	_, err := client.Repository(workspace, repo)
+	assert.NilError(t, err)
+
+	_, err = Client{Token: "my-token"}.Repository(workspace, repo)
+	assert.NilError(t, err)
+
+	_, err = Client{Token: "wrong"}.Repository(workspace, repo)
+	assert.Error(t, err, "HTTP request failed with status code 401: Unauthorized")
+}
+
+func TestCloudClientPullRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", client.rawUrl("pullRequest", workspace, repo, "42"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-pull-request.json").String()))
+
+	pr, err := client.PullRequest(workspace, repo, 42)
+	assert.NilError(t, err)
+	assert.Equal(t, pr.ID, 42)
+	assert.Equal(t, pr.FromRef.DisplayID, "feature/cloud")
+	assert.Equal(t, pr.FromRef.LatestCommit, "1a2b3c4d5e6f")
+	assert.Equal(t, pr.ToRef.Repository.Project.Key, workspace)
+	assert.Equal(t, pr.ReviewedBy(), "John Smith (APPROVED), Ola Nordmann (NEEDS_WORK)")
+}
+
+func TestCloudClientCommits(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponderWithQuery("GET", client.rawUrl("refCommits", workspace, repo, "release"),
+		"exclude=master&pagelen=1",
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-commits.json").String()))
+
+	commits, err := client.Commits(workspace, repo, bitbucket.CommitOptions{
+		Since: "refs/heads/master",
+		Until: "refs/heads/release",
+		Limit: 1,
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, commits.Size, 1)
+	assert.Equal(t, commits.IsLastPage, false)
+	assert.Equal(t, commits.Values[0].ID, sha)
+}
+
+func TestCloudClientCommitStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	statusesURL := client.rawUrl("statuses", workspace, repo, sha)
+	httpmock.RegisterResponder("GET", statusesURL,
+		httpmock.NewStringResponder(200, fmt.Sprintf(`{"values":[{"key":"lint","state":"FAILED"}],"next":"%s?page=2"}`, statusesURL)))
+	httpmock.RegisterResponderWithQuery("GET", statusesURL, "page=2",
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-statuses.json").String()))
+
+	st, err := client.CommitStatus(workspace, repo, sha)
+	assert.NilError(t, err)
+	assert.Equal(t, st.Size, 3)
+	assert.Equal(t, st.State(), bitbucket.StatusFailed)
+	assert.Equal(t, st.Values[2].State, bitbucket.StatusCancelled)
+}
+
+func TestCloudClientNotFound(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", client.rawUrl("commit", workspace, repo, sha),
+		httpmock.NewStringResponder(404, `{"type":"error","error":{"message":"Commit not found"}}`))
+
+	_, err := client.Commit(workspace, repo, sha)
+	assert.Error(t, err, "HTTP request failed with status code 404: Commit not found")
+}
@@ -0,0 +1,36 @@
+package cloud
+
+// DefaultServer is the Bitbucket Cloud API server
+const DefaultServer = "api.bitbucket.org"
+
+// Domain is the domain of Bitbucket Cloud links
+const Domain = "bitbucket.org"
+
+var APIPaths = map[string]string{
+	"base":        "https://%s/2.0/%s",
+	"repo":        "repositories/%s/%s",
+	"pullRequest": "repositories/%s/%s/pullrequests/%s",
+	"commit":      "repositories/%s/%s/commit/%s",
+	"commits":     "repositories/%s/%s/commits",
+	"refCommits":  "repositories/%s/%s/commits/%s",
+	"statuses":    "repositories/%s/%s/commit/%s/statuses",
+}
+
+const (
+	// ParticipantRoleReviewer is the role of Pull Request reviewers
+	ParticipantRoleReviewer = "REVIEWER"
+
+	// ParticipantStateApproved is the state of participants who approved
+	ParticipantStateApproved = "approved"
+
+	// ParticipantStateChangesRequested is the state of participants who
+	// requested changes
+	ParticipantStateChangesRequested = "changes_requested"
+
+	// PullRequestStateOpen is the state of open Pull Requests
+	PullRequestStateOpen = "OPEN"
+
+	// StatusStopped is the state of stopped builds, such as stopped
+	// Pipelines
+	StatusStopped = "STOPPED"
+)
@@ -0,0 +1,22 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// responseError returns an error with the message from a Bitbucket Cloud
+// error response, or with the status code only.
+func responseError(data []byte, status int) error {
+	var res struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil || res.Error.Message == "" {
+		return fmt.Errorf("HTTP request failed with unexpected status code %d", status)
+	}
+
+	return fmt.Errorf("HTTP request failed with status code %d: %s", status, res.Error.Message)
+}
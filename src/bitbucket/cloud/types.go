@@ -0,0 +1,289 @@
+package cloud
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+)
+
+// Link is a link to a resource
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name,omitempty"`
+}
+
+// Links are the links of a resource
+type Links struct {
+	HTML  Link   `json:"html"`
+	Clone []Link `json:"clone,omitempty"`
+}
+
+// Account is a Bitbucket Cloud user
+type Account struct {
+	UUID        string `json:"uuid"`
+	AccountID   string `json:"account_id"`
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	Links       Links  `json:"links"`
+}
+
+// Participant is a user taking part in a Pull Request
+type Participant struct {
+	User     Account `json:"user"`
+	Role     string  `json:"role"`
+	Approved bool    `json:"approved"`
+	State    string  `json:"state"`
+}
+
+// Endpoint is the source or destination of a Pull Request
+type Endpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+	Repository Repository `json:"repository"`
+}
+
+// PullRequest is a Bitbucket Cloud Pull Request
+type PullRequest struct {
+	ID           int           `json:"id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	State        string        `json:"state"`
+	CreatedOn    time.Time     `json:"created_on"`
+	UpdatedOn    time.Time     `json:"updated_on"`
+	Author       Account       `json:"author"`
+	Source       Endpoint      `json:"source"`
+	Destination  Endpoint      `json:"destination"`
+	Participants []Participant `json:"participants"`
+	TaskCount    int           `json:"task_count"`
+	CommentCount int           `json:"comment_count"`
+	Links        Links         `json:"links"`
+}
+
+// Commit is a Bitbucket Cloud commit
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Message string    `json:"message"`
+	Author  struct {
+		Raw  string   `json:"raw"`
+		User *Account `json:"user,omitempty"`
+	} `json:"author"`
+	Parents []struct {
+		Hash string `json:"hash"`
+	} `json:"parents"`
+	Links Links `json:"links"`
+}
+
+// CommitList is a page of commits
+type CommitList struct {
+	PageLen int      `json:"pagelen"`
+	Next    string   `json:"next,omitempty"`
+	Values  []Commit `json:"values"`
+}
+
+// Repository is a Bitbucket Cloud repository
+type Repository struct {
+	UUID        string `json:"uuid"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	Project     struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Parent *Repository `json:"parent,omitempty"`
+	Links  Links       `json:"links"`
+}
+
+// Status is a build status of a commit, including Pipelines builds
+type Status struct {
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	State       string    `json:"state"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	CreatedOn   time.Time `json:"created_on"`
+}
+
+// StatusList is a page of build statuses
+type StatusList struct {
+	Next   string   `json:"next,omitempty"`
+	Values []Status `json:"values"`
+}
+
+// ToBitbucket returns the Pull Request in the Bitbucket Server model
+func (pr PullRequest) ToBitbucket() bitbucket.PullRequest {
+	var p bitbucket.PullRequest
+
+	p.ID = pr.ID
+	p.Title = pr.Title
+	p.Description = pr.Description
+	p.State = pr.State
+	p.IsOpen = pr.State == PullRequestStateOpen
+	p.IsClosed = !p.IsOpen
+	p.CreatedDate = millis(pr.CreatedOn)
+	p.UpdatedDate = millis(pr.UpdatedOn)
+	p.FromRef = pr.Source.toBitbucket()
+	p.ToRef = pr.Destination.toBitbucket()
+	p.Author = bitbucket.Author{User: pr.Author.toBitbucket(), Role: bitbucket.PullRequestUserRoleAuthor}
+	p.Properties.OpenTaskCount = pr.TaskCount
+	p.Properties.CommentCount = pr.CommentCount
+	p.Links.Self = []struct {
+		Href string `json:"href"`
+	}{{Href: pr.Links.HTML.Href}}
+
+	for _, participant := range pr.Participants {
+		if participant.Role != ParticipantRoleReviewer {
+			continue
+		}
+
+		status := bitbucket.PullRequestReviewStatusUnapproved
+		switch {
+		case participant.Approved || participant.State == ParticipantStateApproved:
+			status = bitbucket.PullRequestReviewStatusApproved
+		case participant.State == ParticipantStateChangesRequested:
+			status = bitbucket.PullRequestReviewStatusNeedsWork
+		}
+
+		p.Reviewers = append(p.Reviewers, bitbucket.Author{
+			User:        participant.User.toBitbucket(),
+			Role:        bitbucket.PullRequestUserRoleReviewer,
+			HasApproved: status == bitbucket.PullRequestReviewStatusApproved,
+			Status:      status,
+		})
+	}
+
+	return p
+}
+
+// toBitbucket returns the endpoint as a git ref in the Bitbucket Server model
+func (e Endpoint) toBitbucket() bitbucket.GitRef {
+	return bitbucket.GitRef{
+		ID:           bitbucket.RefPrefixBranch + e.Branch.Name,
+		DisplayID:    e.Branch.Name,
+		LatestCommit: e.Commit.Hash,
+		Repository:   e.Repository.ToBitbucket(),
+	}
+}
+
+// toBitbucket returns the account as a user in the Bitbucket Server model
+func (a Account) toBitbucket() bitbucket.User {
+	var u bitbucket.User
+
+	u.Name = a.Nickname
+	u.Slug = a.Nickname
+	u.DisplayName = a.DisplayName
+	if a.Links.HTML.Href != "" {
+		u.Links.Self = []struct {
+			Href string `json:"href"`
+		}{{Href: a.Links.HTML.Href}}
+	}
+
+	return u
+}
+
+// ToBitbucket returns the commit in the Bitbucket Server model
+func (c Commit) ToBitbucket() bitbucket.Commit {
+	var commit bitbucket.Commit
+
+	commit.ID = c.Hash
+	commit.DisplayID = c.Hash
+	if len(c.Hash) > 11 {
+		commit.DisplayID = c.Hash[:11]
+	}
+	commit.Message = c.Message
+	commit.AuthorTimestamp = millis(c.Date)
+	commit.CommitterTimestamp = commit.AuthorTimestamp
+
+	// The raw author is "Name <email>", the user is only set for authors
+	// with a Bitbucket account
+	if addr, err := mail.ParseAddress(c.Author.Raw); err == nil {
+		commit.Author.DisplayName = addr.Name
+		commit.Author.Email = addr.Address
+	} else {
+		commit.Author.DisplayName = c.Author.Raw
+	}
+	if c.Author.User != nil {
+		email := commit.Author.Email
+		commit.Author = c.Author.User.toBitbucket()
+		commit.Author.Email = email
+	}
+	commit.Committer = commit.Author
+
+	for _, p := range c.Parents {
+		commit.Parents = append(commit.Parents, struct {
+			ID        string `json:"id"`
+			DisplayID string `json:"displayId"`
+		}{ID: p.Hash, DisplayID: p.Hash})
+	}
+
+	return commit
+}
+
+// ToBitbucket returns the repository in the Bitbucket Server model
+func (r Repository) ToBitbucket() bitbucket.Repository {
+	repo := bitbucket.Repository{
+		Slug:        r.Slug,
+		Name:        r.Name,
+		Description: r.Description,
+		Public:      !r.IsPrivate,
+		Project: bitbucket.Project{
+			Key:  r.Workspace(),
+			Name: r.Project.Name,
+		},
+	}
+
+	if r.Links.HTML.Href != "" {
+		repo.Links.Self = []bitbucket.Link{{Href: r.Links.HTML.Href}}
+	}
+	for _, l := range r.Links.Clone {
+		repo.Links.Clone = append(repo.Links.Clone, bitbucket.Link{Href: l.Href, Name: l.Name})
+	}
+
+	if r.Parent != nil {
+		origin := r.Parent.ToBitbucket()
+		repo.Origin = &origin
+	}
+
+	return repo
+}
+
+// Workspace returns the workspace of the repository from its full name
+func (r Repository) Workspace() string {
+	return strings.SplitN(r.FullName, "/", 2)[0]
+}
+
+// ToBitbucket returns the status in the Bitbucket Server model. Stopped
+// builds are cancelled.
+func (s Status) ToBitbucket() bitbucket.Status {
+	state := s.State
+	if state == StatusStopped {
+		state = bitbucket.StatusCancelled
+	}
+
+	return bitbucket.Status{
+		State:       state,
+		Key:         s.Key,
+		Name:        s.Name,
+		URL:         s.URL,
+		Description: s.Description,
+		DateAdded:   int(s.CreatedOn.Unix()),
+	}
+}
+
+// millis returns t in milliseconds since epoch, as used by Bitbucket Server
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano() / int64(time.Millisecond)
+}
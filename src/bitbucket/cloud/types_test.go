@@ -0,0 +1,42 @@
+package cloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"gotest.tools/assert"
+)
+
+func TestCommitToBitbucket(t *testing.T) {
+	var c Commit
+	c.Hash = sha
+	c.Date = time.Unix(1624183200, 0)
+	c.Message = "ACE-456 Map cloud commits"
+	c.Author.Raw = "Jane Doe <jane@example.com>"
+
+	commit := c.ToBitbucket()
+	assert.Equal(t, commit.DisplayID, "c2646bb9a62")
+	assert.Equal(t, commit.AuthorTimestamp, int64(1624183200000))
+	assert.Equal(t, commit.Author.DisplayName, "Jane Doe")
+	assert.Equal(t, commit.Author.Email, "jane@example.com")
+
+	c.Author.Raw = "jane"
+	assert.Equal(t, c.ToBitbucket().Author.DisplayName, "jane")
+}
+
+func TestRepositoryToBitbucket(t *testing.T) {
+	r := Repository{Slug: repo, FullName: workspace + "/" + repo, IsPrivate: true}
+	r.Parent = &Repository{Slug: "upstream", FullName: "other/upstream"}
+
+	b := r.ToBitbucket()
+	assert.Equal(t, b.Project.Key, workspace)
+	assert.Equal(t, b.Public, false)
+	assert.Equal(t, b.Origin.Project.Key, "other")
+	assert.Equal(t, b.Origin.Slug, "upstream")
+}
+
+func TestStatusToBitbucket(t *testing.T) {
+	assert.Equal(t, Status{State: StatusStopped}.ToBitbucket().State, bitbucket.StatusCancelled)
+	assert.Equal(t, Status{State: bitbucket.StatusSuccess}.ToBitbucket().State, bitbucket.StatusSuccess)
+}
@@ -0,0 +1,75 @@
+package bitbucket
+
+// Comment is a Pull Request comment with its replies
+type Comment struct {
+	ID             int            `json:"id"`
+	Version        int            `json:"version"`
+	Text           string         `json:"text"`
+	Author         User           `json:"author"`
+	CreatedDate    int64          `json:"createdDate"`
+	UpdatedDate    int64          `json:"updatedDate"`
+	Severity       string         `json:"severity"`
+	State          string         `json:"state"`
+	ThreadResolved bool           `json:"threadResolved"`
+	Anchor         *CommentAnchor `json:"anchor,omitempty"`
+	Comments       []Comment      `json:"comments"`
+	Tasks          []Task         `json:"tasks"`
+}
+
+// CommentAnchor is the file and line a comment was made on
+type CommentAnchor struct {
+	Path     string `json:"path"`
+	SrcPath  string `json:"srcPath"`
+	Line     int    `json:"line"`
+	LineType string `json:"lineType"`
+	FileType string `json:"fileType"`
+	DiffType string `json:"diffType"`
+}
+
+// Task is a task on a comment in older Bitbucket versions. Newer versions
+// use comments with CommentSeverityBlocker instead.
+type Task struct {
+	ID    int    `json:"id"`
+	Text  string `json:"text"`
+	State string `json:"state"`
+}
+
+// Replies returns the number of replies in the comment thread
+func (c Comment) Replies() int {
+	n := len(c.Comments)
+	for _, reply := range c.Comments {
+		n += reply.Replies()
+	}
+
+	return n
+}
+
+// IsTask returns true if the comment is a task
+func (c Comment) IsTask() bool {
+	return c.Severity == CommentSeverityBlocker
+}
+
+// IsResolved returns true if the comment thread or task is resolved
+func (c Comment) IsResolved() bool {
+	return c.ThreadResolved || c.State == CommentStateResolved
+}
+
+// OpenTasks returns the number of open tasks in the comment thread
+func (c Comment) OpenTasks() int {
+	n := 0
+	if c.IsTask() && !c.IsResolved() {
+		n++
+	}
+
+	for _, t := range c.Tasks {
+		if t.State == CommentStateOpen {
+			n++
+		}
+	}
+
+	for _, reply := range c.Comments {
+		n += reply.OpenTasks()
+	}
+
+	return n
+}
@@ -0,0 +1,37 @@
+package bitbucket
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestComment(t *testing.T) {
+	comment := Comment{
+		Comments: []Comment{
+			{Comments: []Comment{{}}},
+			{Severity: CommentSeverityBlocker, State: CommentStateOpen},
+			{Severity: CommentSeverityBlocker, State: CommentStateResolved},
+		},
+		Tasks: []Task{{State: CommentStateOpen}, {State: CommentStateResolved}},
+	}
+
+	t.Run("should count nested replies", func(t *testing.T) {
+		assert.Equal(t, 4, comment.Replies())
+	})
+
+	t.Run("should count open tasks", func(t *testing.T) {
+		assert.Equal(t, 2, comment.OpenTasks())
+	})
+
+	t.Run("should be resolved when the thread is resolved", func(t *testing.T) {
+		assert.Equal(t, false, comment.IsResolved())
+		assert.Equal(t, true, Comment{ThreadResolved: true}.IsResolved())
+		assert.Equal(t, true, Comment{State: CommentStateResolved}.IsResolved())
+	})
+
+	t.Run("should be a task when it is a blocker", func(t *testing.T) {
+		assert.Equal(t, false, comment.IsTask())
+		assert.Equal(t, true, comment.Comments[1].IsTask())
+	})
+}
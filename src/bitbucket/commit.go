@@ -15,6 +15,7 @@ type CommitList struct {
 	Start         int      `json:"start"`
 	Limit         int      `json:"limit"`
 	NextPageStart int      `json:"nextPageStart"`
+	TotalCount    int      `json:"totalCount"`
 	Values        []Commit `json:"values"`
 }
 
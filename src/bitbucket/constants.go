@@ -1,12 +1,37 @@
 package bitbucket
 
+import "time"
+
 var APIPaths = map[string]string{
-	"base":         "https://%s/rest/api/1.0/%s",
-	"repo":         "projects/%s/repos/%s",
-	"repoCommits":  "projects/%s/repos/%s/commits",
-	"browse":       "projects/%s/repos/%s/browse/%s",
-	"pullRequests": "projects/%s/repos/%s/pull-requests",
-	"pullRequest":  "projects/%s/repos/%s/pull-requests/%s",
+	"base":           "https://%s/rest/api/1.0/%s",
+	"project":        "projects/%s",
+	"projectRepos":   "projects/%s/repos",
+	"repos":          "repos",
+	"repo":           "projects/%s/repos/%s",
+	"repoCommits":    "projects/%s/repos/%s/commits",
+	"commit":         "projects/%s/repos/%s/commits/%s",
+	"browse":         "projects/%s/repos/%s/browse/%s",
+	"branches":       "projects/%s/repos/%s/branches",
+	"defaultBranch":  "projects/%s/repos/%s/branches/default",
+	"tags":           "projects/%s/repos/%s/tags",
+	"pullRequests":   "projects/%s/repos/%s/pull-requests",
+	"pullRequest":    "projects/%s/repos/%s/pull-requests/%s",
+	"participant":    "projects/%s/repos/%s/pull-requests/%s/participants/%s",
+	"merge":          "projects/%s/repos/%s/pull-requests/%s/merge",
+	"changes":        "projects/%s/repos/%s/pull-requests/%s/changes",
+	"diff":           "projects/%s/repos/%s/pull-requests/%s/diff",
+	"fileDiff":       "projects/%s/repos/%s/pull-requests/%s/diff/%s",
+	"comment":        "projects/%s/repos/%s/pull-requests/%s/comments/%s",
+	"compare":        "projects/%s/repos/%s/compare/commits",
+	"compareChanges": "projects/%s/repos/%s/compare/changes",
+	"compareDiff":    "projects/%s/repos/%s/compare/diff",
+	"users":          "users",
+	"user":           "users/%s",
+}
+
+var PluginPaths = map[string]string{
+	"base":   "https://%s/plugins/servlet/%s",
+	"whoami": "applinks/whoami",
 }
 
 var StatusPaths = map[string]string{
@@ -14,7 +39,26 @@ var StatusPaths = map[string]string{
 	"status": "commits/%s",
 }
 
+var InsightsPaths = map[string]string{
+	"base":        "https://%s/rest/insights/1.0/%s",
+	"reports":     "projects/%s/repos/%s/commits/%s/reports",
+	"annotations": "projects/%s/repos/%s/commits/%s/annotations",
+}
+
 const (
+	// pullRequestPageSize is the number of Pull Requests per page when
+	// paging through Pull Request lists
+	pullRequestPageSize = 100
+
+	// statusPageSize is the number of build statuses per page when paging
+	// through build status lists
+	statusPageSize = 100
+
+	// diffTimeout is the timeout for getting Pull Request diffs and
+	// diffMaxBytes the largest diff read.
+	diffTimeout  = 10 * time.Second
+	diffMaxBytes = 5 << 20
+
 	// PullRequestReviewStatusNeedsWork is the status for a pull request review
 	// when the pull request needs more work before it can be merged.
 	PullRequestReviewStatusNeedsWork = "NEEDS_WORK"
@@ -25,6 +69,40 @@ const (
 	// PullRequestReviewStatusUnapproved is the status for an unapproved pull request review
 	PullRequestReviewStatusUnapproved = "UNAPPROVED"
 
+	// CommentSeverityBlocker is the severity of comments that are tasks
+	CommentSeverityBlocker = "BLOCKER"
+
+	// CommentStateOpen is the state of open comments and tasks
+	CommentStateOpen = "OPEN"
+
+	// CommentStateResolved is the state of resolved comments and tasks
+	CommentStateResolved = "RESOLVED"
+
+	// DiffSegmentAdded is the type of diff segments with added lines
+	DiffSegmentAdded = "ADDED"
+
+	// DiffSegmentRemoved is the type of diff segments with removed lines
+	DiffSegmentRemoved = "REMOVED"
+
+	// RefTypeBranch is the type of branch refs
+	RefTypeBranch = "BRANCH"
+
+	// RefTypeTag is the type of tag refs
+	RefTypeTag = "TAG"
+
+	// RefPrefixBranch is the prefix of fully qualified branch refs
+	RefPrefixBranch = "refs/heads/"
+
+	// RefPrefixTag is the prefix of fully qualified tag refs
+	RefPrefixTag = "refs/tags/"
+
+	// PermissionRepoRead is the permission to read a repository
+	PermissionRepoRead = "REPO_READ"
+	// PermissionProjectRead is the permission to read a project
+	PermissionProjectRead = "PROJECT_READ"
+	// PermissionLicensedUser is the global permission to log in to Bitbucket
+	PermissionLicensedUser = "LICENSED_USER"
+
 	PullRequestUserRoleAuthor   = "AUTHOR"
 	PullRequestUserRoleReviewer = "REVIEWER"
 )
@@ -0,0 +1,137 @@
+package bitbucket
+
+import "sort"
+
+// ChangeList is a list of files changed
+type ChangeList struct {
+	Size          int      `json:"size"`
+	Limit         int      `json:"limit"`
+	IsLastPage    bool     `json:"isLastPage"`
+	Start         int      `json:"start"`
+	NextPageStart int      `json:"nextPageStart"`
+	Values        []Change `json:"values"`
+}
+
+// Change is a file that was added, modified, moved or deleted
+type Change struct {
+	ContentID string `json:"contentId"`
+	Type      string `json:"type"`
+	Path      Path   `json:"path"`
+	SrcPath   *Path  `json:"srcPath,omitempty"`
+}
+
+// Path is the path of a file
+type Path struct {
+	Components []string `json:"components"`
+	Name       string   `json:"name"`
+	Extension  string   `json:"extension"`
+	ToString   string   `json:"toString"`
+}
+
+// Diff is the diff of a Pull Request or a commit
+type Diff struct {
+	FromHash     string     `json:"fromHash"`
+	ToHash       string     `json:"toHash"`
+	ContextLines int        `json:"contextLines"`
+	Whitespace   string     `json:"whitespace"`
+	Truncated    bool       `json:"truncated"`
+	Diffs        []FileDiff `json:"diffs"`
+}
+
+// FileDiff is the diff of a single file
+type FileDiff struct {
+	Source      *Path  `json:"source"`
+	Destination *Path  `json:"destination"`
+	Hunks       []Hunk `json:"hunks"`
+	Binary      bool   `json:"binary"`
+	Truncated   bool   `json:"truncated"`
+}
+
+// Hunk is a part of a file diff
+type Hunk struct {
+	SourceLine      int       `json:"sourceLine"`
+	SourceSpan      int       `json:"sourceSpan"`
+	DestinationLine int       `json:"destinationLine"`
+	DestinationSpan int       `json:"destinationSpan"`
+	Segments        []Segment `json:"segments"`
+	Truncated       bool      `json:"truncated"`
+}
+
+// Segment is a run of added, removed or context lines in a hunk
+type Segment struct {
+	Type  string `json:"type"`
+	Lines []struct {
+		Source      int    `json:"source"`
+		Destination int    `json:"destination"`
+		Line        string `json:"line"`
+	} `json:"lines"`
+	Truncated bool `json:"truncated"`
+}
+
+// FileStat is the number of lines added and removed in a file
+type FileStat struct {
+	Path    string
+	Added   int
+	Removed int
+}
+
+// Lines returns the number of lines changed in the file
+func (f FileStat) Lines() int {
+	return f.Added + f.Removed
+}
+
+// DiffStat is the number of lines added and removed in a diff
+type DiffStat struct {
+	Added   int
+	Removed int
+	Files   []FileStat
+}
+
+// Lines returns the number of lines changed in the diff
+func (d DiffStat) Lines() int {
+	return d.Added + d.Removed
+}
+
+// Path returns the path of the file after the change, or before it for
+// deleted files.
+func (f FileDiff) Path() string {
+	if f.Destination != nil {
+		return f.Destination.ToString
+	}
+
+	if f.Source != nil {
+		return f.Source.ToString
+	}
+
+	return ""
+}
+
+// Stat counts the lines added and removed in the diff. Files are sorted
+// with the most changed first.
+func (d Diff) Stat() DiffStat {
+	var stat DiffStat
+
+	for _, f := range d.Diffs {
+		fs := FileStat{Path: f.Path()}
+		for _, h := range f.Hunks {
+			for _, s := range h.Segments {
+				switch s.Type {
+				case DiffSegmentAdded:
+					fs.Added += len(s.Lines)
+				case DiffSegmentRemoved:
+					fs.Removed += len(s.Lines)
+				}
+			}
+		}
+
+		stat.Added += fs.Added
+		stat.Removed += fs.Removed
+		stat.Files = append(stat.Files, fs)
+	}
+
+	sort.SliceStable(stat.Files, func(i, j int) bool {
+		return stat.Files[i].Lines() > stat.Files[j].Lines()
+	})
+
+	return stat
+}
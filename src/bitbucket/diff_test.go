@@ -0,0 +1,37 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestDiffStat(t *testing.T) {
+	var diff Diff
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-diff-297.json")
+	assert.NilError(t, json.Unmarshal(httpmock.File(jsonFilePath).Bytes(), &diff))
+
+	stat := diff.Stat()
+
+	t.Run("should count lines", func(t *testing.T) {
+		assert.Equal(t, 57, stat.Added)
+		assert.Equal(t, 20, stat.Removed)
+		assert.Equal(t, 77, stat.Lines())
+	})
+
+	t.Run("should sort files by lines changed", func(t *testing.T) {
+		assert.DeepEqual(t, []FileStat{
+			{Path: "src/unfurl/bitbucket.go", Added: 40, Removed: 10},
+			{Path: "src/main.go", Added: 12, Removed: 3},
+			{Path: "docs/old.md", Added: 0, Removed: 7},
+			{Path: "README.md", Added: 5, Removed: 0},
+		}, stat.Files)
+	})
+
+	t.Run("should count nothing for empty diffs", func(t *testing.T) {
+		assert.Equal(t, 0, Diff{}.Stat().Lines())
+	})
+}
@@ -0,0 +1,188 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// InsightResultPass is the result of a passed Code Insights report
+	InsightResultPass = "PASS"
+	// InsightResultFail is the result of a failed Code Insights report
+	InsightResultFail = "FAIL"
+
+	// InsightDataPercentage is a report data value in percent
+	InsightDataPercentage = "PERCENTAGE"
+	// InsightDataNumber is a numeric report data value
+	InsightDataNumber = "NUMBER"
+	// InsightDataBoolean is a boolean report data value
+	InsightDataBoolean = "BOOLEAN"
+	// InsightDataText is a text report data value
+	InsightDataText = "TEXT"
+	// InsightDataLink is a link report data value
+	InsightDataLink = "LINK"
+	// InsightDataDate is a report data value in milliseconds since epoch
+	InsightDataDate = "DATE"
+	// InsightDataDuration is a report data value in milliseconds
+	InsightDataDuration = "DURATION"
+)
+
+// InsightReportList is a list of Code Insights reports
+type InsightReportList struct {
+	Size       int             `json:"size"`
+	Limit      int             `json:"limit"`
+	IsLastPage bool            `json:"isLastPage"`
+	Start      int             `json:"start"`
+	Values     []InsightReport `json:"values"`
+}
+
+// InsightReport is a Code Insights report for a commit
+type InsightReport struct {
+	Key         string        `json:"key"`
+	Title       string        `json:"title"`
+	Result      string        `json:"result,omitempty"`
+	Reporter    string        `json:"reporter,omitempty"`
+	Details     string        `json:"details,omitempty"`
+	Link        string        `json:"link,omitempty"`
+	Data        []InsightData `json:"data"`
+	CreatedDate int64         `json:"createdDate"`
+}
+
+// InsightData is a single metric in a Code Insights report
+type InsightData struct {
+	Title string          `json:"title"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// String returns the metric as a string, e.g. "Coverage 78%"
+func (d InsightData) String() string {
+	value := d.value()
+	if value == "" {
+		return d.Title
+	}
+
+	return fmt.Sprintf("%s %s", d.Title, value)
+}
+
+// value returns the value of the metric formatted by its type
+func (d InsightData) value() string {
+	switch d.Type {
+	case InsightDataPercentage, InsightDataNumber:
+		var n float64
+		if err := json.Unmarshal(d.Value, &n); err != nil {
+			return ""
+		}
+
+		s := strconv.FormatFloat(n, 'f', -1, 64)
+		if d.Type == InsightDataPercentage {
+			s = strconv.FormatFloat(n, 'f', 1, 64)
+			s = strings.TrimSuffix(s, ".0") + "%"
+		}
+		return s
+
+	case InsightDataBoolean:
+		var b bool
+		if err := json.Unmarshal(d.Value, &b); err != nil {
+			return ""
+		}
+
+		if b {
+			return "yes"
+		}
+		return "no"
+
+	case InsightDataLink:
+		var link struct {
+			LinkText string `json:"linktext"`
+			Href     string `json:"href"`
+		}
+		if err := json.Unmarshal(d.Value, &link); err != nil || link.Href == "" {
+			return ""
+		}
+
+		if link.LinkText == "" {
+			link.LinkText = link.Href
+		}
+		return fmt.Sprintf("<%s|%s>", link.Href, link.LinkText)
+
+	case InsightDataDate, InsightDataDuration:
+		var ms int64
+		if err := json.Unmarshal(d.Value, &ms); err != nil {
+			return ""
+		}
+
+		if d.Type == InsightDataDuration {
+			return (time.Duration(ms) * time.Millisecond).String()
+		}
+		return time.Unix(ms/1000, 0).UTC().Format("2006-01-02 15:04")
+	}
+
+	var s string
+	if err := json.Unmarshal(d.Value, &s); err != nil {
+		return string(d.Value)
+	}
+
+	return s
+}
+
+// InsightAnnotationList is a list of Code Insights annotations for a commit
+type InsightAnnotationList struct {
+	TotalCount  int                 `json:"totalCount"`
+	Annotations []InsightAnnotation `json:"annotations"`
+}
+
+// InsightAnnotation is a Code Insights annotation on a line of code
+type InsightAnnotation struct {
+	ReportKey string `json:"reportKey"`
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+	Type      string `json:"type,omitempty"`
+}
+
+// insightSeverities are the annotation severities from most to least severe
+var insightSeverities = []string{"HIGH", "MEDIUM", "LOW"}
+
+// Counts returns the number of annotations of a report by severity as a
+// string, e.g. "2 high, 1 low", or an empty string if there are none.
+func (al InsightAnnotationList) Counts(reportKey string) string {
+	counts := map[string]int{}
+	for _, a := range al.Annotations {
+		if a.ReportKey == reportKey {
+			counts[a.Severity]++
+		}
+	}
+
+	severities := []string{}
+	for s := range counts {
+		severities = append(severities, s)
+	}
+	sort.SliceStable(severities, func(i, j int) bool {
+		return severityRank(severities[i]) < severityRank(severities[j])
+	})
+
+	parts := []string{}
+	for _, s := range severities {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], strings.ToLower(s)))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// severityRank returns the position of an annotation severity, unknown
+// severities are ranked last
+func severityRank(severity string) int {
+	for i, s := range insightSeverities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return len(insightSeverities)
+}
@@ -0,0 +1,41 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestInsightDataString(t *testing.T) {
+	data := map[string]InsightData{
+		"Coverage 78%":                          {Title: "Coverage", Type: InsightDataPercentage, Value: json.RawMessage(`78.0`)},
+		"Duplication 2.4%":                      {Title: "Duplication", Type: InsightDataPercentage, Value: json.RawMessage(`2.35`)},
+		"Critical vulnerabilities 3":            {Title: "Critical vulnerabilities", Type: InsightDataNumber, Value: json.RawMessage(`3`)},
+		"Safe to merge yes":                     {Title: "Safe to merge", Type: InsightDataBoolean, Value: json.RawMessage(`true`)},
+		"Scanner v2.1":                          {Title: "Scanner", Type: InsightDataText, Value: json.RawMessage(`"v2.1"`)},
+		"Report <https://sonar.corp.org|Sonar>": {Title: "Report", Type: InsightDataLink, Value: json.RawMessage(`{"linktext":"Sonar","href":"https://sonar.corp.org"}`)},
+		"Scan time 1m35s":                       {Title: "Scan time", Type: InsightDataDuration, Value: json.RawMessage(`95000`)},
+		"Scanned 2021-11-24 15:34":              {Title: "Scanned", Type: InsightDataDate, Value: json.RawMessage(`1637768058000`)},
+		"Broken":                                {Title: "Broken", Type: InsightDataNumber, Value: json.RawMessage(`"n/a"`)},
+	}
+
+	for expected, d := range data {
+		assert.Equal(t, expected, d.String())
+	}
+}
+
+func TestInsightAnnotationListCounts(t *testing.T) {
+	al := InsightAnnotationList{
+		Annotations: []InsightAnnotation{
+			{ReportKey: "scan", Severity: "LOW"},
+			{ReportKey: "scan", Severity: "HIGH"},
+			{ReportKey: "scan", Severity: "HIGH"},
+			{ReportKey: "sonar", Severity: "MEDIUM"},
+		},
+	}
+
+	assert.Equal(t, "2 high, 1 low", al.Counts("scan"))
+	assert.Equal(t, "1 medium", al.Counts("sonar"))
+	assert.Equal(t, "", al.Counts("other"))
+}
@@ -11,15 +11,18 @@ import (
 
 // PullRequests is a list of Pull Requests
 type PullRequests struct {
-	Size       int           `json:"size"`
-	Limit      int           `json:"limit"`
-	IsLastPage bool          `json:"isLastPage"`
-	List       []PullRequest `json:"values"`
+	Size          int           `json:"size"`
+	Limit         int           `json:"limit"`
+	IsLastPage    bool          `json:"isLastPage"`
+	Start         int           `json:"start"`
+	NextPageStart int           `json:"nextPageStart"`
+	List          []PullRequest `json:"values"`
 }
 
 // PullRequest is a single Pull Request
 type PullRequest struct {
 	ID          int      `json:"id"`
+	Version     int      `json:"version"`
 	Title       string   `json:"title"`
 	Description string   `json:"description,omitempty"`
 	State       string   `json:"state"`
@@ -31,7 +34,12 @@ type PullRequest struct {
 	ToRef       GitRef   `json:"toRef"`
 	Author      Author   `json:"author"`
 	Reviewers   []Author `json:"reviewers"`
-	Links       struct {
+	Properties  struct {
+		OpenTaskCount     int `json:"openTaskCount"`
+		ResolvedTaskCount int `json:"resolvedTaskCount"`
+		CommentCount      int `json:"commentCount"`
+	} `json:"properties"`
+	Links struct {
 		Self []struct {
 			Href string `json:"href"`
 		} `json:"self"`
@@ -124,6 +132,28 @@ func (pr PullRequest) ApprovalStatus(showEmojis bool) string {
 	return "Unapproved"
 }
 
+// Tasks returns the open and resolved task counts of the Pull Request in
+// human readable format, or an empty string if it has no tasks.
+func (pr PullRequest) Tasks() string {
+	open := pr.Properties.OpenTaskCount
+	resolved := pr.Properties.ResolvedTaskCount
+
+	if open == 0 && resolved == 0 {
+		return ""
+	}
+
+	tasks := "tasks"
+	if open == 1 {
+		tasks = "task"
+	}
+
+	if resolved == 0 {
+		return fmt.Sprintf("%d open %s", open, tasks)
+	}
+
+	return fmt.Sprintf("%d open %s, %d resolved", open, tasks, resolved)
+}
+
 // GitRef is the git reference of a Pull Request
 type GitRef struct {
 	ID           string     `json:"id"`
@@ -131,3 +161,42 @@ type GitRef struct {
 	LatestCommit string     `json:"latestCommit"`
 	Repository   Repository `json:"repository"`
 }
+
+// MergeStatus is the result of the merge checks for a Pull Request
+type MergeStatus struct {
+	CanMerge   bool        `json:"canMerge"`
+	Conflicted bool        `json:"conflicted"`
+	Outcome    string      `json:"outcome"`
+	Vetoes     []MergeVeto `json:"vetoes"`
+}
+
+// MergeVeto is a merge check preventing a Pull Request from being merged
+type MergeVeto struct {
+	SummaryMessage  string `json:"summaryMessage"`
+	DetailedMessage string `json:"detailedMessage"`
+}
+
+// String returns the summaries of the merge vetoes as a string
+func (ms MergeStatus) String() string {
+	if ms.CanMerge {
+		return "Mergeable"
+	}
+
+	if len(ms.Vetoes) == 0 {
+		if ms.Conflicted {
+			return "Blocked: merge conflicts"
+		}
+
+		return "Blocked"
+	}
+
+	vetoes := make([]string, len(ms.Vetoes))
+	for i, veto := range ms.Vetoes {
+		vetoes[i] = veto.SummaryMessage
+		if vetoes[i] == "" {
+			vetoes[i] = veto.DetailedMessage
+		}
+	}
+
+	return fmt.Sprintf("Blocked: %s", strings.Join(vetoes, ", "))
+}
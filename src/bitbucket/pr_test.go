@@ -131,6 +131,32 @@ func TestPullRequestIsWorkInProgress(t *testing.T) {
 	})
 }
 
+func TestPullRequestTasks(t *testing.T) {
+	t.Run("returns empty string when pull request has no tasks", func(t *testing.T) {
+		pr := PullRequest{}
+		assert.Equal(t, "", pr.Tasks())
+	})
+
+	t.Run("returns open tasks", func(t *testing.T) {
+		pr := PullRequest{}
+		pr.Properties.OpenTaskCount = 1
+		assert.Equal(t, "1 open task", pr.Tasks())
+	})
+
+	t.Run("returns open and resolved tasks", func(t *testing.T) {
+		pr := PullRequest{}
+		pr.Properties.OpenTaskCount = 2
+		pr.Properties.ResolvedTaskCount = 1
+		assert.Equal(t, "2 open tasks, 1 resolved", pr.Tasks())
+	})
+
+	t.Run("returns resolved tasks", func(t *testing.T) {
+		pr := PullRequest{}
+		pr.Properties.ResolvedTaskCount = 3
+		assert.Equal(t, "0 open tasks, 3 resolved", pr.Tasks())
+	})
+}
+
 func TestPullRequestIsApproved(t *testing.T) {
 	t.Run("returns true when pull request is approved", func(t *testing.T) {
 		pr := PullRequest{
@@ -182,3 +208,25 @@ func TestPullRequestAppovalStatus(t *testing.T) {
 		assert.Equal(t, "Unapproved", pr.ApprovalStatus(false))
 	})
 }
+
+func TestMergeStatusString(t *testing.T) {
+	t.Run("returns mergeable when pull request can be merged", func(t *testing.T) {
+		ms := MergeStatus{CanMerge: true}
+		assert.Equal(t, "Mergeable", ms.String())
+	})
+
+	t.Run("returns vetoes when pull request is blocked", func(t *testing.T) {
+		ms := MergeStatus{
+			Vetoes: []MergeVeto{
+				{SummaryMessage: "Requires 2 approvals", DetailedMessage: "At least 2 approvals are required before this pull request can be merged."},
+				{DetailedMessage: "Requires 1 successful build"},
+			},
+		}
+		assert.Equal(t, "Blocked: Requires 2 approvals, Requires 1 successful build", ms.String())
+	})
+
+	t.Run("returns conflicts when pull request is conflicted", func(t *testing.T) {
+		ms := MergeStatus{Conflicted: true}
+		assert.Equal(t, "Blocked: merge conflicts", ms.String())
+	})
+}
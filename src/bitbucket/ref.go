@@ -0,0 +1,63 @@
+package bitbucket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RefList is a list of branches or tags
+type RefList struct {
+	Size          int   `json:"size"`
+	Limit         int   `json:"limit"`
+	IsLastPage    bool  `json:"isLastPage"`
+	Start         int   `json:"start"`
+	NextPageStart int   `json:"nextPageStart"`
+	Values        []Ref `json:"values"`
+}
+
+// Find returns the ref with the given name or id from the list
+func (rl RefList) Find(name string) (Ref, bool) {
+	for _, ref := range rl.Values {
+		if ref.ID == name || ref.DisplayID == name {
+			return ref, true
+		}
+	}
+
+	return Ref{}, false
+}
+
+// Ref is a branch or a tag in a repository
+type Ref struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	Type         string `json:"type"`
+	LatestCommit string `json:"latestCommit"`
+	IsDefault    bool   `json:"isDefault"`
+}
+
+// IsTag returns true if the ref is a tag
+func (r Ref) IsTag() bool {
+	return r.Type == RefTypeTag
+}
+
+// RefName returns the short name of a fully qualified ref such as
+// refs/heads/feature/foo or refs/tags/v1.2.0
+func RefName(ref string) string {
+	ref = strings.TrimPrefix(ref, RefPrefixBranch)
+	return strings.TrimPrefix(ref, RefPrefixTag)
+}
+
+// AheadBehind is the number of commits a ref is ahead and behind another ref
+type AheadBehind struct {
+	Ahead  int
+	Behind int
+}
+
+// String returns the ahead and behind counts as a string
+func (ab AheadBehind) String() string {
+	if ab.Ahead == 0 && ab.Behind == 0 {
+		return "Up to date"
+	}
+
+	return fmt.Sprintf("%d ahead, %d behind", ab.Ahead, ab.Behind)
+}
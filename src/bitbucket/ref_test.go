@@ -0,0 +1,44 @@
+package bitbucket
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRefListFind(t *testing.T) {
+	refs := RefList{
+		Values: []Ref{
+			{ID: "refs/heads/feature/foo-bar", DisplayID: "feature/foo-bar"},
+			{ID: "refs/heads/feature/foo", DisplayID: "feature/foo"},
+		},
+	}
+
+	t.Run("finds ref by display id", func(t *testing.T) {
+		ref, ok := refs.Find("feature/foo")
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "refs/heads/feature/foo", ref.ID)
+	})
+
+	t.Run("finds ref by id", func(t *testing.T) {
+		ref, ok := refs.Find("refs/heads/feature/foo-bar")
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "feature/foo-bar", ref.DisplayID)
+	})
+
+	t.Run("returns false for partial matches", func(t *testing.T) {
+		_, ok := refs.Find("feature")
+		assert.Equal(t, false, ok)
+	})
+}
+
+func TestRefName(t *testing.T) {
+	assert.Equal(t, "feature/foo", RefName("refs/heads/feature/foo"))
+	assert.Equal(t, "v1.2.0", RefName("refs/tags/v1.2.0"))
+	assert.Equal(t, "master", RefName("master"))
+}
+
+func TestAheadBehindString(t *testing.T) {
+	assert.Equal(t, "Up to date", AheadBehind{}.String())
+	assert.Equal(t, "3 ahead, 1 behind", AheadBehind{Ahead: 3, Behind: 1}.String())
+}
@@ -6,16 +6,37 @@ type Repository struct {
 	ID          int             `json:"id"`
 	Name        string          `json:"name"`
 	Description string          `json:"description"`
+	Public      bool            `json:"public"`
 	Project     Project         `json:"project"`
+	Origin      *Repository     `json:"origin,omitempty"`
 	Links       RepositoryLinks `json:"links"`
 }
 
+// RepositoryList is a list of repositories
+type RepositoryList struct {
+	Size          int          `json:"size"`
+	Limit         int          `json:"limit"`
+	IsLastPage    bool         `json:"isLastPage"`
+	Start         int          `json:"start"`
+	NextPageStart int          `json:"nextPageStart"`
+	Values        []Repository `json:"values"`
+}
+
 // RepositoryLinks are links to the repository
 type RepositoryLinks struct {
 	Self  []Link `json:"self"`
 	Clone []Link `json:"clone"`
 }
 
+// UserList is a list of users
+type UserList struct {
+	Size       int    `json:"size"`
+	Limit      int    `json:"limit"`
+	IsLastPage bool   `json:"isLastPage"`
+	Start      int    `json:"start"`
+	Values     []User `json:"values"`
+}
+
 // Link is a link to a resource
 type Link struct {
 	Href string `json:"href"`
@@ -28,6 +49,8 @@ type Project struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Links       Links  `json:"links"`
 }
 
 // Author is the creator of a Pull Request
@@ -42,8 +65,10 @@ type Author struct {
 type User struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
+	Slug        string `json:"slug"`
 	DisplayName string `json:"displayName"`
 	Email       string `json:"emailAddress"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
 	Links       struct {
 		Self []struct {
 			Href string `json:"href"`
@@ -1,5 +1,7 @@
 package bitbucket
 
+import "sort"
+
 const (
 	// StatusSuccess represents the success status
 	StatusSuccess = "SUCCESSFUL"
@@ -7,6 +9,8 @@ const (
 	StatusFailed = "FAILED"
 	// StatusRunning represents the running status
 	StatusInProgress = "INPROGRESS"
+	// StatusCancelled represents the cancelled status
+	StatusCancelled = "CANCELLED"
 	// StatusUnknown represents the unknown status
 	StatusUnknown = "UNKNOWN"
 )
@@ -23,11 +27,12 @@ type Status struct {
 
 // StatusList is a list of Status
 type StatusList struct {
-	Size       int      `json:"size"`
-	Limit      int      `json:"limit"`
-	IsLastPage bool     `json:"isLastPage"`
-	Start      int      `json:"start"`
-	Values     []Status `json:"values"`
+	Size          int      `json:"size"`
+	Limit         int      `json:"limit"`
+	IsLastPage    bool     `json:"isLastPage"`
+	Start         int      `json:"start"`
+	NextPageStart int      `json:"nextPageStart"`
+	Values        []Status `json:"values"`
 }
 
 // State returns the aggregated state of all statuses in the list
@@ -35,6 +40,7 @@ func (s StatusList) State() string {
 	isRunning := false
 	isSuccess := false
 	isFailed := false
+	isCancelled := false
 
 	for _, status := range s.Values {
 		switch status.State {
@@ -44,18 +50,53 @@ func (s StatusList) State() string {
 			isSuccess = true
 		case StatusFailed:
 			isFailed = true
+		case StatusCancelled:
+			isCancelled = true
 		}
 	}
 
-	// If there is a failed or running status, return that. Otherwise return the
-	// success status if it exists
+	// If there is a failed, running or cancelled status, return that.
+	// Otherwise return the success status if it exists
 	if isFailed {
 		return StatusFailed
 	} else if isRunning {
 		return StatusInProgress
+	} else if isCancelled {
+		return StatusCancelled
 	} else if isSuccess {
 		return StatusSuccess
 	}
 
 	return StatusUnknown
 }
+
+// statusOrder is the order statuses are listed in, failed builds first
+var statusOrder = []string{StatusFailed, StatusCancelled, StatusInProgress, StatusUnknown, StatusSuccess}
+
+// Sorted returns the statuses with failed builds first, then cancelled,
+// running, unknown and successful builds, each sorted by name.
+func (s StatusList) Sorted() []Status {
+	rank := func(state string) int {
+		for i, o := range statusOrder {
+			if o == state {
+				return i
+			}
+		}
+
+		// Other states are listed with the unknown ones
+		return 3
+	}
+
+	statuses := make([]Status, len(s.Values))
+	copy(statuses, s.Values)
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if rank(statuses[i].State) != rank(statuses[j].State) {
+			return rank(statuses[i].State) < rank(statuses[j].State)
+		}
+
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
@@ -75,4 +75,40 @@ func TestStatusListState(t *testing.T) {
 
 		assert.Equal(t, StatusSuccess, s.State())
 	})
+	t.Run("should return cancelled if any status is cancelled and none failed", func(t *testing.T) {
+		s := StatusList{
+			Values: []Status{
+				{
+					State: StatusSuccess,
+				},
+				{
+					State: StatusCancelled,
+				},
+			},
+		}
+
+		assert.Equal(t, StatusCancelled, s.State())
+	})
+}
+
+func TestStatusListSorted(t *testing.T) {
+	s := StatusList{
+		Values: []Status{
+			{Name: "Unit tests", State: StatusSuccess},
+			{Name: "Deploy", State: StatusInProgress},
+			{Name: "Lint", State: StatusFailed},
+			{Name: "Build", State: StatusSuccess},
+			{Name: "E2E", State: StatusCancelled},
+			{Name: "Docs", State: "SKIPPED"},
+			{Name: "Integration", State: StatusFailed},
+		},
+	}
+
+	names := []string{}
+	for _, status := range s.Sorted() {
+		names = append(names, status.Name)
+	}
+
+	assert.DeepEqual(t, []string{"Integration", "Lint", "E2E", "Deploy", "Docs", "Build", "Unit tests"}, names)
+	assert.Equal(t, "Unit tests", s.Values[0].Name)
 }
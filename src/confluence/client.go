@@ -0,0 +1,134 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Client is a Confluence Server client that is used for storing server
+// configuration, authentication and to run the actual Confluence requests.
+type Client struct {
+	Server    string
+	PAT       string
+	timeout   int
+	useragent string
+}
+
+// Timeout returns the configured connection timeout for the HTTP client.
+func (c Client) Timeout() int {
+	if c.timeout == 0 {
+		return 2
+	}
+
+	return c.timeout
+}
+
+// Useragent returns the configured client useragent or a default one.
+func (c Client) Useragent() string {
+	if c.useragent == "" {
+		return "confluence-go-sdk"
+	}
+
+	return c.useragent
+}
+
+// RawRequest does a API request and returns the content and the status code.
+// This is just a helper method used by other Client functions.
+// https://docs.atlassian.com/ConfluenceServer/rest/7.13.0/
+func (c Client) RawRequest(ctx context.Context, url string) ([]byte, int, error) {
+	httpClient := http.Client{
+		Timeout: time.Second * time.Duration(c.Timeout()),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", c.Useragent())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.PAT))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	return body, res.StatusCode, nil
+}
+
+// rawUrl returns a URL for a given API path and a list of path parameters.
+func (c Client) rawUrl(apis map[string]string, path string, args ...interface{}) string {
+	return fmt.Sprintf(apis["base"], c.Server, fmt.Sprintf(apis[path], args...))
+}
+
+// WebURL returns the absolute URL of a web UI link
+func (c Client) WebURL(path string) string {
+	return fmt.Sprintf(WebPaths["base"], c.Server, path)
+}
+
+// Page returns a single page by its ID
+func (c Client) Page(ctx context.Context, id string) (Content, error) {
+	var content Content
+
+	q := url.Values{}
+	q.Set("expand", ContentExpand)
+
+	u := fmt.Sprintf("%s?%s", c.rawUrl(APIPaths, "content", url.PathEscape(id)), q.Encode())
+	data, status, err := c.RawRequest(ctx, u)
+	if err != nil {
+		return content, err
+	}
+
+	if status != 200 {
+		return content, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &content); err != nil {
+		return content, err
+	}
+
+	return content, nil
+}
+
+// PageByTitle returns a single page by its space and title
+func (c Client) PageByTitle(ctx context.Context, space string, title string) (Content, error) {
+	var list ContentList
+
+	q := url.Values{}
+	q.Set("type", "page")
+	q.Set("spaceKey", space)
+	q.Set("title", title)
+	q.Set("expand", ContentExpand)
+
+	u := fmt.Sprintf("%s?%s", c.rawUrl(APIPaths, "search"), q.Encode())
+	data, status, err := c.RawRequest(ctx, u)
+	if err != nil {
+		return Content{}, err
+	}
+
+	if status != 200 {
+		return Content{}, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &list); err != nil {
+		return Content{}, err
+	}
+
+	if len(list.Results) == 0 {
+		return Content{}, &Error{StatusCode: 404, Message: fmt.Sprintf("no page %q in space %s", title, space)}
+	}
+
+	return list.Results[0], nil
+}
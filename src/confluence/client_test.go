@@ -0,0 +1,76 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const (
+	confluenceServer = "confluence.corp.org"
+	confluencePAT    = "my-token"
+
+	testdataDir = "../../testdata"
+)
+
+func TestConfluenceClientPage(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "confluence-page-123456.json")
+
+	// Set up mock Confluence Server
+	httpmock.RegisterResponderWithQuery("GET", fmt.Sprintf(APIPaths["base"], confluenceServer, fmt.Sprintf(APIPaths["content"], "123456")),
+		map[string]string{"expand": ContentExpand},
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(APIPaths["base"], confluenceServer, fmt.Sprintf(APIPaths["content"], "404")),
+		httpmock.NewStringResponder(404, `{"statusCode":404,"message":"No content found with id: ContentId{id=404}"}`))
+
+	client := Client{Server: confluenceServer, PAT: confluencePAT}
+
+	t.Run("should return page", func(t *testing.T) {
+		page, err := client.Page(context.Background(), "123456")
+		assert.NilError(t, err)
+		assert.Equal(t, "Deploy Runbook", page.Title)
+		assert.Equal(t, "OPS", page.Space.Key)
+		assert.Equal(t, 2, len(page.Ancestors))
+		assert.Equal(t, "User A", page.Version.By.DisplayName)
+	})
+
+	t.Run("should return ErrNotFound for missing pages", func(t *testing.T) {
+		_, err := client.Page(context.Background(), "404")
+		assert.Assert(t, errors.Is(err, ErrNotFound))
+		assert.ErrorContains(t, err, "No content found")
+	})
+}
+
+func TestConfluenceClientPageByTitle(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "confluence-page-search.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], confluenceServer, APIPaths["search"])
+
+	// Set up mock Confluence Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, "type=page&spaceKey=OPS&title=Deploy+Runbook&expand="+ContentExpand,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, `{"results":[],"start":0,"limit":25,"size":0}`))
+
+	client := Client{Server: confluenceServer, PAT: confluencePAT}
+
+	t.Run("should return page", func(t *testing.T) {
+		page, err := client.PageByTitle(context.Background(), "OPS", "Deploy Runbook")
+		assert.NilError(t, err)
+		assert.Equal(t, "123456", page.ID)
+	})
+
+	t.Run("should return ErrNotFound for missing pages", func(t *testing.T) {
+		_, err := client.PageByTitle(context.Background(), "OPS", "Nothing")
+		assert.Assert(t, errors.Is(err, ErrNotFound))
+	})
+}
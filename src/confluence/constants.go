@@ -0,0 +1,18 @@
+package confluence
+
+var APIPaths = map[string]string{
+	"base":    "https://%s/rest/api/%s",
+	"content": "content/%s",
+	"search":  "content",
+}
+
+var WebPaths = map[string]string{
+	"base": "https://%s%s",
+}
+
+const (
+	// ContentExpand are the properties expanded when getting a page,
+	// including the view restrictions of its ancestors which it inherits.
+	ContentExpand = "space,ancestors,version,body.storage,restrictions.read.restrictions.user,restrictions.read.restrictions.group," +
+		"ancestors.restrictions.read.restrictions.user,ancestors.restrictions.read.restrictions.group"
+)
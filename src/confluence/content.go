@@ -0,0 +1,158 @@
+package confluence
+
+import (
+	"strings"
+	"time"
+)
+
+// Content is a Confluence page or blog post
+type Content struct {
+	ID           string       `json:"id"`
+	Type         string       `json:"type"`
+	Status       string       `json:"status"`
+	Title        string       `json:"title"`
+	Space        Space        `json:"space"`
+	Ancestors    []Ancestor   `json:"ancestors"`
+	Version      Version      `json:"version"`
+	Body         Body         `json:"body"`
+	Restrictions Restrictions `json:"restrictions"`
+	Links        Links        `json:"_links"`
+}
+
+// ContentList is a list of content
+type ContentList struct {
+	Results []Content `json:"results"`
+	Start   int       `json:"start"`
+	Limit   int       `json:"limit"`
+	Size    int       `json:"size"`
+}
+
+// Space is the space content belongs to
+type Space struct {
+	ID    int    `json:"id"`
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Links Links  `json:"_links"`
+}
+
+// Ancestor is a parent page of content
+type Ancestor struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Restrictions Restrictions `json:"restrictions"`
+	Links        Links        `json:"_links"`
+}
+
+// Version is the current version of content
+type Version struct {
+	Number int    `json:"number"`
+	When   string `json:"when"`
+	By     User   `json:"by"`
+}
+
+// User is a Confluence user
+type User struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+}
+
+// Body is the body of content in storage format
+type Body struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+// Restrictions are the view restrictions of content. Restrictions that were
+// not expanded are nil.
+type Restrictions struct {
+	Read *struct {
+		Restrictions struct {
+			User  *RestrictionList `json:"user"`
+			Group *RestrictionList `json:"group"`
+		} `json:"restrictions"`
+	} `json:"read"`
+}
+
+// RestrictionList is a list of users or groups content is restricted to
+type RestrictionList struct {
+	Results []interface{} `json:"results"`
+	Size    int           `json:"size"`
+}
+
+// Links are the web UI links of content
+type Links struct {
+	WebUI  string `json:"webui"`
+	TinyUI string `json:"tinyui"`
+	Base   string `json:"base"`
+}
+
+// IsRestricted returns true if only some users or groups may view the
+// content, or if that is not known because the restrictions were not
+// expanded.
+func (r Restrictions) IsRestricted() bool {
+	if r.Read == nil || r.Read.Restrictions.User == nil || r.Read.Restrictions.Group == nil {
+		return true
+	}
+
+	return len(r.Read.Restrictions.User.Results) > 0 || len(r.Read.Restrictions.Group.Results) > 0
+}
+
+// IsRestricted returns true if the content or any of its ancestors has view
+// restrictions, as pages inherit the view restrictions of their parents.
+// Space permissions are not checked.
+func (c Content) IsRestricted() bool {
+	if c.Restrictions.IsRestricted() {
+		return true
+	}
+
+	for _, a := range c.Ancestors {
+		if a.Restrictions.IsRestricted() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Breadcrumb returns the space and the ancestors of content
+func (c Content) Breadcrumb() string {
+	crumbs := []string{c.Space.Name}
+	for _, a := range c.Ancestors {
+		crumbs = append(crumbs, a.Title)
+	}
+
+	return strings.Join(crumbs, " / ")
+}
+
+// Excerpt returns up to max characters of the body as plain text
+func (c Content) Excerpt(max int) string {
+	return truncate(PlainText(c.Body.Storage.Value), max)
+}
+
+// LastModified returns when the content was last modified
+func (c Content) LastModified() time.Time {
+	t, err := time.Parse(time.RFC3339, c.Version.When)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
+
+// truncate returns s shortened to max characters, on a word boundary when
+// possible
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if max <= 0 || len(r) <= max {
+		return s
+	}
+
+	cut := string(r[:max])
+	if i := strings.LastIndexAny(cut, " \n"); i > max/2 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimSpace(cut) + "…"
+}
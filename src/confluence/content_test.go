@@ -0,0 +1,85 @@
+package confluence
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func readPage(t *testing.T, file string) Content {
+	data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", testdataDir, file))
+	assert.NilError(t, err)
+
+	var page Content
+	assert.NilError(t, json.Unmarshal(data, &page))
+
+	return page
+}
+
+func TestContent(t *testing.T) {
+	page := readPage(t, "confluence-page-123456.json")
+
+	t.Run("should return breadcrumb", func(t *testing.T) {
+		assert.Equal(t, "Operations / Operations Home / Runbooks", page.Breadcrumb())
+	})
+
+	t.Run("should return last modified time", func(t *testing.T) {
+		assert.Equal(t, int64(1636548133), page.LastModified().Unix())
+	})
+
+	t.Run("should not be restricted", func(t *testing.T) {
+		assert.Equal(t, false, page.IsRestricted())
+	})
+
+	t.Run("should be restricted", func(t *testing.T) {
+		assert.Equal(t, true, readPage(t, "confluence-page-654321.json").IsRestricted())
+	})
+
+	t.Run("should inherit restrictions of ancestors", func(t *testing.T) {
+		child := readPage(t, "confluence-page-123456.json")
+		child.Ancestors[1].Restrictions = readPage(t, "confluence-page-654321.json").Restrictions
+
+		assert.Equal(t, true, child.IsRestricted())
+	})
+
+	t.Run("should be restricted when restrictions are unknown", func(t *testing.T) {
+		child := readPage(t, "confluence-page-123456.json")
+		child.Ancestors[0].Restrictions = Restrictions{}
+
+		assert.Equal(t, true, child.IsRestricted())
+	})
+
+	t.Run("should truncate excerpt on a word", func(t *testing.T) {
+		assert.Equal(t, "Overview\nThis page…", page.Excerpt(20))
+	})
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		storage  string
+		expected string
+	}{
+		{"paragraphs", "<p>One</p><p>Two</p>", "One\nTwo"},
+		{"inline markup", "<p>Some <strong>bold</strong> &amp; <em>nice</em>&nbsp;text</p>", "Some bold & nice text"},
+		{"macros", `<p>Before</p><ac:structured-macro ac:name="code"><ac:plain-text-body><![CDATA[secret code]]></ac:plain-text-body></ac:structured-macro><p>After</p>`, "Before\nAfter"},
+		{"links", `<p>See <ac:link><ri:page ri:content-title="Other" /><ac:plain-text-link-body><![CDATA[the other page]]></ac:plain-text-link-body></ac:link></p>`, "See the other page"},
+		{"lists", "<ul><li>One</li><li>Two</li></ul>", "One\nTwo"},
+		{"invalid markup", "<p>Unclosed <b>tag</p><p>More", "Unclosed tag\nMore"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("should render "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlainText(tt.storage))
+		})
+	}
+
+	t.Run("should render page body", func(t *testing.T) {
+		page := readPage(t, "confluence-page-123456.json")
+		assert.Equal(t, "Overview\nThis page describes how we deploy services & roll back.\nBuild the image\nRun the deploy job\nAsk in the channel if stuck", PlainText(page.Body.Storage.Value))
+	})
+}
@@ -0,0 +1,59 @@
+package confluence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when a page does not exist or the user is not
+	// allowed to see it.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when the token is missing, invalid or
+	// lacks permissions.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Error is an error response from the Confluence API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code and the error message from Confluence
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP request failed with unexpected status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("HTTP request failed with status code %d: %s", e.StatusCode, e.Message)
+}
+
+// Is makes errors.Is match Error against ErrNotFound and ErrUnauthorized
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	}
+
+	return false
+}
+
+// responseError returns an Error with the message from a Confluence error
+// response
+func responseError(data []byte, status int) error {
+	var res struct {
+		Message string `json:"message"`
+	}
+
+	e := &Error{StatusCode: status}
+	if err := json.Unmarshal(data, &res); err == nil {
+		e.Message = res.Message
+	}
+
+	return e
+}
@@ -0,0 +1,91 @@
+package confluence
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
+// skippedElements are storage format elements whose content is not text,
+// such as macros, which may contain code or render other pages.
+var skippedElements = map[string]bool{
+	"structured-macro": true,
+	"macro":            true,
+	"image":            true,
+	"emoticon":         true,
+	"placeholder":      true,
+	"parameter":        true,
+	"style":            true,
+	"script":           true,
+}
+
+// blockElements are elements that start a new line
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "table": true, "ul": true, "ol": true,
+}
+
+// autoClose are the void elements that may be left unclosed. Unlike
+// xml.HTMLAutoClose it does not have link, which is also the name of the
+// ac:link element.
+var autoClose = []string{"br", "hr", "img", "col", "wbr"}
+
+var (
+	spaces   = regexp.MustCompile(`[ \t\r\f\v\x{00a0}]+`)
+	newlines = regexp.MustCompile(`\s*\n\s*`)
+)
+
+// PlainText renders a page body in storage format as plain text. Macros and
+// other non-text elements are left out. Invalid markup gives as much text as
+// could be read.
+func PlainText(storage string) string {
+	d := xml.NewDecoder(strings.NewReader("<root>" + storage + "</root>"))
+	d.Strict = false
+	d.AutoClose = autoClose
+	d.Entity = xml.HTMLEntity
+
+	var b strings.Builder
+	skip := 0
+
+	for {
+		tok, err := d.Token()
+		// Stop at the end, or at the first error in invalid markup
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || skippedElements[t.Name.Local] {
+				skip++
+				continue
+			}
+			if blockElements[t.Name.Local] {
+				b.WriteString("\n")
+			}
+			if t.Name.Local == "td" || t.Name.Local == "th" {
+				b.WriteString(" ")
+			}
+
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if blockElements[t.Name.Local] {
+				b.WriteString("\n")
+			}
+
+		case xml.CharData:
+			if skip == 0 {
+				b.Write(t)
+			}
+		}
+	}
+
+	s := spaces.ReplaceAllString(b.String(), " ")
+	s = newlines.ReplaceAllString(s, "\n")
+
+	return strings.TrimSpace(s)
+}
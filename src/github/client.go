@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a GitHub Enterprise Server client that is used for storing server
+// configuration, authentication and to run the actual GitHub requests.
+type Client struct {
+	// Server is the GitHub Enterprise hostname links are unfurled for
+	Server string
+
+	// APIURL is the REST API base URL, https://<Server>/api/v3 when empty
+	APIURL string
+
+	Token     string
+	timeout   int
+	useragent string
+}
+
+// Timeout returns the configured connection timeout for the HTTP client.
+func (c Client) Timeout() int {
+	if c.timeout == 0 {
+		return 2
+	}
+
+	return c.timeout
+}
+
+// Useragent returns the configured client useragent or a default one.
+func (c Client) Useragent() string {
+	if c.useragent == "" {
+		return "github-go-sdk"
+	}
+
+	return c.useragent
+}
+
+// RawRequest does a API request and returns the content and the status code.
+// This is just a helper method used by other Client functions.
+// https://docs.github.com/en/enterprise-server@3.4/rest
+func (c Client) RawRequest(ctx context.Context, url string) ([]byte, int, error) {
+	httpClient := http.Client{
+		Timeout: time.Second * time.Duration(c.Timeout()),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", c.Useragent())
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Add("Authorization", fmt.Sprintf("token %s", c.Token))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	return body, res.StatusCode, nil
+}
+
+// BaseURL returns the REST API base URL without a trailing slash
+func (c Client) BaseURL() string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+
+	return fmt.Sprintf("https://%s%s", c.Server, APIPrefix)
+}
+
+// rawUrl returns a URL for a given API path and a list of path parameters.
+func (c Client) rawUrl(path string, args ...interface{}) string {
+	return fmt.Sprintf(APIPaths["base"], c.BaseURL(), fmt.Sprintf(APIPaths[path], args...))
+}
+
+// get does a API request and decodes the JSON response into v.
+func (c Client) get(ctx context.Context, url string, v interface{}) error {
+	data, status, err := c.RawRequest(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	if status != 200 {
+		return responseError(data, status)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Repository returns a single repository
+func (c Client) Repository(ctx context.Context, owner string, repo string) (Repository, error) {
+	var r Repository
+
+	err := c.get(ctx, c.rawUrl("repo", owner, repo), &r)
+
+	return r, err
+}
+
+// PullRequest returns a single pull request
+func (c Client) PullRequest(ctx context.Context, owner string, repo string, number int) (PullRequest, error) {
+	var pr PullRequest
+
+	err := c.get(ctx, c.rawUrl("pullRequest", owner, repo, number), &pr)
+
+	return pr, err
+}
+
+// Reviews returns the first page of reviews of a pull request, oldest first
+func (c Client) Reviews(ctx context.Context, owner string, repo string, number int) (ReviewList, error) {
+	var reviews ReviewList
+
+	u := fmt.Sprintf("%s?per_page=%d", c.rawUrl("reviews", owner, repo, number), PageSize)
+	err := c.get(ctx, u, &reviews)
+
+	return reviews, err
+}
+
+// Issue returns a single issue. Pull requests are issues too, and have
+// PullRequest set.
+func (c Client) Issue(ctx context.Context, owner string, repo string, number int) (Issue, error) {
+	var issue Issue
+
+	err := c.get(ctx, c.rawUrl("issue", owner, repo, number), &issue)
+
+	return issue, err
+}
+
+// Commit returns a single commit with its stats
+func (c Client) Commit(ctx context.Context, owner string, repo string, ref string) (Commit, error) {
+	var commit Commit
+
+	err := c.get(ctx, c.rawUrl("commit", owner, repo, url.PathEscape(ref)), &commit)
+
+	return commit, err
+}
+
+// Checks returns the combined check runs and commit statuses of a ref
+func (c Client) Checks(ctx context.Context, owner string, repo string, ref string) (Checks, error) {
+	var checks Checks
+
+	u := fmt.Sprintf("%s?per_page=%d", c.rawUrl("checkRuns", owner, repo, url.PathEscape(ref)), PageSize)
+	if err := c.get(ctx, u, &checks.CheckRuns); err != nil {
+		return checks, err
+	}
+
+	u = fmt.Sprintf("%s?per_page=%d", c.rawUrl("status", owner, repo, url.PathEscape(ref)), PageSize)
+	if err := c.get(ctx, u, &checks.Status); err != nil {
+		return checks, err
+	}
+
+	return checks, nil
+}
+
+// Contents returns a file at a given ref
+func (c Client) Contents(ctx context.Context, owner string, repo string, path string, ref string) (Content, error) {
+	var content Content
+
+	q := url.Values{}
+	q.Set("ref", ref)
+
+	u := fmt.Sprintf("%s?%s", c.rawUrl("contents", owner, repo, escapePath(path)), q.Encode())
+	err := c.get(ctx, u, &content)
+
+	return content, err
+}
+
+// WorkflowRun returns a single GitHub Actions workflow run
+func (c Client) WorkflowRun(ctx context.Context, owner string, repo string, id int) (WorkflowRun, error) {
+	var run WorkflowRun
+
+	err := c.get(ctx, c.rawUrl("run", owner, repo, id), &run)
+
+	return run, err
+}
+
+// escapePath escapes each segment of a file path
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const (
+	githubServer = "github.corp.org"
+	githubToken  = "my-token"
+	owner        = "my-org"
+	repo         = "my-repo"
+
+	testdataDir = "../../testdata"
+)
+
+var client = Client{Server: githubServer, Token: githubToken}
+
+// apiURL returns the mocked API URL for a path
+func apiURL(path string, args ...interface{}) string {
+	return fmt.Sprintf(APIPaths["base"], "https://"+githubServer+APIPrefix, fmt.Sprintf(APIPaths[path], args...))
+}
+
+func TestGitHubClientBaseURL(t *testing.T) {
+	assert.Equal(t, client.BaseURL(), "https://github.corp.org/api/v3")
+	assert.Equal(t, Client{Server: githubServer, APIURL: "https://api.github.corp.org/"}.BaseURL(), "https://api.github.corp.org")
+}
+
+func TestGitHubClientPullRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", apiURL("pullRequest", owner, repo, 7),
+		func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "token "+githubToken {
+				return httpmock.NewStringResponse(401, `{"message":"Bad credentials"}`), nil
+			}
+
+			return httpmock.NewStringResponse(200, httpmock.File(testdataDir+"/github-pull-request-7.json").String()), nil
+		})
+	httpmock.RegisterResponder("GET", apiURL("pullRequest", owner, repo, 404),
+		httpmock.NewStringResponder(404, `{"message":"Not Found"}`))
+
+	t.Run("should return pull request", func(t *testing.T) {
+		pr, err := client.PullRequest(context.Background(), owner, repo, 7)
+		assert.NilError(t, err)
+		assert.Equal(t, pr.Number, 7)
+		assert.Equal(t, pr.StateName(), "OPEN")
+		assert.Equal(t, pr.Head.Ref, "feature/github")
+	})
+
+	t.Run("should return ErrUnauthorized for bad tokens", func(t *testing.T) {
+		_, err := Client{Server: githubServer, Token: "wrong"}.PullRequest(context.Background(), owner, repo, 7)
+		assert.Assert(t, errors.Is(err, ErrUnauthorized))
+		assert.ErrorContains(t, err, "Bad credentials")
+	})
+
+	t.Run("should return ErrNotFound for missing pull requests", func(t *testing.T) {
+		_, err := client.PullRequest(context.Background(), owner, repo, 404)
+		assert.Assert(t, errors.Is(err, ErrNotFound))
+	})
+}
+
+func TestGitHubClientChecks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	sha := "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	httpmock.RegisterResponderWithQuery("GET", apiURL("checkRuns", owner, repo, sha), "per_page=100",
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/github-check-runs.json").String()))
+	httpmock.RegisterResponderWithQuery("GET", apiURL("status", owner, repo, sha), "per_page=100",
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/github-commit-status.json").String()))
+
+	checks, err := client.Checks(context.Background(), owner, repo, sha)
+	assert.NilError(t, err)
+	assert.Equal(t, len(checks.CheckRuns.CheckRuns), 2)
+	assert.Equal(t, checks.Status.State, StatusStateFailure)
+}
+
+func TestGitHubClientContents(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponderWithQuery("GET", apiURL("contents", owner, repo, "cmd/my%20app/main.go"), "ref=main",
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/github-contents.json").String()))
+
+	content, err := client.Contents(context.Background(), owner, repo, "cmd/my app/main.go", "main")
+	assert.NilError(t, err)
+
+	text, err := content.Decode()
+	assert.NilError(t, err)
+	assert.Equal(t, text, "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n")
+}
@@ -0,0 +1,52 @@
+package github
+
+var APIPaths = map[string]string{
+	"base":        "%s/%s",
+	"repo":        "repos/%s/%s",
+	"pullRequest": "repos/%s/%s/pulls/%d",
+	"reviews":     "repos/%s/%s/pulls/%d/reviews",
+	"issue":       "repos/%s/%s/issues/%d",
+	"commit":      "repos/%s/%s/commits/%s",
+	"checkRuns":   "repos/%s/%s/commits/%s/check-runs",
+	"status":      "repos/%s/%s/commits/%s/status",
+	"contents":    "repos/%s/%s/contents/%s",
+	"run":         "repos/%s/%s/actions/runs/%d",
+}
+
+const (
+	// APIPrefix is the path of the REST API on GitHub Enterprise Server
+	APIPrefix = "/api/v3"
+
+	// PageSize is the max number of items requested from list endpoints
+	PageSize = 100
+
+	// StateOpen and StateClosed are the states of issues and pull requests
+	StateOpen   = "open"
+	StateClosed = "closed"
+
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+	ReviewStateDismissed        = "DISMISSED"
+
+	// CheckStatusCompleted is the status of finished check runs and
+	// workflow runs, which then have a conclusion
+	CheckStatusCompleted = "completed"
+
+	ConclusionSuccess        = "success"
+	ConclusionFailure        = "failure"
+	ConclusionCancelled      = "cancelled"
+	ConclusionTimedOut       = "timed_out"
+	ConclusionActionRequired = "action_required"
+	ConclusionNeutral        = "neutral"
+	ConclusionSkipped        = "skipped"
+
+	// Commit status states
+	StatusStateSuccess = "success"
+	StatusStatePending = "pending"
+	StatusStateFailure = "failure"
+	StatusStateError   = "error"
+
+	// VisibilityPublic is the visibility of public repositories
+	VisibilityPublic = "public"
+)
@@ -0,0 +1,334 @@
+package github
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// User is a GitHub user or app
+type User struct {
+	Login     string `json:"login"`
+	HTMLURL   string `json:"html_url"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// Repository is a GitHub repository
+type Repository struct {
+	FullName   string `json:"full_name"`
+	Private    bool   `json:"private"`
+	Visibility string `json:"visibility"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// Public returns true if anyone can read the repository. Internal
+// repositories are only visible to members of the enterprise.
+func (r Repository) Public() bool {
+	return !r.Private && (r.Visibility == "" || r.Visibility == VisibilityPublic)
+}
+
+// Ref is the head or base of a pull request
+type Ref struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// PullRequest is a GitHub pull request
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	State          string     `json:"state"`
+	Draft          bool       `json:"draft"`
+	Merged         bool       `json:"merged"`
+	HTMLURL        string     `json:"html_url"`
+	User           User       `json:"user"`
+	CreatedAt      time.Time  `json:"created_at"`
+	MergedAt       *time.Time `json:"merged_at"`
+	Head           Ref        `json:"head"`
+	Base           Ref        `json:"base"`
+	Comments       int        `json:"comments"`
+	ReviewComments int        `json:"review_comments"`
+	Additions      int        `json:"additions"`
+	Deletions      int        `json:"deletions"`
+	ChangedFiles   int        `json:"changed_files"`
+}
+
+// StateName returns the state of the pull request in the same form as
+// Bitbucket, e.g. OPEN, DRAFT, MERGED or CLOSED.
+func (pr PullRequest) StateName() string {
+	switch {
+	case pr.Merged || pr.MergedAt != nil:
+		return "MERGED"
+	case pr.State == StateOpen && pr.Draft:
+		return "DRAFT"
+	}
+
+	return strings.ToUpper(pr.State)
+}
+
+// Review is a pull request review
+type Review struct {
+	User        User      `json:"user"`
+	State       string    `json:"state"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// ReviewList are the reviews of a pull request, oldest first
+type ReviewList []Review
+
+// Latest returns the latest approving, changes requested or dismissed review
+// of each reviewer, in the order they first reviewed. Comments do not change
+// the state of a review.
+func (rl ReviewList) Latest() ReviewList {
+	var latest ReviewList
+	index := map[string]int{}
+
+	for _, r := range rl {
+		if r.State != ReviewStateApproved && r.State != ReviewStateChangesRequested && r.State != ReviewStateDismissed {
+			continue
+		}
+
+		if i, ok := index[r.User.Login]; ok {
+			latest[i] = r
+			continue
+		}
+
+		index[r.User.Login] = len(latest)
+		latest = append(latest, r)
+	}
+
+	return latest
+}
+
+// ReviewedBy returns the reviewers with their latest review state in human
+// readable format.
+func (rl ReviewList) ReviewedBy() string {
+	var reviewers []string
+	for _, r := range rl.Latest() {
+		if r.State != ReviewStateDismissed {
+			reviewers = append(reviewers, fmt.Sprintf("%s (%s)", r.User.Login, r.State))
+		}
+	}
+
+	if len(reviewers) == 0 {
+		return "No reviews :sob:"
+	}
+
+	return strings.Join(reviewers, ", ")
+}
+
+// ReviewState returns the review state of the pull request. Changes requested
+// by any reviewer block approval.
+func (rl ReviewList) ReviewState(showEmojis bool) string {
+	approved := false
+	for _, r := range rl.Latest() {
+		switch r.State {
+		case ReviewStateChangesRequested:
+			if showEmojis {
+				return ":no_entry: Changes requested"
+			}
+
+			return "Changes requested"
+		case ReviewStateApproved:
+			approved = true
+		}
+	}
+
+	if approved {
+		if showEmojis {
+			return ":white_check_mark: Approved"
+		}
+
+		return "Approved"
+	}
+
+	if showEmojis {
+		return ":disappointed: Unapproved"
+	}
+
+	return "Unapproved"
+}
+
+// Label is an issue label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Milestone is an issue milestone
+type Milestone struct {
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Issue is a GitHub issue
+type Issue struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	State       string     `json:"state"`
+	StateReason string     `json:"state_reason"`
+	HTMLURL     string     `json:"html_url"`
+	User        User       `json:"user"`
+	Labels      []Label    `json:"labels"`
+	Assignees   []User     `json:"assignees"`
+	Milestone   *Milestone `json:"milestone"`
+	Comments    int        `json:"comments"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	PullRequest *struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"pull_request"`
+}
+
+// LabelNames returns the names of the issue labels
+func (i Issue) LabelNames() []string {
+	names := make([]string, len(i.Labels))
+	for n, l := range i.Labels {
+		names[n] = l.Name
+	}
+
+	return names
+}
+
+// Commit is a GitHub commit
+type Commit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *User `json:"author"`
+	Stats  struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+		Total     int `json:"total"`
+	} `json:"stats"`
+	Files []struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+}
+
+// ShortSHA returns the abbreviated commit hash
+func (c Commit) ShortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+
+	return c.SHA
+}
+
+// CheckRun is a check run, such as a GitHub Actions job
+type CheckRun struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Conclusion  string     `json:"conclusion"`
+	HTMLURL     string     `json:"html_url"`
+	DetailsURL  string     `json:"details_url"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	Output      struct {
+		Title string `json:"title"`
+	} `json:"output"`
+}
+
+// CheckRunList is a page of check runs
+type CheckRunList struct {
+	TotalCount int        `json:"total_count"`
+	CheckRuns  []CheckRun `json:"check_runs"`
+}
+
+// CommitStatus is a commit status set by an external CI server
+type CommitStatus struct {
+	State       string `json:"state"`
+	Context     string `json:"context"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// CombinedStatus is the combined commit status of a ref
+type CombinedStatus struct {
+	State      string         `json:"state"`
+	TotalCount int            `json:"total_count"`
+	Statuses   []CommitStatus `json:"statuses"`
+}
+
+// Checks are the check runs and commit statuses of a ref, which together
+// make up the checks shown on pull requests.
+type Checks struct {
+	CheckRuns CheckRunList
+	Status    CombinedStatus
+}
+
+// Content is a file in a repository
+type Content struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+	Path     string `json:"path"`
+	Size     int    `json:"size"`
+	HTMLURL  string `json:"html_url"`
+}
+
+// Decode returns the content of the file
+func (c Content) Decode() (string, error) {
+	if c.Encoding != "base64" {
+		return c.Content, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(c.Content, "\n", ""))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// WorkflowRun is a GitHub Actions workflow run
+type WorkflowRun struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	DisplayTitle string    `json:"display_title"`
+	RunNumber    int       `json:"run_number"`
+	RunAttempt   int       `json:"run_attempt"`
+	Event        string    `json:"event"`
+	Status       string    `json:"status"`
+	Conclusion   string    `json:"conclusion"`
+	HeadBranch   string    `json:"head_branch"`
+	HeadSHA      string    `json:"head_sha"`
+	HTMLURL      string    `json:"html_url"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RunStartedAt time.Time `json:"run_started_at"`
+	Actor        *User     `json:"actor"`
+}
+
+// Result returns the conclusion of a completed run, or its status
+func (r WorkflowRun) Result() string {
+	if r.Status == CheckStatusCompleted && r.Conclusion != "" {
+		return r.Conclusion
+	}
+
+	return r.Status
+}
+
+// Duration returns how long the run took, or has been running for
+func (r WorkflowRun) Duration(now time.Time) time.Duration {
+	started := r.RunStartedAt
+	if started.IsZero() {
+		started = r.CreatedAt
+	}
+
+	end := now
+	if r.Status == CheckStatusCompleted {
+		end = r.UpdatedAt
+	}
+
+	return end.Sub(started).Round(time.Second)
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestPullRequestStateName(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, PullRequest{State: StateOpen}.StateName(), "OPEN")
+	assert.Equal(t, PullRequest{State: StateOpen, Draft: true}.StateName(), "DRAFT")
+	assert.Equal(t, PullRequest{State: StateClosed, MergedAt: &now}.StateName(), "MERGED")
+	assert.Equal(t, PullRequest{State: StateClosed}.StateName(), "CLOSED")
+}
+
+func TestReviewList(t *testing.T) {
+	review := func(login string, state string) Review {
+		return Review{User: User{Login: login}, State: state}
+	}
+
+	t.Run("should use the latest review of each reviewer", func(t *testing.T) {
+		reviews := ReviewList{
+			review("alice", ReviewStateChangesRequested),
+			review("bob", ReviewStateCommented),
+			review("alice", ReviewStateApproved),
+		}
+
+		assert.Equal(t, reviews.ReviewedBy(), "alice (APPROVED)")
+		assert.Equal(t, reviews.ReviewState(true), ":white_check_mark: Approved")
+	})
+
+	t.Run("should block approval on requested changes", func(t *testing.T) {
+		reviews := ReviewList{
+			review("alice", ReviewStateApproved),
+			review("bob", ReviewStateChangesRequested),
+		}
+
+		assert.Equal(t, reviews.ReviewedBy(), "alice (APPROVED), bob (CHANGES_REQUESTED)")
+		assert.Equal(t, reviews.ReviewState(false), "Changes requested")
+	})
+
+	t.Run("should ignore dismissed reviews", func(t *testing.T) {
+		reviews := ReviewList{
+			review("alice", ReviewStateApproved),
+			review("alice", ReviewStateDismissed),
+		}
+
+		assert.Equal(t, reviews.ReviewedBy(), "No reviews :sob:")
+		assert.Equal(t, reviews.ReviewState(true), ":disappointed: Unapproved")
+	})
+}
+
+func TestRepositoryPublic(t *testing.T) {
+	assert.Equal(t, Repository{Visibility: VisibilityPublic}.Public(), true)
+	assert.Equal(t, Repository{Visibility: "internal"}.Public(), false)
+	assert.Equal(t, Repository{Private: true}.Public(), false)
+}
+
+func TestWorkflowRunDuration(t *testing.T) {
+	started := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	run := WorkflowRun{Status: "in_progress", RunStartedAt: started, UpdatedAt: started.Add(time.Minute)}
+
+	assert.Equal(t, run.Duration(started.Add(5*time.Minute)), 5*time.Minute)
+	assert.Equal(t, run.Result(), "in_progress")
+
+	run.Status = CheckStatusCompleted
+	run.Conclusion = ConclusionSuccess
+	assert.Equal(t, run.Duration(started.Add(5*time.Minute)), time.Minute)
+	assert.Equal(t, run.Result(), ConclusionSuccess)
+}
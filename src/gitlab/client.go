@@ -0,0 +1,259 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a self-managed GitLab client that is used for storing server
+// configuration, authentication and to run the actual GitLab requests.
+type Client struct {
+	// Server is the GitLab hostname links are unfurled for
+	Server string
+
+	// APIURL is the REST API base URL, https://<Server>/api/v4 when empty
+	APIURL string
+
+	Token     string
+	timeout   int
+	useragent string
+}
+
+// Timeout returns the configured connection timeout for the HTTP client.
+func (c Client) Timeout() int {
+	if c.timeout == 0 {
+		return 2
+	}
+
+	return c.timeout
+}
+
+// Useragent returns the configured client useragent or a default one.
+func (c Client) Useragent() string {
+	if c.useragent == "" {
+		return "gitlab-go-sdk"
+	}
+
+	return c.useragent
+}
+
+// RawRequest does a API request and returns the content and the status code.
+// This is just a helper method used by other Client functions.
+// https://docs.gitlab.com/ee/api/rest/
+func (c Client) RawRequest(ctx context.Context, url string) ([]byte, int, error) {
+	return c.request(ctx, url, time.Second*time.Duration(c.Timeout()), 0)
+}
+
+// request does a API request with the given timeout. Responses larger than
+// maxBytes fail with ErrResponseTooLarge, unless maxBytes is 0.
+func (c Client) request(ctx context.Context, url string, timeout time.Duration, maxBytes int64) ([]byte, int, error) {
+	httpClient := http.Client{
+		Timeout: timeout,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", c.Useragent())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Add("PRIVATE-TOKEN", c.Token)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	defer res.Body.Close()
+
+	var reader io.Reader = res.Body
+	if maxBytes > 0 {
+		reader = io.LimitReader(res.Body, maxBytes+1)
+	}
+
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return []byte{}, res.StatusCode, ErrResponseTooLarge
+	}
+
+	return body, res.StatusCode, nil
+}
+
+// BaseURL returns the REST API base URL without a trailing slash
+func (c Client) BaseURL() string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+
+	return fmt.Sprintf("https://%s%s", c.Server, APIPrefix)
+}
+
+// rawUrl returns a URL for a given API path and a list of path parameters.
+// The project path is the first parameter of all paths, and is escaped.
+func (c Client) rawUrl(path string, project string, args ...interface{}) string {
+	args = append([]interface{}{url.PathEscape(project)}, args...)
+
+	return fmt.Sprintf(APIPaths["base"], c.BaseURL(), fmt.Sprintf(APIPaths[path], args...))
+}
+
+// get does a API request and decodes the JSON response into v.
+func (c Client) get(ctx context.Context, url string, v interface{}) error {
+	data, status, err := c.RawRequest(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	if status != 200 {
+		return responseError(data, status)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Project returns a single project by its path, e.g. my-group/my-project
+func (c Client) Project(ctx context.Context, project string) (Project, error) {
+	var p Project
+
+	err := c.get(ctx, c.rawUrl("project", project), &p)
+
+	return p, err
+}
+
+// MergeRequest returns a single merge request
+func (c Client) MergeRequest(ctx context.Context, project string, iid int) (MergeRequest, error) {
+	var mr MergeRequest
+
+	err := c.get(ctx, c.rawUrl("mergeRequest", project, iid), &mr)
+
+	return mr, err
+}
+
+// Approvals returns the approval state of a merge request
+func (c Client) Approvals(ctx context.Context, project string, iid int) (Approvals, error) {
+	var a Approvals
+
+	err := c.get(ctx, c.rawUrl("approvals", project, iid), &a)
+
+	return a, err
+}
+
+// DiffStat returns the number of files and lines changed by a merge request.
+// The diffs are read a page at a time, up to diffMaxPages pages, and the stat
+// is truncated when not all of them could be read.
+func (c Client) DiffStat(ctx context.Context, project string, iid int) (DiffStat, error) {
+	var stat DiffStat
+
+	for page := 1; page <= diffMaxPages; page++ {
+		var diffs []Diff
+
+		u := fmt.Sprintf("%s?page=%d&per_page=%d", c.rawUrl("diffs", project, iid), page, PageSize)
+		data, status, err := c.request(ctx, u, diffTimeout, diffMaxBytes)
+		if errors.Is(err, ErrResponseTooLarge) {
+			stat.Truncated = true
+			return stat, nil
+		}
+		if err != nil {
+			return stat, err
+		}
+
+		if status != 200 {
+			return stat, responseError(data, status)
+		}
+
+		if err := json.Unmarshal(data, &diffs); err != nil {
+			return stat, err
+		}
+
+		s := NewDiffStat(diffs)
+		stat.Files += s.Files
+		stat.Additions += s.Additions
+		stat.Deletions += s.Deletions
+		stat.Truncated = stat.Truncated || s.Truncated
+
+		if len(diffs) < PageSize {
+			return stat, nil
+		}
+	}
+
+	stat.Truncated = true
+
+	return stat, nil
+}
+
+// Issue returns a single issue
+func (c Client) Issue(ctx context.Context, project string, iid int) (Issue, error) {
+	var issue Issue
+
+	err := c.get(ctx, c.rawUrl("issue", project, iid), &issue)
+
+	return issue, err
+}
+
+// Commit returns a single commit with its stats
+func (c Client) Commit(ctx context.Context, project string, sha string) (Commit, error) {
+	var commit Commit
+
+	u := fmt.Sprintf("%s?stats=true", c.rawUrl("commit", project, url.PathEscape(sha)))
+	err := c.get(ctx, u, &commit)
+
+	return commit, err
+}
+
+// RawFile returns the content of a file at a given ref. Files larger than
+// rawFileMaxBytes fail with ErrResponseTooLarge.
+func (c Client) RawFile(ctx context.Context, project string, path string, ref string) (string, error) {
+	q := url.Values{}
+	q.Set("ref", ref)
+
+	u := fmt.Sprintf("%s?%s", c.rawUrl("rawFile", project, url.PathEscape(path)), q.Encode())
+	data, status, err := c.request(ctx, u, time.Second*time.Duration(c.Timeout()), rawFileMaxBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if status != 200 {
+		return "", responseError(data, status)
+	}
+
+	return string(data), nil
+}
+
+// Pipeline returns a single pipeline
+func (c Client) Pipeline(ctx context.Context, project string, id int) (Pipeline, error) {
+	var p Pipeline
+
+	err := c.get(ctx, c.rawUrl("pipeline", project, id), &p)
+
+	return p, err
+}
+
+// PipelineJobs returns the first page of jobs of a pipeline
+func (c Client) PipelineJobs(ctx context.Context, project string, id int) ([]Job, error) {
+	var jobs []Job
+
+	u := fmt.Sprintf("%s?per_page=%d", c.rawUrl("pipelineJobs", project, id), PageSize)
+	err := c.get(ctx, u, &jobs)
+
+	return jobs, err
+}
+
+// Job returns a single job
+func (c Client) Job(ctx context.Context, project string, id int) (Job, error) {
+	var job Job
+
+	err := c.get(ctx, c.rawUrl("job", project, id), &job)
+
+	return job, err
+}
@@ -0,0 +1,183 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const (
+	gitlabToken = "my-token"
+	project     = "my-group/sub-group/my-project"
+	projectPath = "/api/v4/projects/my-group%2Fsub-group%2Fmy-project"
+
+	testdataDir = "../../testdata"
+)
+
+// newStub returns a local GitLab API serving routes by escaped path and
+// query. Route values are testdata files, or raw bodies when not JSON files.
+func newStub(t *testing.T, routes map[string]string) (*httptest.Server, Client) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != gitlabToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"401 Unauthorized"}`))
+			return
+		}
+
+		route := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			route += "?" + r.URL.RawQuery
+		}
+
+		body, ok := routes[route]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Not Found"}`))
+			return
+		}
+
+		if strings.HasSuffix(body, ".json") {
+			data, err := ioutil.ReadFile(testdataDir + "/" + body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = string(data)
+		}
+
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, Client{Server: "gitlab.corp.org", APIURL: srv.URL + APIPrefix, Token: gitlabToken}
+}
+
+func TestGitLabClientBaseURL(t *testing.T) {
+	assert.Equal(t, Client{Server: "gitlab.corp.org"}.BaseURL(), "https://gitlab.corp.org/api/v4")
+	assert.Equal(t, Client{APIURL: "https://gitlab.corp.org/api/v4/"}.BaseURL(), "https://gitlab.corp.org/api/v4")
+}
+
+func TestGitLabClientMergeRequest(t *testing.T) {
+	_, client := newStub(t, map[string]string{
+		projectPath + "/merge_requests/5":                           "gitlab-merge-request-5.json",
+		projectPath + "/merge_requests/5/approvals":                 "gitlab-mr-approvals-5.json",
+		projectPath + "/merge_requests/5/diffs?page=1&per_page=100": "gitlab-mr-diffs-5.json",
+	})
+	ctx := context.Background()
+
+	t.Run("should return merge request", func(t *testing.T) {
+		mr, err := client.MergeRequest(ctx, project, 5)
+		assert.NilError(t, err)
+		assert.Equal(t, mr.IID, 5)
+		assert.Equal(t, mr.StateName(), "OPEN")
+		assert.Equal(t, mr.HeadPipeline.Status, StatusFailed)
+	})
+
+	t.Run("should return approvals", func(t *testing.T) {
+		a, err := client.Approvals(ctx, project, 5)
+		assert.NilError(t, err)
+		assert.Equal(t, a.ReviewedBy(), "asmith (APPROVED)")
+		assert.Equal(t, a.ReviewState(false), "Unapproved, 1 approval left")
+	})
+
+	t.Run("should count changed lines", func(t *testing.T) {
+		stat, err := client.DiffStat(ctx, project, 5)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, stat, DiffStat{Files: 2, Additions: 4, Deletions: 1})
+	})
+
+	t.Run("should return the number of files changed", func(t *testing.T) {
+		mr, err := client.MergeRequest(ctx, project, 5)
+		assert.NilError(t, err)
+
+		files, more, err := mr.FilesChanged()
+		assert.NilError(t, err)
+		assert.Equal(t, files, 2)
+		assert.Equal(t, more, false)
+	})
+
+	t.Run("should return ErrNotFound for missing merge requests", func(t *testing.T) {
+		_, err := client.MergeRequest(ctx, project, 404)
+		assert.Assert(t, errors.Is(err, ErrNotFound))
+		assert.ErrorContains(t, err, "404 Not Found")
+	})
+
+	t.Run("should return ErrUnauthorized for bad tokens", func(t *testing.T) {
+		c := client
+		c.Token = "wrong"
+
+		_, err := c.MergeRequest(ctx, project, 5)
+		assert.Assert(t, errors.Is(err, ErrUnauthorized))
+	})
+}
+
+func TestGitLabClientRawFile(t *testing.T) {
+	_, client := newStub(t, map[string]string{
+		projectPath + "/repository/files/cmd%2Fmain.go/raw?ref=main": "package main\n",
+	})
+
+	text, err := client.RawFile(context.Background(), project, "cmd/main.go", "main")
+	assert.NilError(t, err)
+	assert.Equal(t, text, "package main\n")
+
+	_, client = newStub(t, map[string]string{
+		projectPath + "/repository/files/big.txt/raw?ref=main": strings.Repeat("x", rawFileMaxBytes+1),
+	})
+
+	_, err = client.RawFile(context.Background(), project, "big.txt", "main")
+	assert.Assert(t, errors.Is(err, ErrResponseTooLarge))
+}
+
+func TestGitLabClientDiffStatPages(t *testing.T) {
+	page := make([]string, PageSize)
+	for i := range page {
+		page[i] = `{"new_path":"f.go","diff":"@@ -1 +1 @@\n-a\n+b\n"}`
+	}
+	full := "[" + strings.Join(page, ",") + "]"
+
+	t.Run("should read all pages", func(t *testing.T) {
+		_, client := newStub(t, map[string]string{
+			projectPath + "/merge_requests/5/diffs?page=1&per_page=100": full,
+			projectPath + "/merge_requests/5/diffs?page=2&per_page=100": `[{"new_path":"g.go","diff":"@@ -0,0 +1 @@\n+c\n"}]`,
+		})
+
+		stat, err := client.DiffStat(context.Background(), project, 5)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, stat, DiffStat{Files: 101, Additions: 101, Deletions: 100})
+	})
+
+	t.Run("should stop after the last page read", func(t *testing.T) {
+		routes := map[string]string{}
+		for i := 1; i <= diffMaxPages; i++ {
+			routes[fmt.Sprintf("%s/merge_requests/5/diffs?page=%d&per_page=100", projectPath, i)] = full
+		}
+		_, client := newStub(t, routes)
+
+		stat, err := client.DiffStat(context.Background(), project, 5)
+		assert.NilError(t, err)
+		assert.Equal(t, stat.Files, diffMaxPages*PageSize)
+		assert.Equal(t, stat.Truncated, true)
+	})
+}
+
+func TestGitLabClientPipelineJobs(t *testing.T) {
+	_, client := newStub(t, map[string]string{
+		projectPath + "/pipelines/101/jobs?per_page=100": "gitlab-pipeline-jobs-101.json",
+	})
+
+	jobs, err := client.PipelineJobs(context.Background(), project, 101)
+	assert.NilError(t, err)
+
+	stages := Stages(jobs)
+	assert.Equal(t, len(stages), 3)
+	assert.Equal(t, stages[0].Name, "build")
+	assert.Equal(t, stages[1].Name, "test")
+	assert.Equal(t, len(stages[1].Jobs), 3)
+	assert.Equal(t, stages[2].Name, "deploy")
+}
@@ -0,0 +1,56 @@
+package gitlab
+
+import "time"
+
+var APIPaths = map[string]string{
+	"base":         "%s/%s",
+	"project":      "projects/%s",
+	"mergeRequest": "projects/%s/merge_requests/%d",
+	"approvals":    "projects/%s/merge_requests/%d/approvals",
+	"diffs":        "projects/%s/merge_requests/%d/diffs",
+	"issue":        "projects/%s/issues/%d",
+	"commit":       "projects/%s/repository/commits/%s",
+	"rawFile":      "projects/%s/repository/files/%s/raw",
+	"pipeline":     "projects/%s/pipelines/%d",
+	"pipelineJobs": "projects/%s/pipelines/%d/jobs",
+	"job":          "projects/%s/jobs/%d",
+}
+
+const (
+	// APIPrefix is the path of the REST API on self-managed GitLab
+	APIPrefix = "/api/v4"
+
+	// PageSize is the max number of items requested from list endpoints
+	PageSize = 100
+
+	// diffMaxPages is the max number of pages of merge request diffs read
+	diffMaxPages = 10
+	// diffTimeout is the timeout for reading a page of merge request diffs
+	diffTimeout = 10 * time.Second
+	// diffMaxBytes is the max size of a page of merge request diffs
+	diffMaxBytes = 5 << 20
+	// rawFileMaxBytes is the max size of files read for excerpts
+	rawFileMaxBytes = 1 << 20
+
+	// Merge request and issue states
+	StateOpened = "opened"
+	StateClosed = "closed"
+	StateMerged = "merged"
+	StateLocked = "locked"
+
+	// Pipeline and job statuses
+	StatusCreated            = "created"
+	StatusWaitingForResource = "waiting_for_resource"
+	StatusPreparing          = "preparing"
+	StatusPending            = "pending"
+	StatusRunning            = "running"
+	StatusSuccess            = "success"
+	StatusFailed             = "failed"
+	StatusCanceled           = "canceled"
+	StatusSkipped            = "skipped"
+	StatusManual             = "manual"
+	StatusScheduled          = "scheduled"
+
+	// VisibilityPublic is the visibility of public projects
+	VisibilityPublic = "public"
+)
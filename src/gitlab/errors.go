@@ -0,0 +1,67 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when a resource does not exist or the token
+	// is not allowed to read it.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when the token is missing, invalid or
+	// lacks permissions.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrResponseTooLarge is returned when a response is larger than the
+	// client is willing to read, such as a very large file.
+	ErrResponseTooLarge = errors.New("response is too large")
+)
+
+// Error is an error response from the GitLab API
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code and the error message from GitLab
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("HTTP request failed with unexpected status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("HTTP request failed with status code %d: %s", e.StatusCode, e.Message)
+}
+
+// Is makes errors.Is match Error against ErrNotFound and ErrUnauthorized
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	}
+
+	return false
+}
+
+// responseError returns an Error with the message from a GitLab error
+// response. The message is a string, or an object for validation errors.
+func responseError(data []byte, status int) error {
+	var res struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+	}
+
+	e := &Error{StatusCode: status}
+	if err := json.Unmarshal(data, &res); err == nil {
+		e.Message = res.Error
+		if err := json.Unmarshal(res.Message, &e.Message); err != nil && len(res.Message) > 0 {
+			e.Message = string(res.Message)
+		}
+	}
+
+	return e
+}
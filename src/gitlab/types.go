@@ -0,0 +1,287 @@
+package gitlab
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// User is a GitLab user
+type User struct {
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	WebURL    string `json:"web_url"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// Project is a GitLab project
+type Project struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	Visibility        string `json:"visibility"`
+	WebURL            string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+// Public returns true if anyone can read the project. Internal projects are
+// only visible to signed in users.
+func (p Project) Public() bool {
+	return p.Visibility == VisibilityPublic
+}
+
+// PipelineRef is the pipeline of a merge request or commit
+type PipelineRef struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	WebURL string `json:"web_url"`
+}
+
+// MergeRequest is a GitLab merge request
+type MergeRequest struct {
+	IID            int          `json:"iid"`
+	Title          string       `json:"title"`
+	Description    string       `json:"description"`
+	State          string       `json:"state"`
+	Draft          bool         `json:"draft"`
+	WorkInProgress bool         `json:"work_in_progress"`
+	WebURL         string       `json:"web_url"`
+	Author         User         `json:"author"`
+	CreatedAt      time.Time    `json:"created_at"`
+	SourceBranch   string       `json:"source_branch"`
+	TargetBranch   string       `json:"target_branch"`
+	SHA            string       `json:"sha"`
+	HeadPipeline   *PipelineRef `json:"head_pipeline"`
+	UserNotesCount int          `json:"user_notes_count"`
+	ChangesCount   string       `json:"changes_count"`
+}
+
+// FilesChanged returns the number of files changed by the merge request, and
+// true when GitLab stopped counting, e.g. at 1000+ files.
+func (mr MergeRequest) FilesChanged() (int, bool, error) {
+	n, err := strconv.Atoi(strings.TrimSuffix(mr.ChangesCount, "+"))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid changes count %q: %w", mr.ChangesCount, err)
+	}
+
+	return n, strings.HasSuffix(mr.ChangesCount, "+"), nil
+}
+
+// StateName returns the state of the merge request in the same form as
+// Bitbucket pull requests, e.g. OPEN, DRAFT, MERGED or CLOSED.
+func (mr MergeRequest) StateName() string {
+	if mr.State == StateOpened {
+		if mr.Draft || mr.WorkInProgress {
+			return "DRAFT"
+		}
+
+		return "OPEN"
+	}
+
+	return strings.ToUpper(mr.State)
+}
+
+// Approvals is the approval state of a merge request
+type Approvals struct {
+	Approved          bool `json:"approved"`
+	ApprovalsRequired int  `json:"approvals_required"`
+	ApprovalsLeft     int  `json:"approvals_left"`
+	ApprovedBy        []struct {
+		User User `json:"user"`
+	} `json:"approved_by"`
+}
+
+// ReviewedBy returns the users who approved the merge request in human
+// readable format.
+func (a Approvals) ReviewedBy() string {
+	var reviewers []string
+	for _, r := range a.ApprovedBy {
+		reviewers = append(reviewers, fmt.Sprintf("%s (APPROVED)", r.User.Username))
+	}
+
+	if len(reviewers) == 0 {
+		return "No reviews :sob:"
+	}
+
+	return strings.Join(reviewers, ", ")
+}
+
+// ReviewState returns the approval state of the merge request with the
+// number of approvals left, if any.
+func (a Approvals) ReviewState(showEmojis bool) string {
+	// Without approval rules, approved is true before anyone approves
+	if a.ApprovalsLeft == 0 && len(a.ApprovedBy) > 0 {
+		if showEmojis {
+			return ":white_check_mark: Approved"
+		}
+
+		return "Approved"
+	}
+
+	state := "Unapproved"
+	if a.ApprovalsLeft == 1 {
+		state = "Unapproved, 1 approval left"
+	} else if a.ApprovalsLeft > 1 {
+		state = fmt.Sprintf("Unapproved, %d approvals left", a.ApprovalsLeft)
+	}
+
+	if showEmojis {
+		return ":disappointed: " + state
+	}
+
+	return state
+}
+
+// Diff is the diff of a single file
+type Diff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	TooLarge    bool   `json:"too_large"`
+}
+
+// DiffStat is the number of files and lines changed. Truncated is true when
+// not all diffs could be read, so the lines are undercounted.
+type DiffStat struct {
+	Files     int
+	Additions int
+	Deletions int
+	Truncated bool
+}
+
+// NewDiffStat counts the files and lines changed in diffs. GitLab diffs have
+// no file headers, so every added or removed line inside the hunks is
+// counted, including lines such as "-- comment" or "++i".
+func NewDiffStat(diffs []Diff) DiffStat {
+	stat := DiffStat{Files: len(diffs)}
+
+	for _, d := range diffs {
+		if d.TooLarge {
+			stat.Truncated = true
+		}
+
+		hunk := false
+		for _, line := range strings.Split(d.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				hunk = true
+			case !hunk:
+			case strings.HasPrefix(line, "+"):
+				stat.Additions++
+			case strings.HasPrefix(line, "-"):
+				stat.Deletions++
+			}
+		}
+	}
+
+	return stat
+}
+
+// Milestone is an issue milestone
+type Milestone struct {
+	Title  string `json:"title"`
+	WebURL string `json:"web_url"`
+}
+
+// Issue is a GitLab issue
+type Issue struct {
+	IID            int        `json:"iid"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	State          string     `json:"state"`
+	WebURL         string     `json:"web_url"`
+	Author         User       `json:"author"`
+	Assignees      []User     `json:"assignees"`
+	Labels         []string   `json:"labels"`
+	Milestone      *Milestone `json:"milestone"`
+	DueDate        string     `json:"due_date"`
+	UserNotesCount int        `json:"user_notes_count"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Commit is a GitLab commit
+type Commit struct {
+	ID           string       `json:"id"`
+	ShortID      string       `json:"short_id"`
+	Title        string       `json:"title"`
+	Message      string       `json:"message"`
+	AuthorName   string       `json:"author_name"`
+	AuthorEmail  string       `json:"author_email"`
+	AuthoredDate time.Time    `json:"authored_date"`
+	WebURL       string       `json:"web_url"`
+	LastPipeline *PipelineRef `json:"last_pipeline"`
+	Stats        struct {
+		Additions int `json:"additions"`
+		Deletions int `json:"deletions"`
+		Total     int `json:"total"`
+	} `json:"stats"`
+}
+
+// Pipeline is a GitLab CI pipeline
+type Pipeline struct {
+	ID         int        `json:"id"`
+	IID        int        `json:"iid"`
+	Status     string     `json:"status"`
+	Source     string     `json:"source"`
+	Ref        string     `json:"ref"`
+	SHA        string     `json:"sha"`
+	WebURL     string     `json:"web_url"`
+	User       *User      `json:"user"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Duration   int        `json:"duration"`
+}
+
+// Job is a job of a GitLab CI pipeline
+type Job struct {
+	ID            int          `json:"id"`
+	Name          string       `json:"name"`
+	Stage         string       `json:"stage"`
+	Status        string       `json:"status"`
+	Ref           string       `json:"ref"`
+	WebURL        string       `json:"web_url"`
+	AllowFailure  bool         `json:"allow_failure"`
+	FailureReason string       `json:"failure_reason"`
+	Duration      float64      `json:"duration"`
+	User          *User        `json:"user"`
+	Pipeline      *PipelineRef `json:"pipeline"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Commit        *struct {
+		ShortID string `json:"short_id"`
+	} `json:"commit"`
+}
+
+// Stage is a stage of a pipeline with its jobs
+type Stage struct {
+	Name string
+	Jobs []Job
+}
+
+// Stages returns the jobs grouped by stage, in pipeline order. Jobs are
+// created stage by stage, so the stages are ordered by their first job ID.
+func Stages(jobs []Job) []Stage {
+	sorted := make([]Job, len(jobs))
+	copy(sorted, jobs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var stages []Stage
+	index := map[string]int{}
+
+	for _, job := range sorted {
+		n, ok := index[job.Stage]
+		if !ok {
+			n = len(stages)
+			index[job.Stage] = n
+			stages = append(stages, Stage{Name: job.Stage})
+		}
+
+		stages[n].Jobs = append(stages[n].Jobs, job)
+	}
+
+	return stages
+}
@@ -0,0 +1,55 @@
+package gitlab
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestMergeRequestStateName(t *testing.T) {
+	assert.Equal(t, MergeRequest{State: StateOpened}.StateName(), "OPEN")
+	assert.Equal(t, MergeRequest{State: StateOpened, Draft: true}.StateName(), "DRAFT")
+	assert.Equal(t, MergeRequest{State: StateOpened, WorkInProgress: true}.StateName(), "DRAFT")
+	assert.Equal(t, MergeRequest{State: StateMerged}.StateName(), "MERGED")
+	assert.Equal(t, MergeRequest{State: StateClosed}.StateName(), "CLOSED")
+}
+
+func TestApprovalsReviewState(t *testing.T) {
+	var a Approvals
+	assert.Equal(t, a.ReviewedBy(), "No reviews :sob:")
+	assert.Equal(t, a.ReviewState(true), ":disappointed: Unapproved")
+
+	a.ApprovalsLeft = 2
+	assert.Equal(t, a.ReviewState(false), "Unapproved, 2 approvals left")
+
+	a.ApprovalsLeft = 0
+	a.ApprovedBy = append(a.ApprovedBy, struct {
+		User User `json:"user"`
+	}{User: User{Username: "asmith"}})
+	assert.Equal(t, a.ReviewState(true), ":white_check_mark: Approved")
+}
+
+func TestProjectPublic(t *testing.T) {
+	assert.Equal(t, Project{Visibility: VisibilityPublic}.Public(), true)
+	assert.Equal(t, Project{Visibility: "internal"}.Public(), false)
+	assert.Equal(t, Project{Visibility: "private"}.Public(), false)
+}
+
+func TestNewDiffStat(t *testing.T) {
+	diffs := []Diff{
+		{NewPath: "schema.sql", Diff: "@@ -1,3 +1,2 @@\n-- old comment\n--- separator\n select 1;\n+++i;\n\\ No newline at end of file\n"},
+		{NewPath: "big.json", TooLarge: true},
+	}
+
+	assert.DeepEqual(t, NewDiffStat(diffs), DiffStat{Files: 2, Additions: 1, Deletions: 2, Truncated: true})
+}
+
+func TestMergeRequestFilesChanged(t *testing.T) {
+	files, more, err := MergeRequest{ChangesCount: "1000+"}.FilesChanged()
+	assert.NilError(t, err)
+	assert.Equal(t, files, 1000)
+	assert.Equal(t, more, true)
+
+	_, _, err = MergeRequest{}.FilesChanged()
+	assert.ErrorContains(t, err, "invalid changes count")
+}
@@ -0,0 +1,236 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client is a Jira Server client that is used for storing server
+// configuration, authentication and to run the actual Jira requests.
+type Client struct {
+	Server    string
+	PAT       string
+	timeout   int
+	useragent string
+
+	// sprintField caches the ID of the sprint custom field for clients
+	// created with NewClient
+	sprintField *sprintFieldCache
+}
+
+// sprintFieldCache is the ID of the sprint custom field once it is found
+type sprintFieldCache struct {
+	mu    sync.Mutex
+	found bool
+	id    string
+}
+
+// NewClient returns a Client that caches the ID of the sprint custom field,
+// so that it is only looked up once.
+func NewClient(server string, pat string) *Client {
+	return &Client{Server: server, PAT: pat, sprintField: &sprintFieldCache{}}
+}
+
+// Timeout returns the configured connection timeout for the HTTP client.
+func (c Client) Timeout() int {
+	if c.timeout == 0 {
+		return 2
+	}
+
+	return c.timeout
+}
+
+// Useragent returns the configured client useragent or a default one.
+func (c Client) Useragent() string {
+	if c.useragent == "" {
+		return "jira-go-sdk"
+	}
+
+	return c.useragent
+}
+
+// RawRequest does a API request and returns the content and the status code.
+// This is just a helper method used by other Client functions.
+// https://docs.atlassian.com/software/jira/docs/api/REST/8.20.0/
+func (c Client) RawRequest(ctx context.Context, url string) ([]byte, int, error) {
+	httpClient := http.Client{
+		Timeout: time.Second * time.Duration(c.Timeout()),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	req.Header.Set("User-Agent", c.Useragent())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.PAT))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return []byte{}, 0, err
+	}
+
+	return body, res.StatusCode, nil
+}
+
+// rawUrl returns a URL for a given API path and a list of path parameters.
+func (c Client) rawUrl(apis map[string]string, path string, args ...interface{}) string {
+	return fmt.Sprintf(apis["base"], c.Server, fmt.Sprintf(apis[path], args...))
+}
+
+// BrowseURL returns the web URL of an issue
+func (c Client) BrowseURL(key string) string {
+	return c.rawUrl(WebPaths, "browse", key)
+}
+
+// Issue returns a single Issue. Only the given fields are returned, or all
+// of them if none are given.
+func (c Client) Issue(ctx context.Context, key string, fields ...string) (Issue, error) {
+	var issue Issue
+
+	u := c.rawUrl(APIPaths, "issue", url.PathEscape(key))
+	if len(fields) > 0 {
+		u = fmt.Sprintf("%s?fields=%s", u, url.QueryEscape(strings.Join(fields, ",")))
+	}
+
+	data, status, err := c.RawRequest(ctx, u)
+	if err != nil {
+		return issue, err
+	}
+
+	if status != 200 {
+		return issue, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &issue); err != nil {
+		return issue, err
+	}
+
+	return issue, nil
+}
+
+// Fields returns all system and custom fields
+func (c Client) Fields(ctx context.Context) ([]Field, error) {
+	var fields []Field
+
+	data, status, err := c.RawRequest(ctx, c.rawUrl(APIPaths, "fields"))
+	if err != nil {
+		return fields, err
+	}
+
+	if status != 200 {
+		return fields, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fields, err
+	}
+
+	return fields, nil
+}
+
+// SprintField returns the ID of the sprint custom field, or an empty string
+// if Jira Software is not installed. The ID is cached by clients created with
+// NewClient. Failed lookups are not cached, so they are retried.
+func (c Client) SprintField(ctx context.Context) (string, error) {
+	if c.sprintField == nil {
+		return c.findSprintField(ctx)
+	}
+
+	c.sprintField.mu.Lock()
+	defer c.sprintField.mu.Unlock()
+
+	if !c.sprintField.found {
+		id, err := c.findSprintField(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		c.sprintField.found = true
+		c.sprintField.id = id
+	}
+
+	return c.sprintField.id, nil
+}
+
+// findSprintField looks up the ID of the sprint custom field in the list of
+// all fields.
+func (c Client) findSprintField(ctx context.Context) (string, error) {
+	fields, err := c.Fields(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range fields {
+		if f.Schema.Custom == SprintFieldSchema {
+			return f.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Issues returns the issues with the given keys, in the same order. Keys of
+// issues that do not exist or can not be seen are left out.
+func (c Client) Issues(ctx context.Context, keys []string, fields ...string) ([]Issue, error) {
+	var res SearchResult
+
+	if len(keys) == 0 {
+		return []Issue{}, nil
+	}
+
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = fmt.Sprintf("%q", key)
+	}
+
+	// Jira fails the whole search on unknown keys unless it is told to warn
+	q := url.Values{}
+	q.Set("jql", fmt.Sprintf("key in (%s)", strings.Join(quoted, ",")))
+	q.Set("validateQuery", "warn")
+	q.Set("maxResults", fmt.Sprint(len(keys)))
+	if len(fields) > 0 {
+		q.Set("fields", strings.Join(fields, ","))
+	}
+
+	data, status, err := c.RawRequest(ctx, fmt.Sprintf("%s?%s", c.rawUrl(APIPaths, "search"), q.Encode()))
+	if err != nil {
+		return []Issue{}, err
+	}
+
+	if status != 200 {
+		return []Issue{}, responseError(data, status)
+	}
+
+	if err := json.Unmarshal(data, &res); err != nil {
+		return []Issue{}, err
+	}
+
+	byKey := make(map[string]Issue, len(res.Issues))
+	for _, issue := range res.Issues {
+		byKey[issue.Key] = issue
+	}
+
+	issues := []Issue{}
+	for _, key := range keys {
+		if issue, ok := byKey[key]; ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,132 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const (
+	jiraServer = "jira.corp.org"
+	jiraPAT    = "my-token"
+	jiraIssue  = "MYPROJ-123"
+
+	testdataDir = "../../testdata"
+)
+
+func TestJiraClientIssue(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "jira-issue-123.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], jiraServer, fmt.Sprintf(APIPaths["issue"], jiraIssue))
+
+	// Set up mock Jira Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(APIPaths["base"], jiraServer, fmt.Sprintf(APIPaths["issue"], "MYPROJ-404")),
+		httpmock.NewStringResponder(404, `{"errorMessages":["Issue Does Not Exist"],"errors":{}}`))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(APIPaths["base"], jiraServer, fmt.Sprintf(APIPaths["issue"], "MYPROJ-401")),
+		httpmock.NewStringResponder(401, ``))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(APIPaths["base"], jiraServer, fmt.Sprintf(APIPaths["issue"], "MYPROJ-400")),
+		httpmock.NewStringResponder(400, `{"errorMessages":[],"errors":{"summary":"bad summary","assignee":"bad assignee"}}`))
+
+	client := Client{Server: jiraServer, PAT: jiraPAT}
+
+	t.Run("should return issue", func(t *testing.T) {
+		issue, err := client.Issue(context.Background(), jiraIssue, "summary", "status")
+		assert.NilError(t, err)
+		assert.Equal(t, "MYPROJ-123", issue.Key)
+		assert.Equal(t, "Add support for unfurling Jira issues", issue.Fields.Summary)
+		assert.Equal(t, "In Progress", issue.Fields.Status.Name)
+		assert.Equal(t, "User A", issue.Fields.Assignee.DisplayName)
+		assert.Equal(t, 2, len(issue.Fields.FixVersions))
+	})
+
+	t.Run("should return ErrNotFound for missing issues", func(t *testing.T) {
+		_, err := client.Issue(context.Background(), "MYPROJ-404")
+		assert.Assert(t, errors.Is(err, ErrNotFound))
+		assert.ErrorContains(t, err, "Issue Does Not Exist")
+	})
+
+	t.Run("should return ErrUnauthorized for invalid tokens", func(t *testing.T) {
+		_, err := client.Issue(context.Background(), "MYPROJ-401")
+		assert.Assert(t, errors.Is(err, ErrUnauthorized))
+		assert.Assert(t, !errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("should list field errors in a stable order", func(t *testing.T) {
+		_, err := client.Issue(context.Background(), "MYPROJ-400")
+		assert.Error(t, err, "HTTP request failed with status code 400: assignee: bad assignee; summary: bad summary")
+	})
+}
+
+func TestJiraClientSprintField(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "jira-fields.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], jiraServer, APIPaths["fields"])
+
+	// Set up mock Jira Server
+	httpmock.RegisterResponder("GET", reqPath,
+		httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: jiraServer, PAT: jiraPAT}
+
+	t.Run("should find the sprint field", func(t *testing.T) {
+		field, err := client.SprintField(context.Background())
+		assert.NilError(t, err)
+		assert.Equal(t, "customfield_10100", field)
+	})
+
+	t.Run("should only look up the sprint field once", func(t *testing.T) {
+		httpmock.ZeroCallCounters()
+		client := NewClient(jiraServer, jiraPAT)
+
+		for i := 0; i < 3; i++ {
+			field, err := client.SprintField(context.Background())
+			assert.NilError(t, err)
+			assert.Equal(t, "customfield_10100", field)
+		}
+
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["GET "+reqPath])
+	})
+}
+
+func TestJiraClientIssues(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	jsonFilePath := fmt.Sprintf("%s/%s", testdataDir, "jira-search.json")
+	reqPath := fmt.Sprintf(APIPaths["base"], jiraServer, APIPaths["search"])
+
+	// Set up mock Jira Server
+	httpmock.RegisterResponderWithQuery("GET", reqPath, map[string]string{
+		"jql":           `key in ("PROJ-1396","UTF-8","PROJ-1400")`,
+		"validateQuery": "warn",
+		"maxResults":    "3",
+		"fields":        "status",
+	}, httpmock.NewStringResponder(200, httpmock.File(jsonFilePath).String()))
+
+	client := Client{Server: jiraServer, PAT: jiraPAT}
+
+	t.Run("should return issues in the order of the keys", func(t *testing.T) {
+		issues, err := client.Issues(context.Background(), []string{"PROJ-1396", "UTF-8", "PROJ-1400"}, "status")
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(issues))
+		assert.Equal(t, "PROJ-1396", issues[0].Key)
+		assert.Equal(t, "In Review", issues[0].Fields.Status.Name)
+		assert.Equal(t, "PROJ-1400", issues[1].Key)
+	})
+
+	t.Run("should not search without keys", func(t *testing.T) {
+		issues, err := client.Issues(context.Background(), []string{})
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(issues))
+	})
+}
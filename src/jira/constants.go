@@ -0,0 +1,19 @@
+package jira
+
+var APIPaths = map[string]string{
+	"base":   "https://%s/rest/api/2/%s",
+	"issue":  "issue/%s",
+	"fields": "field",
+	"search": "search",
+}
+
+var WebPaths = map[string]string{
+	"base":   "https://%s/%s",
+	"browse": "browse/%s",
+}
+
+const (
+	// SprintFieldSchema is the custom field type of the Jira Software sprint
+	// field. The field ID differs between Jira instances.
+	SprintFieldSchema = "com.pyxis.greenhopper.jira:gh-sprint"
+)
@@ -0,0 +1,74 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when an issue does not exist or the user is not
+	// allowed to see it.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when the token is missing, invalid or
+	// lacks permissions.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// Error is an error response from the Jira API
+type Error struct {
+	StatusCode int
+	Messages   []string
+}
+
+// Error returns the status code and the error messages from Jira
+func (e *Error) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("HTTP request failed with unexpected status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("HTTP request failed with status code %d: %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// Is makes errors.Is match Error against ErrNotFound and ErrUnauthorized
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	}
+
+	return false
+}
+
+// responseError returns an Error with the messages from a Jira error response
+func responseError(data []byte, status int) error {
+	var res struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+
+	e := &Error{StatusCode: status}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return e
+	}
+
+	e.Messages = append(e.Messages, res.ErrorMessages...)
+
+	// Sort the fields so that the message is the same every time
+	fields := make([]string, 0, len(res.Errors))
+	for field := range res.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		e.Messages = append(e.Messages, fmt.Sprintf("%s: %s", field, res.Errors[field]))
+	}
+
+	return e
+}
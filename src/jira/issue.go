@@ -0,0 +1,254 @@
+package jira
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Issue is a Jira issue
+type Issue struct {
+	ID     string      `json:"id"`
+	Key    string      `json:"key"`
+	Self   string      `json:"self"`
+	Fields IssueFields `json:"fields"`
+
+	// Raw holds all the fields of the issue, including custom fields
+	Raw map[string]json.RawMessage `json:"-"`
+}
+
+// IssueFields are the system fields of an Issue
+type IssueFields struct {
+	Summary     string     `json:"summary"`
+	Description string     `json:"description"`
+	Status      *Status    `json:"status"`
+	Priority    *Priority  `json:"priority"`
+	Assignee    *User      `json:"assignee"`
+	Reporter    *User      `json:"reporter"`
+	IssueType   *IssueType `json:"issuetype"`
+	FixVersions []Version  `json:"fixVersions"`
+	Created     string     `json:"created"`
+	Updated     string     `json:"updated"`
+}
+
+// Status is the workflow status of an Issue
+type Status struct {
+	Name           string         `json:"name"`
+	IconURL        string         `json:"iconUrl"`
+	StatusCategory StatusCategory `json:"statusCategory"`
+}
+
+// StatusCategory groups statuses into to do, in progress and done
+type StatusCategory struct {
+	Key       string `json:"key"`
+	Name      string `json:"name"`
+	ColorName string `json:"colorName"`
+}
+
+// Priority is the priority of an Issue
+type Priority struct {
+	Name    string `json:"name"`
+	IconURL string `json:"iconUrl"`
+}
+
+// IssueType is the type of an Issue, such as Bug or Story
+type IssueType struct {
+	Name    string `json:"name"`
+	IconURL string `json:"iconUrl"`
+	Subtask bool   `json:"subtask"`
+}
+
+// User is a Jira user
+type User struct {
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Active       bool   `json:"active"`
+}
+
+// Version is a project version an Issue is fixed in
+type Version struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Released bool   `json:"released"`
+	Archived bool   `json:"archived"`
+}
+
+// Sprint is a Jira Software sprint
+type Sprint struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// SearchResult is the result of an issue search
+type SearchResult struct {
+	StartAt    int     `json:"startAt"`
+	MaxResults int     `json:"maxResults"`
+	Total      int     `json:"total"`
+	Issues     []Issue `json:"issues"`
+}
+
+// Field is a system or custom field
+type Field struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	Schema struct {
+		Type   string `json:"type"`
+		Custom string `json:"custom"`
+	} `json:"schema"`
+}
+
+// UnmarshalJSON decodes an Issue and keeps the raw fields for custom fields
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	type issue Issue
+	var raw struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+
+	if err := json.Unmarshal(data, (*issue)(i)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	i.Raw = raw.Fields
+	return nil
+}
+
+// IssueKeyPattern matches issue keys such as MYPROJ-123 in text
+var IssueKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+
+// IsIssueKey returns true if s is a single issue key, such as MYPROJ-123
+func IsIssueKey(s string) bool {
+	loc := IssueKeyPattern.FindStringIndex(s)
+
+	return loc != nil && loc[0] == 0 && loc[1] == len(s)
+}
+
+// IssueKeys returns the unique issue keys found in texts, in the order they
+// are found.
+func IssueKeys(texts ...string) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+
+	for _, text := range texts {
+		for _, key := range IssueKeyPattern.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// sprintString matches the sprints Jira Server returns as strings, like
+// com.atlassian.greenhopper.service.sprint.Sprint@1a2b[id=1,state=ACTIVE,name=Sprint 1,...]
+var (
+	sprintString    = regexp.MustCompile(`\[(.*)\]$`)
+	sprintStringKey = regexp.MustCompile(`(?:^|,)([a-zA-Z]+)=`)
+)
+
+// Sprints returns the sprints of an Issue from the sprint custom field
+func (i Issue) Sprints(field string) []Sprint {
+	raw, ok := i.Raw[field]
+	if !ok {
+		return nil
+	}
+
+	var objects []Sprint
+	if err := json.Unmarshal(raw, &objects); err == nil {
+		return objects
+	}
+
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+
+	var sprints []Sprint
+	for _, v := range values {
+		m := sprintString.FindStringSubmatch(v)
+		if m == nil {
+			continue
+		}
+
+		// Values run until the next key, as sprint names may contain commas
+		var s Sprint
+		keys := sprintStringKey.FindAllStringSubmatchIndex(m[1], -1)
+		for k, key := range keys {
+			end := len(m[1])
+			if k+1 < len(keys) {
+				end = keys[k+1][0]
+			}
+
+			value := m[1][key[1]:end]
+			switch m[1][key[2]:key[3]] {
+			case "id":
+				s.ID, _ = strconv.Atoi(value)
+			case "name":
+				s.Name = value
+			case "state":
+				s.State = value
+			}
+		}
+		sprints = append(sprints, s)
+	}
+
+	return sprints
+}
+
+// Value returns a field as text. It works for strings, numbers and the
+// objects and lists of objects Jira uses for most other fields.
+func (i Issue) Value(field string) string {
+	raw, ok := i.Raw[field]
+	if !ok {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+
+	return value(v)
+}
+
+// value returns a decoded JSON value as text
+func value(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64, bool:
+		b, _ := json.Marshal(t)
+		return string(b)
+	case []interface{}:
+		values := []string{}
+		for _, e := range t {
+			if s := value(e); s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ", ")
+	case map[string]interface{}:
+		for _, key := range []string{"displayName", "name", "value", "key"} {
+			if s, ok := t[key].(string); ok {
+				return s
+			}
+		}
+	}
+
+	return ""
+}
+
+// String returns the name of the version
+func (v Version) String() string {
+	return v.Name
+}
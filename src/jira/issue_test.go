@@ -0,0 +1,80 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func readIssue(t *testing.T) Issue {
+	data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", testdataDir, "jira-issue-123.json"))
+	assert.NilError(t, err)
+
+	var issue Issue
+	assert.NilError(t, json.Unmarshal(data, &issue))
+
+	return issue
+}
+
+func TestIssueSprints(t *testing.T) {
+	issue := readIssue(t)
+
+	t.Run("should parse Jira Server sprint strings", func(t *testing.T) {
+		sprints := issue.Sprints("customfield_10100")
+		assert.Equal(t, 2, len(sprints))
+		assert.Equal(t, Sprint{ID: 41, Name: "Sprint 41", State: "CLOSED"}, sprints[0])
+		assert.Equal(t, Sprint{ID: 42, Name: "Sprint 42, the big one", State: "ACTIVE"}, sprints[1])
+	})
+
+	t.Run("should parse sprint objects", func(t *testing.T) {
+		issue := Issue{Raw: map[string]json.RawMessage{
+			"customfield_10020": json.RawMessage(`[{"id":7,"name":"Sprint 7","state":"active"}]`),
+		}}
+		assert.Equal(t, "Sprint 7", issue.Sprints("customfield_10020")[0].Name)
+	})
+
+	t.Run("should return no sprints for missing fields", func(t *testing.T) {
+		assert.Equal(t, 0, len(issue.Sprints("customfield_99999")))
+	})
+}
+
+func TestIssueValue(t *testing.T) {
+	issue := readIssue(t)
+
+	t.Run("should return option fields", func(t *testing.T) {
+		assert.Equal(t, "Platform", issue.Value("customfield_10200"))
+	})
+
+	t.Run("should return user fields", func(t *testing.T) {
+		assert.Equal(t, "User B", issue.Value("reporter"))
+	})
+
+	t.Run("should return list fields", func(t *testing.T) {
+		assert.Equal(t, "1.2.0, 1.3.0", issue.Value("fixVersions"))
+	})
+
+	t.Run("should return nothing for missing fields", func(t *testing.T) {
+		assert.Equal(t, "", issue.Value("environment"))
+	})
+}
+
+func TestIssueKeys(t *testing.T) {
+	t.Run("should find unique keys in order", func(t *testing.T) {
+		keys := IssueKeys("PROJ-12 Fix the build", "feature/OPS-7-deploy", "Also fixes PROJ-12 and MY_PROJ2-3")
+		assert.DeepEqual(t, []string{"PROJ-12", "OPS-7", "MY_PROJ2-3"}, keys)
+	})
+
+	t.Run("should not find lowercase or zero keys", func(t *testing.T) {
+		assert.Equal(t, 0, len(IssueKeys("proj-12 PROJ-0 PROJ-")))
+	})
+}
+
+func TestIsIssueKey(t *testing.T) {
+	assert.Equal(t, true, IsIssueKey("MY_PROJ2-7"))
+	assert.Equal(t, false, IsIssueKey("PROJ-0"))
+	assert.Equal(t, false, IsIssueKey("PROJ-12/foo"))
+	assert.Equal(t, false, IsIssueKey("x PROJ-12"))
+}
@@ -0,0 +1,45 @@
+package redact
+
+import (
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formatter is a logrus.Formatter that redacts secrets from the formatted
+// log entries of another Formatter.
+type Formatter struct {
+	Formatter logrus.Formatter
+	Redactor  *Redactor
+}
+
+// Format formats an entry and redacts secrets from it
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b, err := f.Formatter.Format(entry)
+	if err != nil {
+		return b, err
+	}
+
+	return []byte(f.Redactor.String(string(b))), nil
+}
+
+// writer redacts secrets from everything written to it
+type writer struct {
+	w io.Writer
+	r *Redactor
+}
+
+// Writer returns an io.Writer that redacts secrets before writing to w. Each
+// call to Write is redacted on its own, so it suits line based loggers.
+func Writer(w io.Writer, r *Redactor) io.Writer {
+	return &writer{w: w, r: r}
+}
+
+// Write writes p to the underlying writer with secrets redacted
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, w.r.String(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
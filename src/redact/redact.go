@@ -0,0 +1,116 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Replacement is what secrets are replaced with
+const Replacement = "[REDACTED]"
+
+// secretGroup is the name of the capture group holding the secret in a
+// pattern. Patterns without it have their whole match replaced.
+const secretGroup = "secret"
+
+// Detector is a named pattern matching a kind of secret
+type Detector struct {
+	Name    string
+	Pattern string
+}
+
+// Detectors are the built-in patterns for well known secrets. Private keys
+// come first so that the other patterns do not match inside them.
+var Detectors = []Detector{
+	{"private_key", `(?s)-----BEGIN[A-Z ]* PRIVATE KEY-----(?:.*?-----END[A-Z ]* PRIVATE KEY-----|.*)`},
+	{"aws_access_key", `(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}`},
+	{"aws_secret_key", `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`},
+	{"slack_token", `\bxox[abposr]-[A-Za-z0-9-]{10,}`},
+	{"slack_app_token", `\bxapp-\d-[A-Za-z0-9-]{10,}`},
+	{"slack_webhook", `https://hooks\.slack\.com/services/[A-Za-z0-9/_-]+`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`},
+	{"url_password", `\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:(?P<secret>[^\s/@]+)@`},
+	{"password", `(?i)(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token)["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&,;]+)`},
+}
+
+// Default is a Redactor with the built-in detectors only
+var Default = mustNew(nil)
+
+// Redactor replaces secrets in text
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New returns a Redactor with the built-in detectors and the given extra
+// patterns.
+func New(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, d := range Detectors {
+		r.patterns = append(r.patterns, regexp.MustCompile(d.Pattern))
+	}
+
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// mustNew is like New but panics on invalid patterns
+func mustNew(patterns []string) *Redactor {
+	r, err := New(patterns)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// String returns s with all secrets replaced
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	for _, re := range r.patterns {
+		s = replace(re, s)
+	}
+
+	return s
+}
+
+// replace replaces the secret group of every match of re in s, or the whole
+// match if the pattern has no secret group.
+func replace(re *regexp.Regexp, s string) string {
+	group := re.SubexpIndex(secretGroup)
+
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+
+		out = append(out, s[last:start]...)
+		out = append(out, Replacement...)
+		last = end
+	}
+	out = append(out, s[last:]...)
+
+	return string(out)
+}
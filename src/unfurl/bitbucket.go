@@ -7,17 +7,27 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
 	"github.com/slack-go/slack"
 )
 
 const (
 	BitbucketIcon               = "https://avatars.slack-edge.com/2021-06-20/2187759053413_fb4aad0a769aaadbdc62_72.png"
 	BitbucketURLPullRequestType = "pull_request"
-	BitbucketURLRepoType        = "repo"
-	BitbucketURLSourceCodeType  = "source_code"
-	BitbucketURLUnknownType     = "unknown"
+
+	BitbucketURLPullRequestCommentType = "pull_request_comment"
+	BitbucketURLPullRequestDiffType    = "pull_request_diff"
+	BitbucketURLRepoType               = "repo"
+	BitbucketURLCommitType             = "commit"
+	BitbucketURLRefType                = "ref"
+	BitbucketURLCompareType            = "compare"
+	BitbucketURLProjectType            = "project"
+	BitbucketURLUserType               = "user"
+	BitbucketURLSourceCodeType         = "source_code"
+	BitbucketURLUnknownType            = "unknown"
 )
 
 // bitbucketLinkType returns the type of Bitbucket link and the matches
@@ -34,16 +44,68 @@ func (u *Unfurl) bitbucketLinkType(url *url.URL) (string, []string) {
 		),
 	)
 
+	var isCommit = regexp.MustCompile(
+		"^/" + fmt.Sprintf(bitbucket.APIPaths["commit"], "([^/]+)", "([^/]+)", "([0-9a-f]{7,40})"),
+	)
+
 	var isRepo = regexp.MustCompile(
 		"^/" + fmt.Sprintf(bitbucket.APIPaths["repo"], "([^/]+)", "([^/]+)"),
 	)
 
+	var isCompare = regexp.MustCompile(
+		"^/" + fmt.Sprintf(bitbucket.APIPaths["repo"], "([^/]+)", "([^/]+)") + "/compare(/|$)",
+	)
+
+	var isProject = regexp.MustCompile(
+		"^/" + fmt.Sprintf(bitbucket.APIPaths["project"], "([^/]+)") + "/?$",
+	)
+
+	var isUser = regexp.MustCompile(
+		"^/" + fmt.Sprintf(bitbucket.APIPaths["user"], "([^/]+)") + "/?$",
+	)
+
+	var isSHA = regexp.MustCompile("^[0-9a-f]{7,40}$")
+
 	if isPullRequest.MatchString(url.Path) {
-		return BitbucketURLPullRequestType, isPullRequest.FindStringSubmatch(url.Path)
+		matches := isPullRequest.FindStringSubmatch(url.Path)
+
+		// Deep links to a comment or to a file in the diff
+		if commentID := url.Query().Get("commentId"); commentID != "" {
+			return BitbucketURLPullRequestCommentType, append(matches, commentID)
+		}
+		if strings.HasSuffix(url.Path, "/diff") && url.Fragment != "" {
+			return BitbucketURLPullRequestDiffType, append(matches, url.Fragment)
+		}
+
+		return BitbucketURLPullRequestType, matches
 	} else if isSourceCode.MatchString(url.Path) {
 		return BitbucketURLSourceCodeType, isSourceCode.FindStringSubmatch(url.Path)
+	} else if isCommit.MatchString(url.Path) {
+		return BitbucketURLCommitType, isCommit.FindStringSubmatch(url.Path)
+	} else if isCompare.MatchString(url.Path) && url.Query().Get("sourceBranch") != "" {
+		matches := isCompare.FindStringSubmatch(url.Path)[:3]
+		return BitbucketURLCompareType, append(matches, url.Query().Get("sourceBranch"), url.Query().Get("targetBranch"))
 	} else if isRepo.MatchString(url.Path) {
-		return BitbucketURLRepoType, isRepo.FindStringSubmatch(url.Path)
+		matches := isRepo.FindStringSubmatch(url.Path)
+
+		// Links to a branch, tag or commit such as browse?at=refs/heads/foo
+		// or commits?until=refs/tags/v1.2.0
+		ref := url.Query().Get("at")
+		if ref == "" {
+			ref = url.Query().Get("until")
+		}
+		if isSHA.MatchString(ref) {
+			return BitbucketURLCommitType, append(matches, ref)
+		}
+		if ref != "" {
+			return BitbucketURLRefType, append(matches, ref)
+		}
+
+		return BitbucketURLRepoType, matches
+	} else if isProject.MatchString(url.Path) {
+		return BitbucketURLProjectType, isProject.FindStringSubmatch(url.Path)
+	} else if isUser.MatchString(url.Path) {
+		return BitbucketURLUserType, isUser.FindStringSubmatch(url.Path)
 	}
 
 	return BitbucketURLUnknownType, []string{}
@@ -55,7 +117,6 @@ func (u *Unfurl) bitbucketLink(URL *url.URL) (slack.Attachment, error) {
 
 	// Parse what type of link this is
 	linkType, matches := u.bitbucketLinkType(URL)
-	fmt.Printf("linkType=%s matches=%s", linkType, matches)
 	switch linkType {
 	case BitbucketURLPullRequestType:
 		proj := matches[1]
@@ -65,19 +126,83 @@ func (u *Unfurl) bitbucketLink(URL *url.URL) (slack.Attachment, error) {
 			return attachement, err
 		}
 
-		fmt.Printf("project=%s, repo=%s, prid=%d", proj, repo, prid)
-		return u.bitbucketPRLink(proj, repo, prid)
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketPRLink(proj, repo, prid)
+		})
+
+	case BitbucketURLPullRequestCommentType, BitbucketURLPullRequestDiffType:
+		proj := matches[1]
+		repo := matches[2]
+		prid, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return attachement, err
+		}
+
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			if linkType == BitbucketURLPullRequestDiffType {
+				return u.bitbucketPRDiffLink(URL, proj, repo, prid, matches[4])
+			}
+
+			commentID, err := strconv.Atoi(matches[4])
+			if err != nil {
+				return slack.Attachment{}, err
+			}
+
+			return u.bitbucketPRCommentLink(URL, proj, repo, prid, commentID)
+		})
 
 	case BitbucketURLSourceCodeType:
 		// @TODO
-		fmt.Println("BitbucketURLSourceCodeType is not implemented for BitbucketLink()")
+
+	case BitbucketURLCommitType:
+		proj := matches[1]
+		repo := matches[2]
+		sha := matches[3]
+
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketCommitLink(proj, repo, sha)
+		})
+
+	case BitbucketURLRefType:
+		proj := matches[1]
+		repo := matches[2]
+		ref := matches[3]
+
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketRefLink(proj, repo, ref)
+		})
+
+	case BitbucketURLCompareType:
+		proj := matches[1]
+		repo := matches[2]
+
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketCompareLink(proj, repo, matches[3], matches[4])
+		})
 
 	case BitbucketURLRepoType:
 		proj := matches[1]
 		repo := matches[2]
 
-		fmt.Printf("project=%s, repo=%s", proj, repo)
-		return u.bitbucketRepoLink(proj, repo)
+		return u.bitbucketVisibleLink(proj, repo, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketRepoLink(proj, repo)
+		})
+
+	case BitbucketURLProjectType:
+		key := matches[1]
+
+		return u.bitbucketRestrictedLink(func() (bool, error) {
+			return u.bitbucketProjectVisible(key)
+		}, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketProjectLink(key)
+		})
+
+	case BitbucketURLUserType:
+		slug := matches[1]
+
+		return u.bitbucketRestrictedLink(u.bitbucketUserVisible, func(u *Unfurl) (slack.Attachment, error) {
+			return u.bitbucketUserLink(slug)
+		})
 
 	default:
 		return slack.Attachment{}, errors.New("bitbucket link not supported")
@@ -86,6 +211,39 @@ func (u *Unfurl) bitbucketLink(URL *url.URL) (slack.Attachment, error) {
 	return attachement, nil
 }
 
+// bitbucketVisibleLink returns the attachment from unfurl, restricted by the
+// visibility policy if the repo is not visible to everyone in the channel.
+func (u *Unfurl) bitbucketVisibleLink(proj string, repo string, unfurl func(u *Unfurl) (slack.Attachment, error)) (slack.Attachment, error) {
+	return u.bitbucketRestrictedLink(func() (bool, error) {
+		return u.bitbucketVisible(proj, repo)
+	}, unfurl)
+}
+
+// bitbucketRestrictedLink returns the attachment from unfurl, restricted by
+// the visibility policy unless visible reports the link as visible.
+func (u *Unfurl) bitbucketRestrictedLink(visible func() (bool, error), unfurl func(u *Unfurl) (slack.Attachment, error)) (slack.Attachment, error) {
+	ok, err := visible()
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	if ok {
+		return unfurl(u)
+	}
+
+	// The title is read with the bot credentials as the user who shared the
+	// link may not be able to read it.
+	var attachement slack.Attachment
+	if u.visibilityPolicy() == VisibilityPolicyTitle {
+		attachement, err = unfurl(u.asBot())
+		if err != nil {
+			return attachement, err
+		}
+	}
+
+	return u.restrict(attachement)
+}
+
 // bitbucketPRLink returns a Slack Attachment for Bitbucket Pull Request links
 func (u *Unfurl) bitbucketPRLink(proj string, repo string, prid int) (slack.Attachment, error) {
 	attachement := slack.Attachment{}
@@ -102,18 +260,45 @@ func (u *Unfurl) bitbucketPRLink(proj string, repo string, prid int) (slack.Atta
 		return attachement, err
 	}
 
+	attachement = u.bitbucketPRAttachment(pr, st)
+	if pr.IsOpen {
+		attachement.Fields = append(attachement.Fields, u.bitbucketPRMergeFields(proj, repo, pr)...)
+	}
+
+	if u.Config != nil && u.Config.BitbucketDiffStats {
+		diffFields, warning, err := u.bitbucketPRDiffStat(proj, repo, prid)
+		if err != nil {
+			u.Logger.WithError(err).WithField("pr", pr.RepoSlug()).Warn("Failed to get Pull Request diff statistics")
+		}
+		attachement.Fields = append(attachement.Fields, diffFields...)
+		attachement.Pretext = warning
+	}
+
+	if field, ok := u.bitbucketInsightsField(proj, repo, pr.FromRef.LatestCommit); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(pr.Title, pr.FromRef.DisplayID)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+	attachement.CallbackID = BitbucketPullRequestCallbackID
+	attachement.Actions = bitbucketPRActions(proj, repo, pr)
+
+	return attachement, nil
+}
+
+// bitbucketPRAttachment returns a Slack Attachment with the state, build
+// status and reviews of a Pull Request, for Bitbucket Server and Cloud.
+func (u *Unfurl) bitbucketPRAttachment(pr bitbucket.PullRequest, st bitbucket.StatusList) slack.Attachment {
+	attachement := slack.Attachment{}
+
 	fields := []slack.AttachmentField{
 		{
 			Title: "PR State",
 			Value: pr.State,
 			Short: true,
 		},
-		{
-			Title: "Build Status",
-			Value: st.State(),
-			Short: true,
-		},
 	}
+	fields = append(fields, u.bitbucketBuildFields(st)...)
 	if pr.ApprovalStatus(false) != "" {
 		fields = append(
 			fields,
@@ -132,15 +317,52 @@ func (u *Unfurl) bitbucketPRLink(proj string, repo string, prid int) (slack.Atta
 	attachement.Ts = json.Number(fmt.Sprint(pr.CreatedDate))
 	attachement.FooterIcon = BitbucketIcon
 	attachement.Footer = "Bitbucket"
-	attachement.AuthorID = fmt.Sprintf("%d", pr.Author.User.ID)
+	if pr.Author.User.ID != 0 {
+		attachement.AuthorID = fmt.Sprintf("%d", pr.Author.User.ID)
+	}
 	attachement.AuthorName = pr.Author.User.DisplayName
-	attachement.AuthorLink = pr.Author.User.Links.Self[0].Href
+	if len(pr.Author.User.Links.Self) > 0 {
+		attachement.AuthorLink = pr.Author.User.Links.Self[0].Href
+	}
 	attachement.Title = fmt.Sprintf("#%d %s", pr.ID, pr.Title)
-	attachement.TitleLink = pr.Links.Self[0].Href
+	if len(pr.Links.Self) > 0 {
+		attachement.TitleLink = pr.Links.Self[0].Href
+	}
 	attachement.Text = pr.Description
 	attachement.Fields = fields
 
-	return attachement, nil
+	return attachement
+}
+
+// bitbucketPRMergeFields returns the task and merge check fields of an open
+// Pull Request
+func (u *Unfurl) bitbucketPRMergeFields(proj string, repo string, pr bitbucket.PullRequest) []slack.AttachmentField {
+	var fields []slack.AttachmentField
+
+	if tasks := pr.Tasks(); tasks != "" {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Tasks",
+			Value: tasks,
+			Short: true,
+		})
+	}
+
+	ms, err := u.Bitbucket.MergeStatus(proj, repo, pr.ID)
+	if err != nil {
+		u.Logger.WithError(err).WithField("pr", pr.RepoSlug()).Warn("Failed to get Pull Request merge status")
+		return fields
+	}
+
+	merge := ":white_check_mark: " + ms.String()
+	if !ms.CanMerge {
+		merge = ":no_entry: " + ms.String()
+	}
+
+	return append(fields, slack.AttachmentField{
+		Title: "Merge Status",
+		Value: merge,
+		Short: true,
+	})
 }
 
 // bitbucketRepoLink returns a Slack Attachment for a Bitbucket Repo links
@@ -153,33 +375,177 @@ func (u *Unfurl) bitbucketRepoLink(project string, repo string) (slack.Attachmen
 		return attachement, err
 	}
 
-	// Get repo commits
-	co, err := u.Bitbucket.Commits(project, repo, bitbucket.CommitOptions{})
+	// Get the latest commit, empty repos have none
+	co, err := u.Bitbucket.Commits(project, repo, bitbucket.CommitOptions{Limit: 1})
 	if err != nil {
 		return attachement, err
 	}
 
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = r.Name
+	attachement.TitleLink = fmt.Sprintf("https://%s/%s/browse", u.Bitbucket.Server, fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo))
+	if len(r.Links.Self) > 0 {
+		attachement.TitleLink = r.Links.Self[0].Href
+	}
+	attachement.Text = r.Description
+
+	if len(co.Values) == 0 {
+		attachement.Fields = []slack.AttachmentField{
+			{
+				Title: "Last Commit",
+				Value: "No commits yet",
+				Short: true,
+			},
+		}
+		attachement.Fields = append(attachement.Fields, u.bitbucketRepoFields(r)...)
+
+		return attachement, nil
+	}
+
 	// Get build status for latest commit
 	st, err := u.Bitbucket.Status(co.Values[0].ID)
 	if err != nil {
 		return attachement, err
 	}
 
-	attachement.Title = r.Name
-	attachement.TitleLink = r.Links.Self[0].Href
-	attachement.Text = r.Description
 	attachement.Fields = []slack.AttachmentField{
 		{
 			Title: "Last Commit",
 			Value: co.Values[0].String(),
 			Short: true,
 		},
+	}
+	attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(st)...)
+
+	if def, err := u.Bitbucket.DefaultBranch(project, repo); err != nil {
+		u.Logger.WithError(err).WithField("repo", project+"/"+repo).Warn("Failed to get default branch")
+	} else {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Default Branch",
+			Value: def.DisplayID,
+			Short: true,
+		})
+	}
+
+	if count, err := u.Bitbucket.OpenPullRequestCount(project, repo); err != nil {
+		u.Logger.WithError(err).WithField("repo", project+"/"+repo).Warn("Failed to count open Pull Requests")
+	} else {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Open PRs",
+			Value: formatNumber(count),
+			Short: true,
+		})
+	}
+
+	attachement.Fields = append(attachement.Fields, u.bitbucketRepoFields(r)...)
+	if field, ok := u.jiraIssuesField(commitIssueKeys(co.Values[0])); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketRepoFields returns the visibility, fork origin and clone URL
+// fields of a repo
+func (u *Unfurl) bitbucketRepoFields(r bitbucket.Repository) []slack.AttachmentField {
+	visibility := ":lock: Private"
+	if r.Public {
+		visibility = "Public"
+	}
+
+	fields := []slack.AttachmentField{
 		{
-			Title: "Build Status",
-			Value: st.State(),
+			Title: "Visibility",
+			Value: visibility,
 			Short: true,
 		},
 	}
 
+	if r.Origin != nil {
+		origin := fmt.Sprintf("%s/%s", r.Origin.Project.Key, r.Origin.Slug)
+		if len(r.Origin.Links.Self) > 0 {
+			origin = fmt.Sprintf("<%s|%s>", r.Origin.Links.Self[0].Href, origin)
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: "Forked From",
+			Value: origin,
+			Short: true,
+		})
+	}
+
+	if len(r.Links.Clone) > 0 {
+		lines := make([]string, len(r.Links.Clone))
+		for i, l := range r.Links.Clone {
+			lines[i] = fmt.Sprintf("%s: `%s`", l.Name, l.Href)
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: "Clone",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	return fields
+}
+
+// bitbucketCommitLink returns a Slack Attachment for Bitbucket commit links
+func (u *Unfurl) bitbucketCommitLink(project string, repo string, sha string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	commit, err := u.Bitbucket.Commit(project, repo, sha)
+	if err != nil {
+		return attachement, err
+	}
+
+	st, err := u.Bitbucket.Status(commit.ID)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement = u.bitbucketCommitAttachment(commit, st, fmt.Sprintf("https://%s/%s", u.Bitbucket.Server,
+		fmt.Sprintf(bitbucket.APIPaths["commit"], project, repo, commit.ID)))
+	if field, ok := u.bitbucketInsightsField(project, repo, commit.ID); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+	if field, ok := u.jiraIssuesField(commitIssueKeys(commit)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
 	return attachement, nil
 }
+
+// bitbucketCommitAttachment returns a Slack Attachment with the message and
+// build status of a commit, for Bitbucket Server and Cloud.
+func (u *Unfurl) bitbucketCommitAttachment(commit bitbucket.Commit, st bitbucket.StatusList, link string) slack.Attachment {
+	var attachement slack.Attachment
+
+	lines := strings.SplitN(commit.Message, "\n", 2)
+
+	attachement.Ts = json.Number(fmt.Sprint(commit.AuthorTimestamp / 1000))
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.AuthorName = commit.Author.DisplayName
+	attachement.Title = lines[0]
+	attachement.TitleLink = link
+	if len(lines) > 1 {
+		attachement.Text = strings.TrimSpace(lines[1])
+	}
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Commit",
+			Value: commit.DisplayID,
+			Short: true,
+		},
+	}
+	attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(st)...)
+
+	return attachement
+}
+
+// commitIssueKeys returns the Jira issue keys Bitbucket found for a commit
+// and the ones in the commit message.
+func commitIssueKeys(commit bitbucket.Commit) []string {
+	return jira.IssueKeys(append(commit.JIRAIssueKeys(), commit.Message)...)
+}
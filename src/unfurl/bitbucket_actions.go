@@ -0,0 +1,159 @@
+package unfurl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// BitbucketPullRequestCallbackID is the callback ID for Pull Request actions.
+	BitbucketPullRequestCallbackID = "bitbucket_pull_request"
+
+	bitbucketPRApprove   = "approve"
+	bitbucketPRNeedsWork = "needs_work"
+	bitbucketPRMerge     = "merge"
+)
+
+// bitbucketPRActions returns the attachment actions for reviewing and merging
+// an open Pull Request.
+func bitbucketPRActions(proj string, repo string, pr bitbucket.PullRequest) []slack.AttachmentAction {
+	if !pr.IsOpen {
+		return nil
+	}
+
+	value := encodeActionValue(map[string]string{
+		"project": proj,
+		"repo":    repo,
+		"id":      fmt.Sprint(pr.ID),
+		"version": fmt.Sprint(pr.Version),
+	})
+
+	return []slack.AttachmentAction{
+		{
+			Name:  bitbucketPRApprove,
+			Text:  ":white_check_mark: Approve",
+			Type:  "button",
+			Style: "primary",
+			Value: value,
+		},
+		{
+			Name:  bitbucketPRNeedsWork,
+			Text:  ":warning: Needs Work",
+			Type:  "button",
+			Value: value,
+		},
+		{
+			Name:  bitbucketPRMerge,
+			Text:  ":twisted_rightwards_arrows: Merge",
+			Type:  "button",
+			Style: "danger",
+			Value: value,
+			Confirm: &slack.ConfirmationField{
+				Title:       fmt.Sprintf("Merge #%d", pr.ID),
+				Text:        fmt.Sprintf("Merge _%s_ into `%s`?", pr.Title, pr.ToRef.DisplayID),
+				OkText:      "Merge",
+				DismissText: "Cancel",
+			},
+		},
+	}
+}
+
+// bitbucketUserClient returns a Bitbucket client acting as the given Slack
+// user, if the user has linked their Bitbucket account.
+func (u *Unfurl) bitbucketUserClient(slackUserID string) (*bitbucket.Client, bool) {
+	if u.Accounts == nil || u.Bitbucket == nil {
+		return nil, false
+	}
+
+	credential, ok, err := u.Accounts.Bitbucket(slackUserID)
+	if err != nil {
+		u.Logger.WithError(err).WithField("slackUser", slackUserID).Warn("Failed to get linked Bitbucket account")
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	return &bitbucket.Client{Server: u.Bitbucket.Server, PAT: credential.Token}, true
+}
+
+// bitbucketPRAction handles a click on one of the Pull Request buttons.
+func (u *Unfurl) bitbucketPRAction(cb slack.InteractionCallback, name string, value actionValue) error {
+	client, ok := u.bitbucketUserClient(cb.User.ID)
+	if !ok {
+		u.reply(cb, cb.Channel.ID, ":link: Link your Bitbucket account to review and merge pull requests from Slack.")
+		return nil
+	}
+
+	proj := value.Params["project"]
+	repo := value.Params["repo"]
+	prid, err := strconv.Atoi(value.Params["id"])
+	if err != nil {
+		return err
+	}
+
+	logger := u.Logger.WithFields(logrus.Fields{
+		"slackUser": cb.User.ID,
+		"project":   proj,
+		"repo":      repo,
+		"prid":      prid,
+		"action":    name,
+	})
+
+	var message string
+	switch name {
+	case bitbucketPRApprove, bitbucketPRNeedsWork:
+		status := bitbucket.PullRequestReviewStatusApproved
+		message = fmt.Sprintf(":white_check_mark: Approved #%d", prid)
+		if name == bitbucketPRNeedsWork {
+			status = bitbucket.PullRequestReviewStatusNeedsWork
+			message = fmt.Sprintf(":warning: Marked #%d as needs work", prid)
+		}
+
+		var slug string
+		slug, err = client.CurrentUserSlug()
+		if err == nil {
+			err = client.SetReviewStatus(proj, repo, prid, slug, status)
+		}
+
+	case bitbucketPRMerge:
+		var ms bitbucket.MergeStatus
+		ms, err = client.MergeStatus(proj, repo, prid)
+		if err == nil && !ms.CanMerge {
+			logger.WithField("vetoes", ms.Vetoes).Info("Pull Request merge is blocked")
+			u.reply(cb, cb.Channel.ID, fmt.Sprintf(":no_entry: Cannot merge #%d. %s", prid, ms))
+			return nil
+		}
+
+		if err == nil {
+			var version int
+			version, err = strconv.Atoi(value.Params["version"])
+			if err == nil {
+				_, err = client.Merge(proj, repo, prid, version)
+			}
+		}
+		message = fmt.Sprintf(":twisted_rightwards_arrows: Merged #%d", prid)
+
+	default:
+		return fmt.Errorf("unsupported bitbucket pull request action %s", name)
+	}
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to act on Pull Request")
+		u.reply(cb, cb.Channel.ID, fmt.Sprintf(":x: Failed to %s #%d: %s", name, prid, err))
+		return err
+	}
+
+	logger.Info("Acted on Pull Request")
+	u.reply(cb, cb.Channel.ID, message)
+
+	if err := u.refresh(cb, cb.Channel.ID, cb.MessageTs, value); err != nil {
+		logger.WithError(err).Warn("Failed to refresh unfurl")
+	}
+
+	return nil
+}
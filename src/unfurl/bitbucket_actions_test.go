@@ -0,0 +1,123 @@
+package unfurl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/accounts"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketPRActions(t *testing.T) {
+	t.Run("should offer actions for open pull requests", func(t *testing.T) {
+		pr := bitbucket.PullRequest{ID: 297, Version: 38, IsOpen: true}
+		actions := bitbucketPRActions(project, repo, pr)
+
+		assert.Equal(t, 3, len(actions))
+		assert.Equal(t, bitbucketPRApprove, actions[0].Name)
+		assert.Equal(t, bitbucketPRNeedsWork, actions[1].Name)
+		assert.Equal(t, bitbucketPRMerge, actions[2].Name)
+
+		value, err := decodeActionValue(actions[0].Value)
+		assert.NilError(t, err)
+		assert.Equal(t, "38", value.Params["version"])
+	})
+
+	t.Run("should not offer actions for merged pull requests", func(t *testing.T) {
+		pr := bitbucket.PullRequest{ID: 297, IsClosed: true}
+		assert.Equal(t, 0, len(bitbucketPRActions(project, repo, pr)))
+	})
+}
+
+func TestBitbucketPRAction(t *testing.T) {
+	value := encodeActionValue(map[string]string{
+		"project": project,
+		"repo":    repo,
+		"id":      pr,
+		"version": "38",
+	})
+
+	callback := func(name string, user string) slack.InteractionCallback {
+		return slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: BitbucketPullRequestCallbackID,
+			User:       slack.User{ID: user},
+			ActionCallback: slack.ActionCallbacks{
+				AttachmentActions: []*slack.AttachmentAction{{Name: name, Value: value}},
+			},
+		}
+	}
+
+	unfurl := func() (Unfurl, *fakeSlack) {
+		s := &fakeSlack{}
+		return Unfurl{
+			Logger:    logrus.StandardLogger(),
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Slack:     s,
+			Config:    &utils.Config{},
+			Accounts: testAccounts(t, accounts.Account{
+				SlackUserID: "U123",
+				Bitbucket:   &accounts.Credential{User: "User B", Token: "user-token"},
+			}),
+		}, s
+	}
+
+	mergeAPI := fmt.Sprintf(bitbucket.APIPaths["base"], server,
+		fmt.Sprintf(bitbucket.APIPaths["merge"], project, repo, pr))
+
+	t.Run("should ask users without a linked account to link it", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		u, s := unfurl()
+		err := u.Interaction(callback(bitbucketPRApprove, "U999"))
+
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(s.ephemerals))
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("should approve as the linked user", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.PluginPaths["base"], server, bitbucket.PluginPaths["whoami"]),
+			httpmock.NewStringResponder(200, "User B"))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, bitbucket.APIPaths["users"]),
+			httpmock.NewStringResponder(200, `{"values":[{"name":"User Bee","slug":"user_bee"},{"name":"User B","slug":"user_b"}]}`))
+
+		participantAPI := fmt.Sprintf(bitbucket.APIPaths["base"], server,
+			fmt.Sprintf(bitbucket.APIPaths["participant"], project, repo, pr, "user_b"))
+		httpmock.RegisterResponder("PUT", participantAPI,
+			httpmock.NewStringResponder(200, "{}"))
+
+		u, s := unfurl()
+		err := u.Interaction(callback(bitbucketPRApprove, "U123"))
+
+		assert.NilError(t, err)
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["PUT "+participantAPI])
+		assert.Equal(t, ":white_check_mark: Approved #297", s.ephemerals[0])
+	})
+
+	t.Run("should show vetoes when merge is blocked", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("GET", mergeAPI,
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-merge-297.json")))
+
+		u, s := unfurl()
+		err := u.Interaction(callback(bitbucketPRMerge, "U123"))
+
+		assert.NilError(t, err)
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["POST "+mergeAPI])
+		assert.Assert(t, len(s.ephemerals) == 1)
+		assert.Equal(t, ":no_entry: Cannot merge #297. Blocked: Not all required reviewers have approved yet, "+
+			"Not all required builds are successful yet", s.ephemerals[0])
+	})
+}
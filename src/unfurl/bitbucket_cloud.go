@@ -0,0 +1,183 @@
+package unfurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+var (
+	bitbucketCloudPullRequest = regexp.MustCompile("^/([^/]+)/([^/]+)/pull-requests/([0-9]+)")
+	bitbucketCloudCommit      = regexp.MustCompile("^/([^/]+)/([^/]+)/commits?/([0-9a-f]{7,40})")
+	bitbucketCloudRepo        = regexp.MustCompile("^/([^/]+)/([^/]+)(/src(/.*)?)?/?$")
+)
+
+// bitbucketCloudLinkType returns the type of Bitbucket Cloud link and the
+// matches. The first two matches are the workspace and the repo slug.
+func bitbucketCloudLinkType(url *url.URL) (string, []string) {
+	if m := bitbucketCloudPullRequest.FindStringSubmatch(url.Path); m != nil {
+		return BitbucketURLPullRequestType, m
+	}
+
+	if m := bitbucketCloudCommit.FindStringSubmatch(url.Path); m != nil {
+		return BitbucketURLCommitType, m
+	}
+
+	if m := bitbucketCloudRepo.FindStringSubmatch(url.Path); m != nil {
+		return BitbucketURLRepoType, m
+	}
+
+	return BitbucketURLUnknownType, []string{}
+}
+
+// bitbucketCloudLink returns a Slack Attachment for bitbucket.org links
+func (u *Unfurl) bitbucketCloudLink(URL *url.URL) (slack.Attachment, error) {
+	linkType, matches := bitbucketCloudLinkType(URL)
+
+	switch linkType {
+	case BitbucketURLPullRequestType:
+		prid, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return slack.Attachment{}, err
+		}
+
+		return u.bitbucketCloudVisibleLink(matches[1], matches[2], func() (slack.Attachment, error) {
+			return u.bitbucketCloudPRLink(matches[1], matches[2], prid)
+		})
+
+	case BitbucketURLCommitType:
+		return u.bitbucketCloudVisibleLink(matches[1], matches[2], func() (slack.Attachment, error) {
+			return u.bitbucketCloudCommitLink(matches[1], matches[2], matches[3])
+		})
+
+	case BitbucketURLRepoType:
+		return u.bitbucketCloudVisibleLink(matches[1], matches[2], func() (slack.Attachment, error) {
+			return u.bitbucketCloudRepoLink(matches[1], matches[2])
+		})
+	}
+
+	return slack.Attachment{}, errors.New("bitbucket cloud link not supported")
+}
+
+// bitbucketCloudVisibleLink returns the attachment from unfurl, restricted by
+// the visibility policy if the repo is private.
+func (u *Unfurl) bitbucketCloudVisibleLink(workspace string, repo string, unfurl func() (slack.Attachment, error)) (slack.Attachment, error) {
+	return u.publicOnlyLink(func() (bool, error) {
+		r, err := u.BitbucketCloud.Repository(workspace, repo)
+
+		return r.Public, err
+	}, unfurl)
+}
+
+// bitbucketCloudPRLink returns a Slack Attachment for Bitbucket Cloud Pull
+// Request links
+func (u *Unfurl) bitbucketCloudPRLink(workspace string, repo string, prid int) (slack.Attachment, error) {
+	pr, err := u.BitbucketCloud.PullRequest(workspace, repo, prid)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	st, err := u.BitbucketCloud.CommitStatus(workspace, repo, pr.FromRef.LatestCommit)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	attachement := u.bitbucketPRAttachment(pr, st)
+	if tasks := pr.Tasks(); pr.IsOpen && tasks != "" {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Tasks",
+			Value: tasks,
+			Short: true,
+		})
+	}
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(pr.Title, pr.FromRef.DisplayID)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketCloudCommitLink returns a Slack Attachment for Bitbucket Cloud
+// commit links
+func (u *Unfurl) bitbucketCloudCommitLink(workspace string, repo string, sha string) (slack.Attachment, error) {
+	commit, err := u.BitbucketCloud.Commit(workspace, repo, sha)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	st, err := u.BitbucketCloud.CommitStatus(workspace, repo, commit.ID)
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	attachement := u.bitbucketCommitAttachment(commit, st,
+		fmt.Sprintf("https://bitbucket.org/%s/%s/commits/%s", workspace, repo, commit.ID))
+	if field, ok := u.jiraIssuesField(commitIssueKeys(commit)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketCloudRepoLink returns a Slack Attachment for Bitbucket Cloud repo
+// links
+func (u *Unfurl) bitbucketCloudRepoLink(workspace string, repo string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	r, err := u.BitbucketCloud.Repository(workspace, repo)
+	if err != nil {
+		return attachement, err
+	}
+
+	co, err := u.BitbucketCloud.Commits(workspace, repo, bitbucket.CommitOptions{Limit: 1})
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = r.Name
+	if len(r.Links.Self) > 0 {
+		attachement.TitleLink = r.Links.Self[0].Href
+	}
+	attachement.Text = r.Description
+
+	if len(co.Values) == 0 {
+		attachement.Fields = []slack.AttachmentField{
+			{
+				Title: "Last Commit",
+				Value: "No commits yet",
+				Short: true,
+			},
+		}
+		attachement.Fields = append(attachement.Fields, u.bitbucketRepoFields(r)...)
+
+		return attachement, nil
+	}
+
+	st, err := u.BitbucketCloud.CommitStatus(workspace, repo, co.Values[0].ID)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Last Commit",
+			Value: co.Values[0].String(),
+			Short: true,
+		},
+	}
+	attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(st)...)
+	attachement.Fields = append(attachement.Fields, u.bitbucketRepoFields(r)...)
+	if field, ok := u.jiraIssuesField(commitIssueKeys(co.Values[0])); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
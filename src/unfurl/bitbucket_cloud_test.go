@@ -0,0 +1,124 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket/cloud"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const (
+	cloudWorkspace = "my-workspace"
+	cloudSHA       = "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+)
+
+var cu = Unfurl{
+	BitbucketCloud: cloud.Client{Username: "jane", AppPassword: "my-app-password"},
+}
+
+// cloudPath returns the Bitbucket Cloud API URL for a path in the test repo
+func cloudPath(path string) string {
+	if path == "" {
+		return fmt.Sprintf("https://%s/2.0/repositories/%s/%s", cloud.DefaultServer, cloudWorkspace, repo)
+	}
+
+	return fmt.Sprintf("https://%s/2.0/repositories/%s/%s/%s", cloud.DefaultServer, cloudWorkspace, repo, path)
+}
+
+func TestBitbucketCloudLinkType(t *testing.T) {
+	typeLinks := map[string][]string{
+		BitbucketURLPullRequestType: {
+			"/my-workspace/my-repo/pull-requests/42",
+			"/my-workspace/my-repo/pull-requests/42/diff",
+		},
+		BitbucketURLCommitType: {
+			"/my-workspace/my-repo/commits/c2646bb9a628c4fd935e6e0e7bca2da01afecde7",
+			"/my-workspace/my-repo/commit/c2646bb",
+		},
+		BitbucketURLRepoType: {
+			"/my-workspace/my-repo",
+			"/my-workspace/my-repo/",
+			"/my-workspace/my-repo/src/master/README.md",
+		},
+		BitbucketURLUnknownType: {
+			"/my-workspace",
+			"/my-workspace/my-repo/pipelines",
+		},
+	}
+
+	for expected, links := range typeLinks {
+		for _, link := range links {
+			URL, err := url.Parse("https://bitbucket.org" + link)
+			assert.NilError(t, err)
+
+			actual, _ := bitbucketCloudLinkType(URL)
+			assert.Equal(t, actual, expected, link)
+		}
+	}
+}
+
+func TestBitbucketCloudPRLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", cloudPath("pullrequests/42"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-pull-request.json").String()))
+	httpmock.RegisterResponder("GET", cloudPath("commit/1a2b3c4d5e6f/statuses"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-statuses.json").String()))
+
+	URL, _ := url.Parse("https://bitbucket.org/my-workspace/my-repo/pull-requests/42")
+	attachement, err := cu.bitbucketCloudLink(URL)
+	assert.NilError(t, err)
+
+	assert.Equal(t, attachement.Title, "#42 ACE-123 Add cloud support")
+	assert.Equal(t, attachement.TitleLink, "https://bitbucket.org/my-workspace/my-repo/pull-requests/42")
+	assert.Equal(t, attachement.AuthorName, "Jane Doe")
+	assert.Equal(t, attachement.AuthorID, "")
+	assert.DeepEqual(t, fieldTitles(attachement.Fields), []string{"PR State", "Build Status", "Reviewers", "Review Status", "Tasks"})
+	assert.Equal(t, attachement.Fields[1].Value, "CANCELLED")
+	assert.Equal(t, attachement.Fields[4].Value, "2 open tasks")
+}
+
+func TestBitbucketCloudCommitLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", cloudPath("commit/"+cloudSHA),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-commit.json").String()))
+	httpmock.RegisterResponder("GET", cloudPath("commit/"+cloudSHA+"/statuses"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-statuses.json").String()))
+
+	URL, _ := url.Parse("https://bitbucket.org/my-workspace/my-repo/commits/" + cloudSHA)
+	attachement, err := cu.bitbucketCloudLink(URL)
+	assert.NilError(t, err)
+
+	assert.Equal(t, attachement.Title, "ACE-456 Map cloud commits")
+	assert.Equal(t, attachement.Text, "Commits are mapped onto the server model.")
+	assert.Equal(t, attachement.TitleLink, "https://bitbucket.org/my-workspace/my-repo/commits/"+cloudSHA)
+	assert.DeepEqual(t, fieldTitles(attachement.Fields), []string{"Commit", "Build Status"})
+	assert.Equal(t, attachement.Fields[0].Value, "c2646bb9a62")
+}
+
+func TestBitbucketCloudRepoLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", cloudPath(""),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-repo.json").String()))
+	httpmock.RegisterResponder("GET", cloudPath("commits"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-commits.json").String()))
+	httpmock.RegisterResponder("GET", cloudPath("commit/"+cloudSHA+"/statuses"),
+		httpmock.NewStringResponder(200, httpmock.File(testdataDir+"/bitbucket-cloud-statuses.json").String()))
+
+	URL, _ := url.Parse("https://bitbucket.org/my-workspace/my-repo/src/master/")
+	attachement, err := cu.bitbucketCloudLink(URL)
+	assert.NilError(t, err)
+
+	assert.Equal(t, attachement.Title, "My Repo")
+	assert.Equal(t, attachement.TitleLink, "https://bitbucket.org/my-workspace/my-repo")
+	assert.DeepEqual(t, fieldTitles(attachement.Fields), []string{"Last Commit", "Build Status", "Visibility", "Clone"})
+	assert.Equal(t, attachement.Fields[2].Value, ":lock: Private")
+}
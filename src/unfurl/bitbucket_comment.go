@@ -0,0 +1,219 @@
+package unfurl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketDiffContext is the number of lines shown around an anchored line
+const bitbucketDiffContext = 3
+
+// bitbucketDiffPrefixes are the line prefixes for diff segment types
+var bitbucketDiffPrefixes = map[string]string{
+	bitbucket.DiffSegmentAdded:   "+",
+	bitbucket.DiffSegmentRemoved: "-",
+}
+
+// bitbucketPRCommentLink returns a Slack Attachment for links to a comment on
+// a Bitbucket Pull Request
+func (u *Unfurl) bitbucketPRCommentLink(URL *url.URL, proj string, repo string, prid int, commentID int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	comment, err := u.Bitbucket.PullRequestComment(proj, repo, prid, commentID)
+	if err != nil {
+		return attachement, err
+	}
+
+	kind := "Comment"
+	if comment.IsTask() {
+		kind = "Task"
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(comment.CreatedDate / 1000))
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.AuthorName = comment.Author.DisplayName
+	attachement.Title = fmt.Sprintf("%s on %s/%s#%d", kind, proj, repo, prid)
+	attachement.TitleLink = URL.String()
+	attachement.Text = comment.Text
+
+	if comment.Anchor != nil && comment.Anchor.Path != "" {
+		file := fmt.Sprintf("`%s`", comment.Anchor.Path)
+		if comment.Anchor.Line > 0 {
+			file = fmt.Sprintf("%s line %d", file, comment.Anchor.Line)
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "File",
+			Value: file,
+		})
+	}
+
+	state := "Open"
+	if comment.IsResolved() {
+		state = "Resolved"
+	}
+
+	attachement.Fields = append(attachement.Fields,
+		slack.AttachmentField{
+			Title: "State",
+			Value: state,
+			Short: true,
+		},
+		slack.AttachmentField{
+			Title: "Replies",
+			Value: fmt.Sprint(comment.Replies()),
+			Short: true,
+		},
+	)
+
+	if tasks := comment.OpenTasks(); tasks > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Open Tasks",
+			Value: fmt.Sprint(tasks),
+			Short: true,
+		})
+	}
+
+	return attachement, nil
+}
+
+// bitbucketPRDiffLink returns a Slack Attachment for links to a file in the
+// diff of a Bitbucket Pull Request, with the lines around the anchored line.
+func (u *Unfurl) bitbucketPRDiffLink(URL *url.URL, proj string, repo string, prid int, fragment string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	path, line, source := bitbucketDiffAnchor(fragment)
+	if path == "" {
+		return attachement, fmt.Errorf("bitbucket diff link has no file")
+	}
+
+	diff, err := u.Bitbucket.PullRequestFileDiff(proj, repo, prid, path, bitbucketDiffContext)
+	if err != nil {
+		return attachement, err
+	}
+
+	title := fmt.Sprintf("%s in %s/%s#%d", path, proj, repo, prid)
+	if line > 0 {
+		title = fmt.Sprintf("%s:%d in %s/%s#%d", path, line, proj, repo, prid)
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = title
+	attachement.TitleLink = URL.String()
+
+	if excerpt := bitbucketDiffExcerpt(diff, line, source); excerpt != "" {
+		attachement.Text = fmt.Sprintf("```\n%s\n```", excerpt)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketDiffAnchor returns the file path and line from the fragment of a
+// diff link, such as path/file.go?t=42. Lines are in the new file (t=) unless
+// source is true (f=).
+func bitbucketDiffAnchor(fragment string) (path string, line int, source bool) {
+	parts := strings.SplitN(fragment, "?", 2)
+	path = parts[0]
+
+	if len(parts) == 1 {
+		return path, 0, false
+	}
+
+	q, err := url.ParseQuery(parts[1])
+	if err != nil {
+		return path, 0, false
+	}
+
+	if t, err := strconv.Atoi(q.Get("t")); err == nil {
+		return path, t, false
+	}
+
+	if f, err := strconv.Atoi(q.Get("f")); err == nil {
+		return path, f, true
+	}
+
+	return path, 0, false
+}
+
+// bitbucketDiffLine is a line of a hunk with its prefix
+type bitbucketDiffLine struct {
+	text        string
+	kind        string
+	source      int
+	destination int
+}
+
+// bitbucketDiffExcerpt returns the hunk with the given line, or the first
+// hunk, limited to the lines around it.
+func bitbucketDiffExcerpt(diff bitbucket.Diff, line int, source bool) string {
+	for _, f := range diff.Diffs {
+		var first []bitbucketDiffLine
+		var header string
+
+		for _, h := range f.Hunks {
+			lines := []bitbucketDiffLine{}
+			for _, s := range h.Segments {
+				prefix, ok := bitbucketDiffPrefixes[s.Type]
+				if !ok {
+					prefix = " "
+				}
+
+				for _, l := range s.Lines {
+					lines = append(lines, bitbucketDiffLine{text: prefix + l.Line, kind: s.Type, source: l.Source, destination: l.Destination})
+				}
+			}
+
+			hunkHeader := fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.SourceLine, h.SourceSpan, h.DestinationLine, h.DestinationSpan)
+			if first == nil {
+				first, header = lines, hunkHeader
+			}
+
+			for i, l := range lines {
+				// Removed lines are only in the source, and added lines only
+				// in the destination.
+				anchor, skip := l.destination, bitbucket.DiffSegmentRemoved
+				if source {
+					anchor, skip = l.source, bitbucket.DiffSegmentAdded
+				}
+				if l.kind == skip {
+					continue
+				}
+
+				if line > 0 && anchor == line {
+					return hunkHeader + "\n" + joinDiffLines(lines, i-bitbucketDiffContext, i+bitbucketDiffContext+1)
+				}
+			}
+		}
+
+		if first != nil {
+			return header + "\n" + joinDiffLines(first, 0, 2*bitbucketDiffContext+1)
+		}
+	}
+
+	return ""
+}
+
+// joinDiffLines returns the lines from start to end, within bounds
+func joinDiffLines(lines []bitbucketDiffLine, start int, end int) string {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	texts := make([]string, 0, end-start)
+	for _, l := range lines[start:end] {
+		texts = append(texts, l.text)
+	}
+
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,101 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketPRCommentLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["comment"], project, repo, pr, "456")),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-comment-456.json")))
+
+	URL, _ := url.Parse(fmt.Sprintf("https://%s/projects/%s/repos/%s/pull-requests/%s/overview?commentId=456", server, project, repo, pr))
+
+	a, err := u.bitbucketLink(URL)
+	assert.NilError(t, err)
+
+	assert.Equal(t, "Comment on MY-PROJ/my-repo#297", a.Title)
+	assert.Equal(t, URL.String(), a.TitleLink)
+	assert.Equal(t, "User A", a.AuthorName)
+	assert.Equal(t, "Should this not handle the empty repo case?", a.Text)
+	assert.DeepEqual(t, []string{"File", "State", "Replies", "Open Tasks"}, fieldTitles(a.Fields))
+	assert.Equal(t, "`src/unfurl/bitbucket.go` line 42", a.Fields[0].Value)
+	assert.Equal(t, "Open", a.Fields[1].Value)
+	assert.Equal(t, "3", a.Fields[2].Value)
+	assert.Equal(t, "1", a.Fields[3].Value)
+}
+
+func TestBitbucketPRDiffLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["fileDiff"], project, repo, pr, "src/unfurl/bitbucket.go")),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-file-diff-297.json")))
+
+	t.Run("should show the lines around the anchor", func(t *testing.T) {
+		URL, _ := url.Parse(fmt.Sprintf("https://%s/projects/%s/repos/%s/pull-requests/%s/diff#src/unfurl/bitbucket.go?t=42", server, project, repo, pr))
+
+		a, err := u.bitbucketLink(URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "src/unfurl/bitbucket.go:42 in MY-PROJ/my-repo#297", a.Title)
+		assert.Equal(t, "```\n@@ -36,7 +37,9 @@\n-\t\treturn attachement, nil\n+\t\treturn attachement, err\n+\t}\n+\tif len(co.Values) == 0 {\n+\t\treturn attachement, nil\n \t}\n \n```", a.Text)
+	})
+
+	t.Run("should show the lines around a source anchor", func(t *testing.T) {
+		URL, _ := url.Parse(fmt.Sprintf("https://%s/projects/%s/repos/%s/pull-requests/%s/diff#src/unfurl/bitbucket.go?f=39", server, project, repo, pr))
+
+		a, err := u.bitbucketLink(URL)
+		assert.NilError(t, err)
+		assert.Equal(t, "```\n@@ -36,7 +37,9 @@\n \t// Get repo commits\n \tco, err := u.Bitbucket.Commits(project, repo, bitbucket.CommitOptions{})\n \tif err != nil {\n-\t\treturn attachement, nil\n+\t\treturn attachement, err\n+\t}\n+\tif len(co.Values) == 0 {\n```", a.Text)
+	})
+
+	t.Run("should show the first hunk without a line", func(t *testing.T) {
+		URL, _ := url.Parse(fmt.Sprintf("https://%s/projects/%s/repos/%s/pull-requests/%s/diff#src/unfurl/bitbucket.go", server, project, repo, pr))
+
+		a, err := u.bitbucketLink(URL)
+		assert.NilError(t, err)
+		assert.Equal(t, "src/unfurl/bitbucket.go in MY-PROJ/my-repo#297", a.Title)
+		assert.Equal(t, "```\n@@ -10,7 +10,8 @@\n const (\n \tBitbucketIcon = \"icon\"\n \tBitbucketURLPullRequestType = \"pull_request\"\n+\tBitbucketURLCommitType = \"commit\"\n \tBitbucketURLRepoType = \"repo\"\n \tBitbucketURLUnknownType = \"unknown\"\n )\n```", a.Text)
+	})
+}
+
+func TestBitbucketDiffAnchor(t *testing.T) {
+	anchors := map[string]struct {
+		path   string
+		line   int
+		source bool
+	}{
+		"src/main.go?t=42": {"src/main.go", 42, false},
+		"src/main.go?f=7":  {"src/main.go", 7, true},
+		"src/main.go":      {"src/main.go", 0, false},
+		"src/main.go?t=x":  {"src/main.go", 0, false},
+	}
+
+	for fragment, expected := range anchors {
+		path, line, source := bitbucketDiffAnchor(fragment)
+		assert.Equal(t, expected.path, path)
+		assert.Equal(t, expected.line, line)
+		assert.Equal(t, expected.source, source)
+	}
+}
+
+// fieldTitles returns the titles of attachment fields
+func fieldTitles(fields []slack.AttachmentField) []string {
+	titles := make([]string, len(fields))
+	for i, f := range fields {
+		titles[i] = f.Title
+	}
+
+	return titles
+}
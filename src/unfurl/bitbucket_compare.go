@@ -0,0 +1,134 @@
+package unfurl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketCompareCommits is the default number of commits listed in compare
+// unfurls
+const bitbucketCompareCommits = 10
+
+// bitbucketCompareLink returns a Slack Attachment for Bitbucket links
+// comparing the source ref to the target ref. The default branch is used when
+// target is empty.
+func (u *Unfurl) bitbucketCompareLink(project string, repo string, source string, target string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	if target == "" {
+		def, err := u.Bitbucket.DefaultBranch(project, repo)
+		if err != nil {
+			return attachement, err
+		}
+		target = def.ID
+	}
+
+	max := bitbucketCompareCommits
+	if u.Config != nil {
+		max = u.Config.BitbucketCompareCommits
+	}
+
+	limit := max
+	if limit < 1 {
+		limit = 1
+	}
+
+	commits, err := u.Bitbucket.Commits(project, repo, bitbucket.CommitOptions{
+		Since:      target,
+		Until:      source,
+		WithCounts: true,
+		Limit:      limit,
+	})
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = fmt.Sprintf("%s: %s → %s", repo, bitbucket.RefName(source), bitbucket.RefName(target))
+	attachement.TitleLink = fmt.Sprintf("https://%s/%s?sourceBranch=%s&targetBranch=%s", u.Bitbucket.Server,
+		fmt.Sprintf(bitbucket.APIPaths["compare"], project, repo), url.QueryEscape(source), url.QueryEscape(target))
+	attachement.Text = bitbucketCommitSubjects(commits, max)
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Commits",
+			Value: formatNumber(commits.TotalCount),
+			Short: true,
+		},
+	}
+
+	if commits.TotalCount > 0 {
+		field, err := u.bitbucketCompareChangesField(project, repo, source, target)
+		if err != nil {
+			u.Logger.WithError(err).WithField("repo", project+"/"+repo).Warn("Failed to get compare diff statistics")
+		} else {
+			attachement.Fields = append(attachement.Fields, field)
+		}
+	}
+
+	keys := []string{}
+	for _, commit := range commits.Values {
+		keys = append(keys, commitIssueKeys(commit)...)
+	}
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(keys...)); ok {
+		// Only the listed commits are searched for issue keys
+		if commits.TotalCount > len(commits.Values) {
+			field.Title = fmt.Sprintf("Jira (first %d commits)", len(commits.Values))
+		}
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketCompareChangesField returns a field with the number of files and
+// lines changed on source compared to target. Diffs too large to read only
+// show the number of files changed.
+func (u *Unfurl) bitbucketCompareChangesField(project string, repo string, source string, target string) (slack.AttachmentField, error) {
+	changes, err := u.Bitbucket.CompareChanges(project, repo, source, target, bitbucketMaxChanges)
+	if err != nil {
+		return slack.AttachmentField{}, err
+	}
+
+	diff, err := u.Bitbucket.CompareDiff(project, repo, source, target)
+	if errors.Is(err, bitbucket.ErrResponseTooLarge) {
+		return slack.AttachmentField{Title: "Changes", Value: filesChanged(changes.Size, !changes.IsLastPage), Short: true}, nil
+	}
+	if err != nil {
+		return slack.AttachmentField{}, err
+	}
+
+	stat := diff.Stat()
+
+	return changesField(changes.Size, !changes.IsLastPage, stat.Added, stat.Removed), nil
+}
+
+// bitbucketCommitSubjects returns the subject and author of up to max commits,
+// one per line.
+func bitbucketCommitSubjects(commits bitbucket.CommitList, max int) string {
+	if commits.TotalCount == 0 && len(commits.Values) == 0 {
+		return "No commits"
+	}
+
+	lines := []string{}
+	for i, commit := range commits.Values {
+		if i == max {
+			break
+		}
+
+		subject := strings.SplitN(commit.Message, "\n", 2)[0]
+		lines = append(lines, fmt.Sprintf("`%s` %s – %s", commit.DisplayID, subject, commit.Author.DisplayName))
+	}
+
+	if more := commits.TotalCount - len(lines); more > 0 {
+		lines = append(lines, fmt.Sprintf("and %s more", formatNumber(more)))
+	}
+
+	return strings.Join(lines, "\n")
+}
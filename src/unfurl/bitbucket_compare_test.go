@@ -0,0 +1,100 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketCompareLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	api := func(path string) string {
+		return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], project, repo))
+	}
+
+	httpmock.RegisterResponder("GET", api("defaultBranch"),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-default-branch.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("repoCommits"), "since=refs/heads/master&until=refs/heads/release&withCounts=true&limit=10",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-compare-commits.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("compareChanges"), "from=refs/heads/release&to=refs/heads/master&limit=1000",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-changes-297.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("compareDiff"), "from=refs/heads/release&to=refs/heads/master&contextLines=0",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-diff-297.json")))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(jira.APIPaths["base"], jiraServer, jira.APIPaths["search"]),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-search.json")))
+
+	u := Unfurl{
+		Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+		Jira:      &jira.Client{Server: jiraServer, PAT: "my-token"},
+		Config:    &utils.Config{BitbucketCompareCommits: 10, JiraIssueLimit: 5},
+	}
+
+	t.Run("should list commits, changes and Jira issues", func(t *testing.T) {
+		linkUrl, _ := url.Parse(fmt.Sprintf("https://%s/%s?sourceBranch=refs%%2Fheads%%2Frelease&targetBranch=refs%%2Fheads%%2Fmaster",
+			server, fmt.Sprintf(bitbucket.APIPaths["compare"], project, repo)))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo: release → master", attachment.Title)
+		assert.Equal(t, "https://bitbucket.corp.org/projects/MY-PROJ/repos/my-repo/compare/commits?sourceBranch=refs%2Fheads%2Frelease&targetBranch=refs%2Fheads%2Fmaster", attachment.TitleLink)
+		assert.Equal(t, "`c2646bb9a62` PROJ-1396 My awesome commit message – User A\n`8d51122def5` PROJ-1401 Update release notes – User B", attachment.Text)
+		assert.DeepEqual(t, []string{"Commits", "Changes", "Jira"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "2", attachment.Fields[0].Value)
+		assert.Equal(t, "4 files, +57 −20", attachment.Fields[1].Value)
+	})
+
+	t.Run("should compare to the default branch", func(t *testing.T) {
+		attachment, err := u.bitbucketCompareLink(project, repo, "refs/heads/release", "")
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo: release → master", attachment.Title)
+	})
+
+	t.Run("should label Jira issues from part of the commits", func(t *testing.T) {
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits"), "since=refs/heads/master&until=refs/heads/release&withCounts=true&limit=1",
+			httpmock.NewStringResponder(200, strings.Replace(utils.ReadTestdataFile("bitbucket-compare-commits.json"), `"totalCount": 2`, `"totalCount": 40`, 1)))
+
+		u := u
+		u.Config = &utils.Config{BitbucketCompareCommits: 1, JiraIssueLimit: 5}
+
+		attachment, err := u.bitbucketCompareLink(project, repo, "refs/heads/release", "refs/heads/master")
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"Commits", "Changes", "Jira (first 2 commits)"}, fieldTitles(attachment.Fields))
+	})
+
+	t.Run("should only count files of diffs too large to read", func(t *testing.T) {
+		httpmock.RegisterResponderWithQuery("GET", api("compareDiff"), "from=refs/heads/release&to=refs/heads/master&contextLines=0",
+			httpmock.NewStringResponder(200, strings.Repeat(" ", 6<<20)))
+
+		attachment, err := u.bitbucketCompareLink(project, repo, "refs/heads/release", "refs/heads/master")
+		assert.NilError(t, err)
+
+		assert.Equal(t, "Changes", attachment.Fields[1].Title)
+		assert.Equal(t, "4 files", attachment.Fields[1].Value)
+	})
+}
+
+func TestBitbucketCommitSubjects(t *testing.T) {
+	commits := bitbucket.CommitList{
+		TotalCount: 12,
+		Values: []bitbucket.Commit{
+			{DisplayID: "c2646bb9a62", Message: "First commit\n\nWith a body", Author: bitbucket.User{DisplayName: "User A"}},
+			{DisplayID: "8d51122def5", Message: "Second commit", Author: bitbucket.User{DisplayName: "User B"}},
+		},
+	}
+
+	assert.Equal(t, "`c2646bb9a62` First commit – User A\nand 11 more", bitbucketCommitSubjects(commits, 1))
+	assert.Equal(t, "and 12 more", bitbucketCommitSubjects(commits, 0))
+	assert.Equal(t, "No commits", bitbucketCommitSubjects(bitbucket.CommitList{}, 10))
+}
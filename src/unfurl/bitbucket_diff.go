@@ -0,0 +1,67 @@
+package unfurl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketMaxChanges is the max number of changed files counted
+const bitbucketMaxChanges = 1000
+
+// bitbucketPRDiffStat returns fields with the diff statistics of a Pull
+// Request, and a warning if the Pull Request is large. Diffs too large to
+// read only show the number of files changed and are always flagged.
+func (u *Unfurl) bitbucketPRDiffStat(proj string, repo string, prid int) ([]slack.AttachmentField, string, error) {
+	top, large := 5, 1000
+	if u.Config != nil {
+		top, large = u.Config.BitbucketDiffFiles, u.Config.BitbucketLargePRLines
+	}
+
+	changes, err := u.Bitbucket.PullRequestChanges(proj, repo, prid, bitbucketMaxChanges)
+	if err != nil {
+		return nil, "", err
+	}
+
+	diff, err := u.Bitbucket.PullRequestDiff(proj, repo, prid)
+	if errors.Is(err, bitbucket.ErrResponseTooLarge) {
+		field := slack.AttachmentField{Title: "Changes", Value: filesChanged(changes.Size, !changes.IsLastPage), Short: true}
+		return []slack.AttachmentField{field}, ":warning: Large PR: too many lines to count", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	stat := diff.Stat()
+
+	fields := []slack.AttachmentField{changesField(changes.Size, !changes.IsLastPage, stat.Added, stat.Removed)}
+
+	if top > 0 && len(stat.Files) > 0 {
+		lines := []string{}
+		for i, f := range stat.Files {
+			if i == top {
+				lines = append(lines, fmt.Sprintf("and %d more", len(stat.Files)-top))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("`%s` +%d −%d", f.Path, f.Added, f.Removed))
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: "Changed Files",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	warning := ""
+	if large > 0 && stat.Lines() > large {
+		warning = fmt.Sprintf(":warning: Large PR: %s lines", formatNumber(stat.Lines()))
+		if diff.Truncated {
+			warning += " or more"
+		}
+	}
+
+	return fields, warning, nil
+}
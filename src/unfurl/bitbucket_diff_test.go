@@ -0,0 +1,69 @@
+package unfurl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketPRDiffStat(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["changes"], project, repo, pr)),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-changes-297.json")))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["diff"], project, repo, pr)),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-request-diff-297.json")))
+
+	unfurl := func(files int, large int) *Unfurl {
+		return &Unfurl{
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Config:    &utils.Config{BitbucketDiffStats: true, BitbucketDiffFiles: files, BitbucketLargePRLines: large},
+		}
+	}
+
+	t.Run("should show changes and top files", func(t *testing.T) {
+		fields, warning, err := unfurl(2, 1000).bitbucketPRDiffStat(project, repo, 297)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "", warning)
+		assert.Equal(t, 2, len(fields))
+		assert.Equal(t, "4 files, +57 −20", fields[0].Value)
+		assert.Equal(t, "`src/unfurl/bitbucket.go` +40 −10\n`src/main.go` +12 −3\nand 2 more", fields[1].Value)
+	})
+
+	t.Run("should not show files when disabled", func(t *testing.T) {
+		fields, _, err := unfurl(0, 1000).bitbucketPRDiffStat(project, repo, 297)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(fields))
+	})
+
+	t.Run("should flag large pull requests", func(t *testing.T) {
+		_, warning, err := unfurl(5, 50).bitbucketPRDiffStat(project, repo, 297)
+		assert.NilError(t, err)
+		assert.Equal(t, ":warning: Large PR: 77 lines", warning)
+	})
+
+	t.Run("should use defaults without config", func(t *testing.T) {
+		u := &Unfurl{Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"}}
+		fields, _, err := u.bitbucketPRDiffStat(project, repo, 297)
+		assert.NilError(t, err)
+		assert.Equal(t, 2, len(fields))
+	})
+
+	t.Run("should flag pull requests with diffs too large to read", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["diff"], project, repo, pr)),
+			httpmock.NewStringResponder(200, strings.Repeat(" ", 6<<20)))
+
+		fields, warning, err := unfurl(5, 1000).bitbucketPRDiffStat(project, repo, 297)
+		assert.NilError(t, err)
+		assert.Equal(t, ":warning: Large PR: too many lines to count", warning)
+		assert.Equal(t, 1, len(fields))
+		assert.Equal(t, "4 files", fields[0].Value)
+	})
+}
@@ -0,0 +1,78 @@
+package unfurl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketInsightResults are the emojis for Code Insights report results
+var bitbucketInsightResults = map[string]string{
+	bitbucket.InsightResultPass: ":white_check_mark:",
+	bitbucket.InsightResultFail: ":x:",
+}
+
+// bitbucketInsightsField returns a field with the result and metrics of the
+// Code Insights reports for a commit, and false if insights are disabled or
+// the commit has no reports.
+func (u *Unfurl) bitbucketInsightsField(project string, repo string, sha string) (slack.AttachmentField, bool) {
+	if u.Config == nil || !u.Config.BitbucketInsights {
+		return slack.AttachmentField{}, false
+	}
+
+	reports, err := u.Bitbucket.Reports(project, repo, sha)
+	if err != nil {
+		u.Logger.WithError(err).WithField("commit", sha).Warn("Failed to get Code Insights reports")
+		return slack.AttachmentField{}, false
+	}
+
+	if len(reports.Values) == 0 {
+		return slack.AttachmentField{}, false
+	}
+
+	annotations, err := u.Bitbucket.Annotations(project, repo, sha)
+	if err != nil {
+		u.Logger.WithError(err).WithField("commit", sha).Warn("Failed to get Code Insights annotations")
+	}
+
+	lines := make([]string, len(reports.Values))
+	for i, report := range reports.Values {
+		lines[i] = bitbucketInsightLine(report, annotations.Counts(report.Key))
+	}
+
+	return slack.AttachmentField{
+		Title: "Code Insights",
+		Value: strings.Join(lines, "\n"),
+	}, true
+}
+
+// bitbucketInsightLine returns a report as a single line, e.g.
+// ":x: SonarQube: Coverage 78%, Bugs 3 (2 high, 1 low)"
+func bitbucketInsightLine(report bitbucket.InsightReport, annotations string) string {
+	result, ok := bitbucketInsightResults[report.Result]
+	if !ok {
+		result = ":grey_question:"
+	}
+
+	title := report.Title
+	if report.Link != "" {
+		title = fmt.Sprintf("<%s|%s>", report.Link, report.Title)
+	}
+
+	metrics := make([]string, len(report.Data))
+	for i, d := range report.Data {
+		metrics[i] = d.String()
+	}
+
+	line := fmt.Sprintf("%s %s", result, title)
+	if len(metrics) > 0 {
+		line += ": " + strings.Join(metrics, ", ")
+	}
+	if annotations != "" {
+		line += fmt.Sprintf(" (%s)", annotations)
+	}
+
+	return line
+}
@@ -0,0 +1,52 @@
+package unfurl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketInsightsField(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+	api := func(path string) string {
+		return fmt.Sprintf(bitbucket.InsightsPaths["base"], server, fmt.Sprintf(bitbucket.InsightsPaths[path], project, repo, sha))
+	}
+
+	httpmock.RegisterResponder("GET", api("reports"),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-insights-reports.json")))
+	httpmock.RegisterResponder("GET", api("annotations"),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-insights-annotations.json")))
+
+	unfurl := func(insights bool) *Unfurl {
+		return &Unfurl{
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Config:    &utils.Config{BitbucketInsights: insights},
+		}
+	}
+
+	t.Run("should show report results and metrics", func(t *testing.T) {
+		field, ok := unfurl(true).bitbucketInsightsField(project, repo, sha)
+		assert.Equal(t, true, ok)
+
+		assert.Equal(t, "Code Insights", field.Title)
+		assert.Equal(t, ":white_check_mark: <https://sonar.corp.org/dashboard?id=my-repo|SonarQube>: Coverage 78%, Bugs 0, Duplication 2.4% (1 medium)\n"+
+			":x: Security scan: Critical vulnerabilities 3, Scan time 1m35s (2 high, 1 low)", field.Value)
+	})
+
+	t.Run("should not show reports when disabled", func(t *testing.T) {
+		_, ok := unfurl(false).bitbucketInsightsField(project, repo, sha)
+		assert.Equal(t, false, ok)
+	})
+}
+
+func TestBitbucketInsightLine(t *testing.T) {
+	report := bitbucket.InsightReport{Title: "Pending scan"}
+	assert.Equal(t, ":grey_question: Pending scan", bitbucketInsightLine(report, ""))
+}
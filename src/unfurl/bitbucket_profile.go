@@ -0,0 +1,211 @@
+package unfurl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// bitbucketMaxRepos is the max number of repos counted in project unfurls
+	bitbucketMaxRepos = 1000
+
+	// bitbucketProjectRepos is the default number of recently active repos
+	// listed in project unfurls
+	bitbucketProjectRepos = 5
+
+	// bitbucketMaxPullRequests is the max number of Pull Requests counted
+	// per repo in user unfurls
+	bitbucketMaxPullRequests = 100
+
+	// bitbucketActivityRepos is the max number of repos checked for recent
+	// activity in project unfurls, and for open Pull Requests in user
+	// unfurls, as each repo takes a request. The field titles say so when
+	// there are more repos.
+	bitbucketActivityRepos = 10
+)
+
+// bitbucketProjectLink returns a Slack Attachment for Bitbucket project links
+func (u *Unfurl) bitbucketProjectLink(key string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	project, err := u.Bitbucket.Project(key)
+	if err != nil {
+		return attachement, err
+	}
+
+	repos, err := u.Bitbucket.Repositories(key, bitbucketMaxRepos)
+	if err != nil {
+		return attachement, err
+	}
+
+	count := formatNumber(repos.Size)
+	if !repos.IsLastPage {
+		count += "+"
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = fmt.Sprintf("%s (%s)", project.Name, project.Key)
+	attachement.TitleLink = fmt.Sprintf("https://%s/%s", u.Bitbucket.Server, fmt.Sprintf(bitbucket.APIPaths["project"], project.Key))
+	attachement.Text = project.Description
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Repositories",
+			Value: count,
+			Short: true,
+		},
+	}
+
+	max := bitbucketProjectRepos
+	if u.Config != nil {
+		max = u.Config.BitbucketProjectRepos
+	}
+
+	if lines := u.bitbucketActiveRepos(repos.Values, max); len(lines) > 0 {
+		title := "Recently Active"
+		if len(repos.Values) > bitbucketActivityRepos {
+			title = fmt.Sprintf("Recently Active (of first %d repos)", bitbucketActivityRepos)
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: title,
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	return attachement, nil
+}
+
+// bitbucketActiveRepos returns up to max repos with the most recent commits,
+// with the time of their last commit. Empty repos are left out.
+func (u *Unfurl) bitbucketActiveRepos(repos []bitbucket.Repository, max int) []string {
+	type activity struct {
+		repo   bitbucket.Repository
+		commit bitbucket.Commit
+	}
+
+	if max <= 0 {
+		return nil
+	}
+
+	active := []activity{}
+	for i, r := range repos {
+		if i == bitbucketActivityRepos {
+			break
+		}
+
+		commits, err := u.Bitbucket.Commits(r.Project.Key, r.Slug, bitbucket.CommitOptions{Limit: 1})
+		if err != nil || len(commits.Values) == 0 {
+			continue
+		}
+
+		active = append(active, activity{repo: r, commit: commits.Values[0]})
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].commit.AuthorTimestamp > active[j].commit.AuthorTimestamp
+	})
+
+	lines := []string{}
+	for i, a := range active {
+		if i == max {
+			break
+		}
+
+		name := a.repo.Name
+		if len(a.repo.Links.Self) > 0 {
+			name = fmt.Sprintf("<%s|%s>", a.repo.Links.Self[0].Href, a.repo.Name)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", name, a.commit.TimeAgo()))
+	}
+
+	return lines
+}
+
+// bitbucketUserLink returns a Slack Attachment for Bitbucket user links
+func (u *Unfurl) bitbucketUserLink(slug string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	user, err := u.Bitbucket.User(slug)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = user.DisplayName
+	attachement.TitleLink = fmt.Sprintf("https://%s/%s", u.Bitbucket.Server, fmt.Sprintf(bitbucket.APIPaths["user"], user.Slug))
+	attachement.ThumbURL = user.AvatarURL
+	if strings.HasPrefix(user.AvatarURL, "/") {
+		attachement.ThumbURL = fmt.Sprintf("https://%s%s", u.Bitbucket.Server, user.AvatarURL)
+	}
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Email",
+			Value: user.Email,
+			Short: true,
+		},
+	}
+
+	count, partial, err := u.bitbucketOpenPullRequestCount(user.Slug)
+	if err != nil {
+		u.Logger.WithError(err).WithField("user", user.Slug).Warn("Failed to count open Pull Requests")
+	} else {
+		title := "Open PRs"
+		if partial {
+			title = fmt.Sprintf("Open PRs (in first %d repos)", bitbucketActivityRepos)
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: title,
+			Value: count,
+			Short: true,
+		})
+	}
+
+	return attachement, nil
+}
+
+// bitbucketOpenPullRequestCount returns the number of open Pull Requests
+// authored by the user. Bitbucket has no API to search Pull Requests across
+// repos, so only the first repos are searched and partial is true when there
+// are more. The count is marked with a plus when a repo has more Pull
+// Requests than are counted, or could not be searched. With a visibility
+// policy only public repos are searched, so that the count does not tell
+// anything about private repos.
+func (u *Unfurl) bitbucketOpenPullRequestCount(slug string) (string, bool, error) {
+	var repos bitbucket.RepositoryList
+	var err error
+
+	if u.audience != nil && u.visibilityPolicy() != VisibilityPolicyOff {
+		repos, err = u.Bitbucket.PublicRepositories(bitbucketActivityRepos)
+	} else {
+		repos, err = u.Bitbucket.Repositories("", bitbucketActivityRepos)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	count := 0
+	more := false
+	for _, r := range repos.Values {
+		prs, err := u.Bitbucket.AuthoredPullRequests(r.Project.Key, r.Slug, slug, bitbucketMaxPullRequests)
+		if err != nil {
+			more = true
+			continue
+		}
+
+		count += prs.Size
+		more = more || !prs.IsLastPage
+	}
+
+	if more {
+		return formatNumber(count) + "+", !repos.IsLastPage, nil
+	}
+
+	return formatNumber(count), !repos.IsLastPage, nil
+}
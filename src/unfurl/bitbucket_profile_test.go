@@ -0,0 +1,227 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketProjectLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	api := func(path string, args ...interface{}) string {
+		return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], args...))
+	}
+
+	httpmock.RegisterResponder("GET", api("project", project),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-project.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("projectRepos", project), "limit=1000",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-project-repos.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, repo), "limit=1",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-ahead.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, "my-other-repo"), "limit=1",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-latest.json")))
+
+	linkUrl, _ := url.Parse(fmt.Sprintf("https://%s/projects/%s", server, project))
+
+	t.Run("should list most recently active repos first", func(t *testing.T) {
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "My Project (MY-PROJ)", attachment.Title)
+		assert.Equal(t, "https://bitbucket.corp.org/projects/MY-PROJ", attachment.TitleLink)
+		assert.Equal(t, "My Project description", attachment.Text)
+		assert.DeepEqual(t, []string{"Repositories", "Recently Active"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "2", attachment.Fields[0].Value)
+
+		lines := bitbucketActiveRepoNames(attachment.Fields[1].Value)
+		assert.DeepEqual(t, []string{"my-other-repo", "my-repo"}, lines)
+	})
+
+	t.Run("should limit recently active repos", func(t *testing.T) {
+		u := Unfurl{
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Config:    &utils.Config{BitbucketProjectRepos: 1},
+		}
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"my-other-repo"}, bitbucketActiveRepoNames(attachment.Fields[1].Value))
+	})
+
+	t.Run("should say when only the first repos are checked for activity", func(t *testing.T) {
+		values := []string{
+			`{"slug":"my-repo","name":"my-repo","project":{"key":"MY-PROJ"}}`,
+			`{"slug":"my-other-repo","name":"my-other-repo","project":{"key":"MY-PROJ"}}`,
+		}
+		for i := 0; i < bitbucketActivityRepos; i++ {
+			values = append(values, fmt.Sprintf(`{"slug":"repo-%d","name":"repo-%d","project":{"key":"MY-PROJ"}}`, i, i))
+		}
+
+		httpmock.RegisterResponderWithQuery("GET", api("projectRepos", project), "limit=1000",
+			httpmock.NewStringResponder(200, fmt.Sprintf(`{"size":%d,"isLastPage":true,"values":[%s]}`, len(values), strings.Join(values, ","))))
+		defer httpmock.RegisterResponderWithQuery("GET", api("projectRepos", project), "limit=1000",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-project-repos.json")))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"Repositories", "Recently Active (of first 10 repos)"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "12", attachment.Fields[0].Value)
+	})
+
+	privateProject := strings.Replace(utils.ReadTestdataFile("bitbucket-project.json"), `"public": true`, `"public": false`, 1)
+	httpmock.RegisterResponder("GET", api("users"),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-users-permission.json")))
+
+	shared := func(policy string, private bool) *Unfurl {
+		s := &fakeSlack{}
+		s.channel.IsPrivate = private
+		s.user.Profile.Email = "user.d@corp.org"
+
+		u := &Unfurl{
+			Logger:    logrus.StandardLogger(),
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Slack:     s,
+			Config:    &utils.Config{BitbucketServer: server, BitbucketProjectRepos: 5, VisibilityPolicy: policy},
+		}
+
+		return u.sharedWith("U123", "C123")
+	}
+
+	t.Run("should restrict private projects before reading their repos", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", api("project", project), httpmock.NewStringResponder(200, privateProject))
+		httpmock.ZeroCallCounters()
+
+		attachment, err := shared(VisibilityPolicyRestricted, false).bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, ":lock: Restricted", attachment.Title)
+		assert.Equal(t, 0, len(attachment.Fields))
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["GET "+api("projectRepos", project)+"?limit=1000"])
+	})
+
+	t.Run("should unfurl private projects in private channels when the user has access", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", api("project", project), httpmock.NewStringResponder(200, privateProject))
+		httpmock.ZeroCallCounters()
+
+		attachment, err := shared(VisibilityPolicyHide, true).bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"Repositories", "Recently Active"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["GET "+api("projectRepos", project)+"?limit=1000"])
+	})
+}
+
+func TestBitbucketUserLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	api := func(path string, args ...interface{}) string {
+		return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], args...))
+	}
+
+	httpmock.RegisterResponderWithQuery("GET", api("user", "user-d"), "avatarSize=64",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-user.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("repos"), "limit=10",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-project-repos.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("pullRequests", project, repo), "state=OPEN&role.1=AUTHOR&username.1=user-d&limit=100",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-requests-outgoing.json")))
+	httpmock.RegisterResponderWithQuery("GET", api("pullRequests", project, "my-other-repo"), "state=OPEN&role.1=AUTHOR&username.1=user-d&limit=100",
+		httpmock.NewStringResponder(200, `{"size":0,"isLastPage":true,"values":[]}`))
+
+	linkUrl, _ := url.Parse(fmt.Sprintf("https://%s/users/user-d", server))
+
+	t.Run("should count open pull requests", func(t *testing.T) {
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "User D", attachment.Title)
+		assert.Equal(t, "https://bitbucket.corp.org/users/user-d", attachment.TitleLink)
+		assert.Equal(t, "https://bitbucket.corp.org/users/user-d/avatar.png?s=64", attachment.ThumbURL)
+		assert.DeepEqual(t, []string{"Email", "Open PRs"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "user.d@corp.org", attachment.Fields[0].Value)
+		assert.Equal(t, "1", attachment.Fields[1].Value)
+	})
+
+	shared := func(private bool) *Unfurl {
+		s := &fakeSlack{}
+		s.channel.IsPrivate = private
+		s.user.Profile.Email = "user.d@corp.org"
+
+		u := &Unfurl{
+			Logger:    logrus.StandardLogger(),
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Slack:     s,
+			Config:    &utils.Config{BitbucketServer: server, VisibilityPolicy: VisibilityPolicyRestricted},
+		}
+
+		return u.sharedWith("U123", "C123")
+	}
+
+	t.Run("should restrict users in public channels with a visibility policy", func(t *testing.T) {
+		attachment, err := shared(false).bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, ":lock: Restricted", attachment.Title)
+		assert.Equal(t, "", attachment.ThumbURL)
+		assert.Equal(t, 0, len(attachment.Fields))
+	})
+
+	t.Run("should only search public repos with a visibility policy", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", api("users"),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-users-permission.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repos"), "visibility=public&limit=10",
+			httpmock.NewStringResponder(200, `{"size":1,"isLastPage":true,"values":[{"slug":"my-other-repo","project":{"key":"MY-PROJ"},"public":true}]}`))
+
+		attachment, err := shared(true).bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"Email", "Open PRs"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "0", attachment.Fields[1].Value)
+	})
+
+	t.Run("should say when only the first repos are searched", func(t *testing.T) {
+		httpmock.RegisterResponderWithQuery("GET", api("repos"), "limit=10",
+			httpmock.NewStringResponder(200, strings.Replace(utils.ReadTestdataFile("bitbucket-project-repos.json"), `"isLastPage": true`, `"isLastPage": false`, 1)))
+		defer httpmock.RegisterResponderWithQuery("GET", api("repos"), "limit=10",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-project-repos.json")))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, []string{"Email", "Open PRs (in first 10 repos)"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "1", attachment.Fields[1].Value)
+	})
+
+	t.Run("should skip repos that can not be searched", func(t *testing.T) {
+		httpmock.RegisterResponderWithQuery("GET", api("pullRequests", project, "my-other-repo"), "state=OPEN&role.1=AUTHOR&username.1=user-d&limit=100",
+			httpmock.NewStringResponder(500, `{"errors":[{"message":"Internal error"}]}`))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "1+", attachment.Fields[1].Value)
+	})
+}
+
+// bitbucketActiveRepoNames returns the repo names of recently active repo
+// lines such as "<https://...|my-repo> 2 days ago"
+func bitbucketActiveRepoNames(value string) []string {
+	names := []string{}
+	for _, line := range strings.Split(value, "\n") {
+		name := strings.SplitN(strings.SplitN(line, "|", 2)[1], ">", 2)[0]
+		names = append(names, name)
+	}
+
+	return names
+}
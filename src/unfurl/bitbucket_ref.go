@@ -0,0 +1,137 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketRefLimit is the number of branches or tags to search for a ref
+const bitbucketRefLimit = 25
+
+// bitbucketRefLink returns a Slack Attachment for links to a Bitbucket
+// branch or tag
+func (u *Unfurl) bitbucketRefLink(project string, repo string, name string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	ref, err := u.bitbucketRef(project, repo, name)
+	if err != nil {
+		return attachement, err
+	}
+
+	commit, err := u.Bitbucket.Commit(project, repo, ref.LatestCommit)
+	if err != nil {
+		return attachement, err
+	}
+
+	st, err := u.Bitbucket.Status(ref.LatestCommit)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.FooterIcon = BitbucketIcon
+	attachement.Footer = "Bitbucket"
+	attachement.Title = fmt.Sprintf("%s: %s", repo, ref.DisplayID)
+	attachement.TitleLink = fmt.Sprintf("https://%s/%s/browse?at=%s", u.Bitbucket.Server,
+		fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo), url.QueryEscape(ref.ID))
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Last Commit",
+			Value: commit.String(),
+			Short: true,
+		},
+	}
+	attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(st)...)
+
+	if !ref.IsDefault {
+		field, err := u.bitbucketAheadBehindField(project, repo, ref)
+		if err != nil {
+			u.Logger.WithError(err).WithField("ref", ref.ID).Warn("Failed to compare ref with the default branch")
+		} else if field.Title != "" {
+			attachement.Fields = append(attachement.Fields, field)
+		}
+	}
+
+	if !ref.IsTag() {
+		prs, err := u.Bitbucket.OutgoingPullRequests(project, repo, ref.ID)
+		if err != nil {
+			u.Logger.WithError(err).WithField("ref", ref.ID).Warn("Failed to get Pull Requests from ref")
+		} else if len(prs.List) > 0 {
+			lines := make([]string, len(prs.List))
+			for i, pr := range prs.List {
+				lines[i] = fmt.Sprintf("#%d %s", pr.ID, pr.Title)
+				if len(pr.Links.Self) > 0 {
+					lines[i] = fmt.Sprintf("<%s|%s>", pr.Links.Self[0].Href, lines[i])
+				}
+			}
+
+			attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+				Title: "Pull Request",
+				Value: strings.Join(lines, "\n"),
+			})
+		}
+	}
+
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(append(commit.JIRAIssueKeys(), ref.DisplayID, commit.Message)...)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// bitbucketRef returns the branch or tag with the given name. Fully
+// qualified refs are only looked up as the type they name.
+func (u *Unfurl) bitbucketRef(project string, repo string, name string) (bitbucket.Ref, error) {
+	isTag := strings.HasPrefix(name, bitbucket.RefPrefixTag)
+	isBranch := strings.HasPrefix(name, bitbucket.RefPrefixBranch)
+	filter := bitbucket.RefName(name)
+
+	if !isTag {
+		branches, err := u.Bitbucket.Branches(project, repo, filter, bitbucketRefLimit)
+		if err != nil {
+			return bitbucket.Ref{}, err
+		}
+
+		if ref, ok := branches.Find(name); ok {
+			return ref, nil
+		}
+	}
+
+	if !isBranch {
+		tags, err := u.Bitbucket.Tags(project, repo, filter, bitbucketRefLimit)
+		if err != nil {
+			return bitbucket.Ref{}, err
+		}
+
+		if ref, ok := tags.Find(name); ok {
+			return ref, nil
+		}
+	}
+
+	return bitbucket.Ref{}, fmt.Errorf("bitbucket ref %s not found in %s/%s", name, project, repo)
+}
+
+// bitbucketAheadBehindField returns a field with the number of commits ref
+// is ahead and behind the default branch, or an empty field if ref is the
+// default branch.
+func (u *Unfurl) bitbucketAheadBehindField(project string, repo string, ref bitbucket.Ref) (slack.AttachmentField, error) {
+	def, err := u.Bitbucket.DefaultBranch(project, repo)
+	if err != nil || def.ID == ref.ID {
+		return slack.AttachmentField{}, err
+	}
+
+	ab, err := u.Bitbucket.AheadBehind(project, repo, ref.ID, def.ID)
+	if err != nil {
+		return slack.AttachmentField{}, err
+	}
+
+	return slack.AttachmentField{
+		Title: fmt.Sprintf("Compared to %s", def.DisplayID),
+		Value: ab.String(),
+		Short: true,
+	}, nil
+}
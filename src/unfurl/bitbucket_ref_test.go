@@ -0,0 +1,100 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketRefLink(t *testing.T) {
+	sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+	api := func(path string, args ...interface{}) string {
+		return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], args...))
+	}
+
+	mock := func() {
+		httpmock.RegisterResponderWithQuery("GET", api("branches", project, repo), "filterText=feature/foo&limit=25",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-branches.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("tags", project, repo), "filterText=v1.2.0&limit=25",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-tags.json")))
+		httpmock.RegisterResponder("GET", api("commit", project, repo, sha),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-commit.json")))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.StatusPaths["base"], server, fmt.Sprintf(bitbucket.StatusPaths["status"], sha)),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-build-status-654382.json")))
+		httpmock.RegisterResponder("GET", api("defaultBranch", project, repo),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-default-branch.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, repo), "since=refs/heads/master&until=refs/heads/feature/foo&withCounts=true&limit=1",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-ahead.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, repo), "since=refs/heads/feature/foo&until=refs/heads/master&withCounts=true&limit=1",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-behind.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, repo), "since=refs/heads/master&until=refs/tags/v1.2.0&withCounts=true&limit=1",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-behind.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits", project, repo), "since=refs/tags/v1.2.0&until=refs/heads/master&withCounts=true&limit=1",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-ahead.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("pullRequests", project, repo), "at=refs/heads/feature/foo&direction=OUTGOING&state=OPEN",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-requests-outgoing.json")))
+	}
+
+	t.Run("Branch", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock()
+
+		linkUrl, _ := url.Parse(fmt.Sprintf("https://%s/%s/browse?at=refs%%2Fheads%%2Ffeature%%2Ffoo", server, fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo)))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo: feature/foo", attachment.Title)
+		assert.Equal(t, "https://bitbucket.corp.org/projects/MY-PROJ/repos/my-repo/browse?at=refs%2Fheads%2Ffeature%2Ffoo", attachment.TitleLink)
+		assert.DeepEqual(t, []string{"Last Commit", "Build Status", "Compared to master", "Pull Request"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "3 ahead, 1 behind", attachment.Fields[2].Value)
+		assert.Equal(t, "<https://bitbucket.corp.org/projects/MY-PROJ/repos/my-repo/pull-requests/297|#297 My new feature>", attachment.Fields[3].Value)
+	})
+
+	t.Run("PullRequestWithoutLinks", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock()
+
+		httpmock.RegisterResponderWithQuery("GET", api("pullRequests", project, repo), "at=refs/heads/feature/foo&direction=OUTGOING&state=OPEN",
+			httpmock.NewStringResponder(200, `{"size":1,"values":[{"id":297,"title":"My new feature"}]}`))
+
+		attachment, err := u.bitbucketRefLink(project, repo, "feature/foo")
+		assert.NilError(t, err)
+		assert.Equal(t, "#297 My new feature", attachment.Fields[3].Value)
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock()
+
+		linkUrl, _ := url.Parse(fmt.Sprintf("https://%s/%s/commits?until=refs/tags/v1.2.0", server, fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo)))
+
+		attachment, err := u.bitbucketLink(linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo: v1.2.0", attachment.Title)
+		assert.DeepEqual(t, []string{"Last Commit", "Build Status", "Compared to master"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "1 ahead, 3 behind", attachment.Fields[2].Value)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("GET", api("branches", project, repo),
+			httpmock.NewStringResponder(200, `{"size":0,"values":[]}`))
+		httpmock.RegisterResponder("GET", api("tags", project, repo),
+			httpmock.NewStringResponder(200, `{"size":0,"values":[]}`))
+
+		_, err := u.bitbucketRefLink(project, repo, "missing")
+		assert.Error(t, err, "bitbucket ref missing not found in MY-PROJ/my-repo")
+	})
+}
@@ -0,0 +1,59 @@
+package unfurl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+// bitbucketBuildFields returns the aggregated build status field, and a field
+// listing each build when build details are enabled.
+func (u *Unfurl) bitbucketBuildFields(st bitbucket.StatusList) []slack.AttachmentField {
+	fields := []slack.AttachmentField{
+		{
+			Title: "Build Status",
+			Value: st.State(),
+			Short: true,
+		},
+	}
+
+	if u.Config == nil || !u.Config.BitbucketBuildDetails || len(st.Values) == 0 {
+		return fields
+	}
+
+	lines := []string{}
+	for _, s := range st.Sorted() {
+		lines = append(lines, bitbucketStatusLine(s))
+	}
+
+	return append(fields, slack.AttachmentField{
+		Title: "Builds",
+		Value: strings.Join(lines, "\n"),
+	})
+}
+
+// bitbucketStatusLine returns a build status as a single line, e.g.
+// ":x: <https://jenkins/job/1|Build> `build-key` – 2 tests failed"
+func bitbucketStatusLine(s bitbucket.Status) string {
+	emoji := statusEmoji(s.State)
+
+	name := s.Name
+	if name == "" {
+		name = s.Key
+	}
+	if s.URL != "" {
+		name = fmt.Sprintf("<%s|%s>", s.URL, name)
+	}
+
+	line := fmt.Sprintf("%s %s", emoji, name)
+	if s.Name != "" && s.Key != "" {
+		line += fmt.Sprintf(" `%s`", s.Key)
+	}
+	if s.Description != "" {
+		line += " – " + s.Description
+	}
+
+	return line
+}
@@ -0,0 +1,37 @@
+package unfurl
+
+import (
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketBuildFields(t *testing.T) {
+	st := bitbucket.StatusList{
+		Values: []bitbucket.Status{
+			{Name: "Build", Key: "build", State: bitbucket.StatusSuccess, URL: "https://jenkins.corp.org/job/build/1/"},
+			{Name: "Deploy", Key: "deploy", State: bitbucket.StatusFailed, Description: "Deployment to test failed"},
+			{Key: "lint", State: "SKIPPED"},
+		},
+	}
+
+	t.Run("should list each build when enabled", func(t *testing.T) {
+		u := Unfurl{Config: &utils.Config{BitbucketBuildDetails: true}}
+
+		fields := u.bitbucketBuildFields(st)
+		assert.DeepEqual(t, []string{"Build Status", "Builds"}, fieldTitles(fields))
+		assert.Equal(t, bitbucket.StatusFailed, fields[0].Value)
+		assert.Equal(t, ":x: Deploy `deploy` – Deployment to test failed\n"+
+			":grey_question: lint\n"+
+			":white_check_mark: <https://jenkins.corp.org/job/build/1/|Build> `build`", fields[1].Value)
+	})
+
+	t.Run("should only show the aggregated status by default", func(t *testing.T) {
+		u := Unfurl{Config: &utils.Config{}}
+
+		fields := u.bitbucketBuildFields(st)
+		assert.DeepEqual(t, []string{"Build Status"}, fieldTitles(fields))
+	})
+}
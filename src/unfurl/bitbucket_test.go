@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
 	"github.com/jarcoal/httpmock"
 	"gotest.tools/assert"
 )
@@ -41,25 +43,59 @@ func TestBitbucketLinkType(t *testing.T) {
 			"/projects/MY-PRO/repos/my-repo/browse/file.ext",
 			"/projects/MY-PRO/repos/my-repo/browse/some/file.ext",
 		},
+		BitbucketURLPullRequestCommentType: {
+			"/projects/MY-PRO/repos/my-repo/pull-requests/123/overview?commentId=456",
+		},
+		BitbucketURLPullRequestDiffType: {
+			"/projects/MY-PRO/repos/my-repo/pull-requests/123/diff#src/main.go?t=42",
+		},
+		BitbucketURLCommitType: {
+			"/projects/MY-PRO/repos/my-repo/commits/c2646bb9a628c4fd935e6e0e7bca2da01afecde7",
+			"/projects/MY-PRO/repos/my-repo/commits/c2646bb",
+			"/projects/MY-PRO/repos/my-repo/commits/c2646bb9a628c4fd935e6e0e7bca2da01afecde7#file.ext",
+			"/projects/MY-PRO/repos/my-repo/browse?at=c2646bb9a628c4fd935e6e0e7bca2da01afecde7",
+		},
+		BitbucketURLCompareType: {
+			"/projects/MY-PRO/repos/my-repo/compare/commits?sourceBranch=refs/heads/release&targetBranch=refs/heads/master",
+			"/projects/MY-PRO/repos/my-repo/compare/diff?sourceBranch=refs%2Fheads%2Frelease",
+			"/projects/MY-PRO/repos/my-repo/compare?sourceBranch=release",
+		},
+		BitbucketURLRefType: {
+			"/projects/MY-PRO/repos/my-repo/browse?at=refs/heads/feature/foo",
+			"/projects/MY-PRO/repos/my-repo/browse?at=refs%2Fheads%2Ffeature%2Ffoo",
+			"/projects/MY-PRO/repos/my-repo/commits?until=refs/tags/v1.2.0",
+			"/projects/MY-PRO/repos/my-repo/branches?at=feature/foo",
+		},
 		BitbucketURLRepoType: {
 			"/projects/MY-PRO/repos/my-repo/browse",
 			"/projects/MY-PRO/repos/my-repo/commits",
 			"/projects/MY-PRO/repos/my-repo/branches",
+			"/projects/MY-PRO/repos/my-repo/compare",
 			"/projects/MY-PRO/repos/my-repo/settings",
 		},
+		BitbucketURLProjectType: {
+			"/projects/MY-PRO",
+			"/projects/MY-PRO/",
+		},
+		BitbucketURLUserType: {
+			"/users/user-d",
+			"/users/user-d/",
+		},
 		BitbucketURLUnknownType: {
 			"/dashboard",
 			"/admin",
 			"/profile",
 			"/account",
+			"/projects/MY-PRO/settings",
+			"/users/user-d/repos",
 		},
 	}
 
 	for shouldBeType, paths := range typeLinks {
 		for _, path := range paths {
 			u := Unfurl{}
-			URL := url.URL{Path: path}
-			isType, _ := u.bitbucketLinkType(&URL)
+			URL, _ := url.Parse(path)
+			isType, _ := u.bitbucketLinkType(URL)
 			if isType != shouldBeType {
 				t.Errorf("URL type should be %s but was %s for url %+v", shouldBeType, isType, u)
 			}
@@ -88,6 +124,12 @@ func TestBitbucketLink(t *testing.T) {
 		httpmock.RegisterResponder("GET", statusAPI,
 			httpmock.NewStringResponder(200, httpmock.File(statusJSON).String()))
 
+		mergeJSON := fmt.Sprintf("%s/%s", testdataDir, "bitbucket-pull-request-merge-297.json")
+		mergePath := fmt.Sprintf(bitbucket.APIPaths["merge"], project, repo, pr)
+
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, mergePath),
+			httpmock.NewStringResponder(200, httpmock.File(mergeJSON).String()))
+
 		attachment, err := u.bitbucketLink(&linkUrl)
 
 		if err != nil {
@@ -97,6 +139,120 @@ func TestBitbucketLink(t *testing.T) {
 		assert.Equal(t, "#297 My new feature", attachment.Title)
 		assert.Equal(t, "User D", attachment.AuthorName)
 		assert.Equal(t, "My awesome description", attachment.Text)
-		assert.Equal(t, 4, len(attachment.Fields))
+		assert.Equal(t, 6, len(attachment.Fields))
+		assert.Equal(t, "2 open tasks, 1 resolved", attachment.Fields[4].Value)
+		assert.Equal(t, ":no_entry: Blocked: Not all required reviewers have approved yet, Not all required builds are successful yet", attachment.Fields[5].Value)
+	})
+
+	t.Run("Repo", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		api := func(path string) string {
+			return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], project, repo))
+		}
+		linkUrl := url.URL{Path: "/" + fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo) + "/browse", Scheme: "https", Host: server}
+
+		httpmock.RegisterResponder("GET", api("repo"),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-fork.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("repoCommits"), "limit=1",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo-commits-latest.json")))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.StatusPaths["base"], server, fmt.Sprintf(bitbucket.StatusPaths["status"], "c2646bb9a628c4fd935e6e0e7bca2da01afecde7")),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-build-status-654382.json")))
+		httpmock.RegisterResponder("GET", api("defaultBranch"),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-default-branch.json")))
+		httpmock.RegisterResponderWithQuery("GET", api("pullRequests"), "state=OPEN&limit=100&start=0",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-requests.json")))
+
+		attachment, err := u.bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo", attachment.Title)
+		assert.DeepEqual(t, []string{"Last Commit", "Build Status", "Default Branch", "Open PRs", "Visibility", "Forked From", "Clone"}, fieldTitles(attachment.Fields))
+		assert.Equal(t, "master", attachment.Fields[2].Value)
+		assert.Equal(t, "6", attachment.Fields[3].Value)
+		assert.Equal(t, ":lock: Private", attachment.Fields[4].Value)
+		assert.Equal(t, "<https://bitbucket.corp.org/projects/MY-PROJ/repos/my-repo/browse|MY-PROJ/my-repo>", attachment.Fields[5].Value)
+		assert.Equal(t, "http: `https://bitbucket.corp.org/scm/~user-d/my-repo.git`\nssh: `ssh://git@bitbucket.corp.org/~user-d/my-repo.git`", attachment.Fields[6].Value)
+	})
+
+	t.Run("EmptyRepo", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		api := func(path string) string {
+			return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], project, repo))
+		}
+		linkUrl := url.URL{Path: "/" + fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo), Scheme: "https", Host: server}
+
+		httpmock.RegisterResponder("GET", api("repo"),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-repo.json")))
+		httpmock.RegisterResponder("GET", api("repoCommits"),
+			httpmock.NewStringResponder(200, `{"size":0,"limit":1,"isLastPage":true,"start":0,"values":[]}`))
+
+		attachment, err := u.bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "my-repo", attachment.Title)
+		assert.Equal(t, "No commits yet", attachment.Fields[0].Value)
+		assert.DeepEqual(t, []string{"Last Commit", "Visibility", "Clone"}, fieldTitles(attachment.Fields))
+	})
+
+	t.Run("RepoWithoutLinks", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		api := func(path string) string {
+			return fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths[path], project, repo))
+		}
+		linkUrl := url.URL{Path: "/" + fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo), Scheme: "https", Host: server}
+
+		httpmock.RegisterResponder("GET", api("repo"),
+			httpmock.NewStringResponder(200, `{"slug":"my-repo","name":"my-repo","project":{"key":"MY-PROJ"}}`))
+		httpmock.RegisterResponder("GET", api("repoCommits"),
+			httpmock.NewStringResponder(200, `{"size":0,"limit":1,"isLastPage":true,"start":0,"values":[]}`))
+
+		attachment, err := u.bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "https://bitbucket.corp.org/projects/MY-PROJ/repos/my-repo/browse", attachment.TitleLink)
 	})
+
+	t.Run("Commit", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+		linkPath := fmt.Sprintf(bitbucket.APIPaths["commit"], project, repo, sha)
+		linkUrl := url.URL{Path: "/" + linkPath, Scheme: "https", Host: server}
+
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, linkPath),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-commit.json")))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.StatusPaths["base"], server, fmt.Sprintf(bitbucket.StatusPaths["status"], sha)),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-build-status-654382.json")))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(jira.APIPaths["base"], jiraServer, jira.APIPaths["search"]),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-search.json")))
+
+		u := Unfurl{
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Jira:      &jira.Client{Server: jiraServer, PAT: "my-token"},
+		}
+
+		attachment, err := u.bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "PROJ-1396 My awesome commit message", attachment.Title)
+		assert.Equal(t, "Also fixes PROJ-1400.", attachment.Text)
+		assert.Equal(t, "https://bitbucket.corp.org/"+linkPath, attachment.TitleLink)
+		assert.Equal(t, "c2646bb9a62", attachment.Fields[0].Value)
+		assert.Equal(t, "Jira", attachment.Fields[2].Title)
+		assert.Equal(t, "<https://jira.corp.org/browse/PROJ-1396|PROJ-1396> In Review\n<https://jira.corp.org/browse/PROJ-1400|PROJ-1400> Done", attachment.Fields[2].Value)
+	})
+}
+
+func TestBitbucketPRAttachmentWithoutLinks(t *testing.T) {
+	attachment := u.bitbucketPRAttachment(bitbucket.PullRequest{ID: 297, Title: "My new feature"}, bitbucket.StatusList{})
+
+	assert.Equal(t, "#297 My new feature", attachment.Title)
+	assert.Equal(t, "", attachment.TitleLink)
 }
@@ -0,0 +1,54 @@
+package unfurl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// blobLines is the max number of lines shown from file links
+const blobLines = 20
+
+// blobLineAnchor matches line anchors of file links, #L10-L20 on GitHub and
+// #L10-20 on GitLab
+var blobLineAnchor = regexp.MustCompile(`^L([0-9]+)(?:-L?([0-9]+))?$`)
+
+// blobLineRange returns the lines selected in the fragment of a file link,
+// or 0 when no lines are selected.
+func blobLineRange(fragment string) (start int, end int) {
+	m := blobLineAnchor.FindStringSubmatch(fragment)
+	if m == nil {
+		return 0, 0
+	}
+
+	start, _ = strconv.Atoi(m[1])
+	end = start
+	if m[2] != "" {
+		end, _ = strconv.Atoi(m[2])
+	}
+
+	if end < start {
+		start, end = end, start
+	}
+
+	return start, end
+}
+
+// blobExcerpt returns the lines start to end of a file, at most blobLines
+// lines.
+func blobExcerpt(text string, start int, end int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if start < 1 || start > len(lines) {
+		return ""
+	}
+
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	if end-start+1 > blobLines {
+		end = start + blobLines - 1
+	}
+
+	return strings.Join(lines[start-1:end], "\n")
+}
@@ -0,0 +1,36 @@
+package unfurl
+
+import (
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestBlobLineRange(t *testing.T) {
+	ranges := map[string][2]int{
+		"L10":     {10, 10},
+		"L10-L20": {10, 20},
+		"L10-20":  {10, 20},
+		"L20-L10": {10, 20},
+		"":        {0, 0},
+		"readme":  {0, 0},
+	}
+
+	for fragment, expected := range ranges {
+		start, end := blobLineRange(fragment)
+		assert.Equal(t, start, expected[0], fragment)
+		assert.Equal(t, end, expected[1], fragment)
+	}
+}
+
+func TestBlobExcerpt(t *testing.T) {
+	text := ""
+	for i := 1; i <= 30; i++ {
+		text += fmt.Sprintf("line %d\n", i)
+	}
+
+	assert.Equal(t, blobExcerpt(text, 29, 40), "line 29\nline 30")
+	assert.Equal(t, blobExcerpt(text, 31, 31), "")
+	assert.Equal(t, blobExcerpt(text, 1, 30), blobExcerpt(text, 1, blobLines))
+}
@@ -0,0 +1,130 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/confluence"
+	"github.com/slack-go/slack"
+	"github.com/xeonx/timeago"
+)
+
+const (
+	ConfluenceIcon = "https://wac-cdn.atlassian.com/assets/img/favicons/confluence/favicon-32x32.png"
+
+	// confluenceExcerptLength is the max length of page excerpts
+	confluenceExcerptLength = 300
+)
+
+var (
+	confluenceSpacesPath  = regexp.MustCompile(`^/spaces/([^/]+)/pages/([0-9]+)(?:/.*)?$`)
+	confluenceDisplayPath = regexp.MustCompile(`^/display/([^/]+)/([^/]+)/?$`)
+)
+
+// confluencePage is a reference to a page from a link, either by ID or by
+// space and title.
+type confluencePage struct {
+	ID    string
+	Space string
+	Title string
+}
+
+// confluencePageRef returns the page a Confluence link points to
+func confluencePageRef(URL *url.URL) (confluencePage, bool) {
+	if URL.Path == "/pages/viewpage.action" {
+		if id := URL.Query().Get("pageId"); id != "" {
+			return confluencePage{ID: id}, true
+		}
+
+		// Older links have the space and title as query parameters
+		q := URL.Query()
+		if q.Get("spaceKey") != "" && q.Get("title") != "" {
+			return confluencePage{Space: q.Get("spaceKey"), Title: q.Get("title")}, true
+		}
+	}
+
+	if m := confluenceSpacesPath.FindStringSubmatch(URL.Path); m != nil {
+		return confluencePage{ID: m[2], Space: m[1]}, true
+	}
+
+	// Titles use + for spaces, so the escaped path is needed to tell them
+	// from encoded plus signs.
+	if m := confluenceDisplayPath.FindStringSubmatch(URL.EscapedPath()); m != nil {
+		space, err := url.PathUnescape(m[1])
+		if err != nil {
+			return confluencePage{}, false
+		}
+
+		title, err := url.PathUnescape(strings.ReplaceAll(m[2], "+", " "))
+		if err != nil {
+			return confluencePage{}, false
+		}
+
+		return confluencePage{Space: space, Title: title}, true
+	}
+
+	return confluencePage{}, false
+}
+
+// confluenceLink returns a Slack Attachment for Confluence page links
+func (u *Unfurl) confluenceLink(URL *url.URL) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	ref, ok := confluencePageRef(URL)
+	if !ok {
+		return attachement, fmt.Errorf("confluence link not supported")
+	}
+
+	ctx := context.Background()
+
+	var page confluence.Content
+	var err error
+	if ref.ID != "" {
+		page, err = u.Confluence.Page(ctx, ref.ID)
+	} else {
+		page, err = u.Confluence.PageByTitle(ctx, ref.Space, ref.Title)
+	}
+	if err != nil {
+		return attachement, fmt.Errorf("failed to get confluence page: %w", err)
+	}
+
+	attachement.Title = page.Title
+	attachement.TitleLink = u.Confluence.WebURL(page.Links.WebUI)
+	attachement.FooterIcon = ConfluenceIcon
+	attachement.Footer = "Confluence"
+
+	// Only the title of pages with view restrictions, of their own or
+	// inherited from a parent page, is shown as the page may not be visible
+	// to everyone in the channel. So is the title of pages whose restrictions
+	// are not known. The breadcrumb is left out as the ancestor titles may
+	// be restricted too.
+	if page.IsRestricted() {
+		attachement.Text = ":lock: This page has view restrictions."
+		return attachement, nil
+	}
+
+	attachement.Pretext = page.Breadcrumb()
+	attachement.Text = page.Excerpt(confluenceExcerptLength)
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Space",
+			Value: page.Space.Name,
+			Short: true,
+		},
+	}
+
+	if modified := page.LastModified(); !modified.IsZero() {
+		attachement.Ts = json.Number(fmt.Sprint(modified.Unix()))
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Last Updated",
+			Value: fmt.Sprintf("by %s %s", page.Version.By.DisplayName, timeago.NoMax(timeago.English).Format(modified)),
+			Short: true,
+		})
+	}
+
+	return attachement, nil
+}
@@ -0,0 +1,92 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/confluence"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+const confluenceServer = "confluence.corp.org"
+
+func TestConfluencePageRef(t *testing.T) {
+	links := map[string]confluencePage{
+		"/pages/viewpage.action?pageId=123456":                     {ID: "123456"},
+		"/pages/viewpage.action?spaceKey=OPS&title=Deploy+Runbook": {Space: "OPS", Title: "Deploy Runbook"},
+		"/spaces/OPS/pages/123456/Deploy+Runbook":                  {ID: "123456", Space: "OPS"},
+		"/spaces/OPS/pages/123456":                                 {ID: "123456", Space: "OPS"},
+		"/display/OPS/Deploy+Runbook":                              {Space: "OPS", Title: "Deploy Runbook"},
+		"/display/OPS/C%2B%2B+Style+Guide":                         {Space: "OPS", Title: "C++ Style Guide"},
+		"/display/~user-a/My+Notes":                                {Space: "~user-a", Title: "My Notes"},
+	}
+
+	for link, expected := range links {
+		t.Run(fmt.Sprintf("should parse %s", link), func(t *testing.T) {
+			URL, _ := url.Parse(link)
+			ref, ok := confluencePageRef(URL)
+			assert.Equal(t, true, ok)
+			assert.Equal(t, expected, ref)
+		})
+	}
+
+	for _, link := range []string{"/display/OPS", "/pages/viewpage.action", "/spaces/OPS/overview", "/display/OPS/2021/11/10/Blog+Post"} {
+		t.Run(fmt.Sprintf("should not parse %s", link), func(t *testing.T) {
+			URL, _ := url.Parse(link)
+			_, ok := confluencePageRef(URL)
+			assert.Equal(t, false, ok)
+		})
+	}
+}
+
+func TestConfluenceLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf(confluence.APIPaths["base"], confluenceServer, fmt.Sprintf(confluence.APIPaths["content"], "123456")),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("confluence-page-123456.json")))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(confluence.APIPaths["base"], confluenceServer, fmt.Sprintf(confluence.APIPaths["content"], "654321")),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("confluence-page-654321.json")))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(confluence.APIPaths["base"], confluenceServer, confluence.APIPaths["search"]),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("confluence-page-search.json")))
+
+	u := &Unfurl{
+		Confluence: &confluence.Client{Server: confluenceServer, PAT: "my-token"},
+		Config:     &utils.Config{ConfluenceServer: confluenceServer},
+	}
+
+	t.Run("should unfurl page by id", func(t *testing.T) {
+		URL, _ := url.Parse("https://confluence.corp.org/pages/viewpage.action?pageId=123456")
+		a, err := u.confluenceLink(URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "Deploy Runbook", a.Title)
+		assert.Equal(t, "https://confluence.corp.org/display/OPS/Deploy+Runbook", a.TitleLink)
+		assert.Equal(t, "Operations / Operations Home / Runbooks", a.Pretext)
+		assert.Equal(t, "Overview\nThis page describes how we deploy services & roll back.\nBuild the image\nRun the deploy job\nAsk in the channel if stuck", a.Text)
+		assert.Equal(t, "Operations", a.Fields[0].Value)
+		assert.Equal(t, "Last Updated", a.Fields[1].Title)
+		assert.Equal(t, "1636548133", a.Ts.String())
+	})
+
+	t.Run("should unfurl page by title", func(t *testing.T) {
+		URL, _ := url.Parse("https://confluence.corp.org/display/OPS/Deploy+Runbook")
+		a, err := u.confluenceLink(URL)
+		assert.NilError(t, err)
+		assert.Equal(t, "Deploy Runbook", a.Title)
+	})
+
+	t.Run("should not show content of restricted pages", func(t *testing.T) {
+		URL, _ := url.Parse("https://confluence.corp.org/spaces/OPS/pages/654321/Secret+Plans")
+		a, err := u.confluenceLink(URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "Secret Plans", a.Title)
+		assert.Equal(t, ":lock: This page has view restrictions.", a.Text)
+		assert.Equal(t, "", a.Pretext)
+		assert.Equal(t, 0, len(a.Fields))
+	})
+}
@@ -0,0 +1,63 @@
+package unfurl
+
+import (
+	"fmt"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+// statusEmojis are the emojis for build states. Other providers map their
+// states to the Bitbucket build states.
+var statusEmojis = map[string]string{
+	bitbucket.StatusSuccess:    ":white_check_mark:",
+	bitbucket.StatusFailed:     ":x:",
+	bitbucket.StatusInProgress: ":hourglass_flowing_sand:",
+	bitbucket.StatusCancelled:  ":no_entry_sign:",
+}
+
+// statusEmoji returns the emoji for a build state
+func statusEmoji(state string) string {
+	if emoji, ok := statusEmojis[state]; ok {
+		return emoji
+	}
+
+	return ":grey_question:"
+}
+
+// changesField returns a field with the number of files and lines
+// changed. The number of files is marked with a plus when there are more.
+func changesField(files int, more bool, additions int, deletions int) slack.AttachmentField {
+	return slack.AttachmentField{
+		Title: "Changes",
+		Value: fmt.Sprintf("%s, +%s −%s", filesChanged(files, more), formatNumber(additions), formatNumber(deletions)),
+		Short: true,
+	}
+}
+
+// filesChanged returns the number of files changed, e.g. 1,000+ files when
+// there are more than were counted.
+func filesChanged(files int, more bool) string {
+	if more {
+		return fmt.Sprintf("%s+ files", formatNumber(files))
+	} else if files == 1 {
+		return "1 file"
+	}
+
+	return fmt.Sprintf("%s files", formatNumber(files))
+}
+
+// formatNumber returns n with thousands separators, e.g. 1,840
+func formatNumber(n int) string {
+	if n < 0 {
+		return "-" + formatNumber(-n)
+	}
+
+	s := fmt.Sprint(n)
+
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
@@ -0,0 +1,27 @@
+package unfurl
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	numbers := map[int]string{
+		0:       "0",
+		999:     "999",
+		1840:    "1,840",
+		1000000: "1,000,000",
+		-12345:  "-12,345",
+	}
+
+	for n, expected := range numbers {
+		assert.Equal(t, expected, formatNumber(n))
+	}
+}
+
+func TestChangesField(t *testing.T) {
+	assert.Equal(t, "1 file, +3 −0", changesField(1, false, 3, 0).Value)
+	assert.Equal(t, "1,000+ files, +12,345 −20", changesField(1000, true, 12345, 20).Value)
+	assert.Equal(t, "2 files", filesChanged(2, false))
+}
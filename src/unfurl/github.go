@@ -0,0 +1,434 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/github"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+const (
+	GitHubIcon = "https://github.githubassets.com/favicons/favicon.png"
+
+	GitHubURLPullRequestType = "pull_request"
+	GitHubURLIssueType       = "issue"
+	GitHubURLCommitType      = "commit"
+	GitHubURLBlobType        = "blob"
+	GitHubURLRunType         = "workflow_run"
+	GitHubURLUnknownType     = "unknown"
+)
+
+var (
+	githubPullRequestPath = regexp.MustCompile(`^/([^/]+)/([^/]+)/pull/([0-9]+)`)
+	githubIssuePath       = regexp.MustCompile(`^/([^/]+)/([^/]+)/issues/([0-9]+)`)
+	githubCommitPath      = regexp.MustCompile(`^/([^/]+)/([^/]+)/commit/([0-9a-f]{7,40})`)
+	githubBlobPath        = regexp.MustCompile(`^/([^/]+)/([^/]+)/blob/([^/]+)/(.+)$`)
+	githubRunPath         = regexp.MustCompile(`^/([^/]+)/([^/]+)/actions/runs/([0-9]+)`)
+)
+
+// githubLinkType returns the type of GitHub link and the matches. The first
+// two matches are the owner and the repo.
+func githubLinkType(URL *url.URL) (string, []string) {
+	types := []struct {
+		linkType string
+		re       *regexp.Regexp
+	}{
+		{GitHubURLPullRequestType, githubPullRequestPath},
+		{GitHubURLIssueType, githubIssuePath},
+		{GitHubURLCommitType, githubCommitPath},
+		{GitHubURLBlobType, githubBlobPath},
+		{GitHubURLRunType, githubRunPath},
+	}
+
+	for _, t := range types {
+		if m := t.re.FindStringSubmatch(URL.Path); m != nil {
+			return t.linkType, m
+		}
+	}
+
+	return GitHubURLUnknownType, []string{}
+}
+
+// githubLink returns a Slack Attachment for GitHub Enterprise links
+func (u *Unfurl) githubLink(URL *url.URL) (slack.Attachment, error) {
+	ctx := context.Background()
+
+	linkType, matches := githubLinkType(URL)
+	if linkType == GitHubURLUnknownType {
+		return slack.Attachment{}, errors.New("github link not supported")
+	}
+
+	owner, repo := matches[1], matches[2]
+
+	return u.publicOnlyLink(func() (bool, error) {
+		r, err := u.GitHub.Repository(ctx, owner, repo)
+
+		return r.Public(), err
+	}, func() (slack.Attachment, error) {
+		switch linkType {
+		case GitHubURLPullRequestType, GitHubURLIssueType, GitHubURLRunType:
+			id, err := strconv.Atoi(matches[3])
+			if err != nil {
+				return slack.Attachment{}, err
+			}
+
+			if linkType == GitHubURLPullRequestType {
+				return u.githubPRLink(ctx, owner, repo, id)
+			} else if linkType == GitHubURLIssueType {
+				return u.githubIssueLink(ctx, owner, repo, id)
+			}
+
+			return u.githubRunLink(ctx, owner, repo, id)
+
+		case GitHubURLCommitType:
+			return u.githubCommitLink(ctx, owner, repo, matches[3])
+		}
+
+		return u.githubBlobLink(ctx, URL, owner, repo, matches[3], matches[4])
+	})
+}
+
+// githubPRLink returns a Slack Attachment for GitHub pull request links, laid
+// out like Bitbucket pull requests.
+func (u *Unfurl) githubPRLink(ctx context.Context, owner string, repo string, number int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	pr, err := u.GitHub.PullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return attachement, err
+	}
+
+	fields := []slack.AttachmentField{
+		{
+			Title: "PR State",
+			Value: pr.StateName(),
+			Short: true,
+		},
+	}
+
+	if checks, err := u.GitHub.Checks(ctx, owner, repo, pr.Head.SHA); err != nil {
+		u.Logger.WithError(err).WithField("pr", fmt.Sprintf("%s/%s#%d", owner, repo, number)).Warn("Failed to get pull request checks")
+	} else {
+		fields = append(fields, u.bitbucketBuildFields(githubStatusList(checks))...)
+	}
+
+	if reviews, err := u.GitHub.Reviews(ctx, owner, repo, number); err != nil {
+		u.Logger.WithError(err).WithField("pr", fmt.Sprintf("%s/%s#%d", owner, repo, number)).Warn("Failed to get pull request reviews")
+	} else {
+		fields = append(
+			fields,
+			slack.AttachmentField{
+				Title: "Reviewers",
+				Value: reviews.ReviewedBy(),
+				Short: true,
+			}, slack.AttachmentField{
+				Title: "Review Status",
+				Value: reviews.ReviewState(true),
+				Short: true,
+			},
+		)
+	}
+
+	fields = append(fields, changesField(pr.ChangedFiles, false, pr.Additions, pr.Deletions))
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(pr.Title, pr.Head.Ref)); ok {
+		fields = append(fields, field)
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(pr.CreatedAt.Unix()))
+	attachement.FooterIcon = GitHubIcon
+	attachement.Footer = "GitHub"
+	attachement.AuthorName = pr.User.Login
+	attachement.AuthorLink = pr.User.HTMLURL
+	attachement.AuthorIcon = pr.User.AvatarURL
+	attachement.Title = fmt.Sprintf("#%d %s", pr.Number, pr.Title)
+	attachement.TitleLink = pr.HTMLURL
+	attachement.Text = pr.Body
+	attachement.Fields = fields
+
+	return attachement, nil
+}
+
+// githubIssueLink returns a Slack Attachment for GitHub issue links. Issue
+// links to pull requests are unfurled as pull requests.
+func (u *Unfurl) githubIssueLink(ctx context.Context, owner string, repo string, number int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	issue, err := u.GitHub.Issue(ctx, owner, repo, number)
+	if err != nil {
+		return attachement, err
+	}
+
+	if issue.PullRequest != nil {
+		return u.githubPRLink(ctx, owner, repo, number)
+	}
+
+	state := strings.ToUpper(issue.State)
+	if issue.StateReason == "not_planned" {
+		state += " (not planned)"
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "State",
+			Value: state,
+			Short: true,
+		},
+	}
+
+	if len(issue.Assignees) > 0 {
+		assignees := make([]string, len(issue.Assignees))
+		for i, a := range issue.Assignees {
+			assignees[i] = a.Login
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Assignees",
+			Value: strings.Join(assignees, ", "),
+			Short: true,
+		})
+	}
+
+	if labels := issue.LabelNames(); len(labels) > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Labels",
+			Value: strings.Join(labels, ", "),
+			Short: true,
+		})
+	}
+
+	if issue.Milestone != nil {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Milestone",
+			Value: issue.Milestone.Title,
+			Short: true,
+		})
+	}
+
+	if issue.Comments > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Comments",
+			Value: formatNumber(issue.Comments),
+			Short: true,
+		})
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(issue.CreatedAt.Unix()))
+	attachement.FooterIcon = GitHubIcon
+	attachement.Footer = "GitHub"
+	attachement.AuthorName = issue.User.Login
+	attachement.AuthorLink = issue.User.HTMLURL
+	attachement.AuthorIcon = issue.User.AvatarURL
+	attachement.Title = fmt.Sprintf("#%d %s", issue.Number, issue.Title)
+	attachement.TitleLink = issue.HTMLURL
+	attachement.Text = issue.Body
+
+	return attachement, nil
+}
+
+// githubCommitLink returns a Slack Attachment for GitHub commit links, laid
+// out like Bitbucket commits.
+func (u *Unfurl) githubCommitLink(ctx context.Context, owner string, repo string, sha string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	commit, err := u.GitHub.Commit(ctx, owner, repo, sha)
+	if err != nil {
+		return attachement, err
+	}
+
+	lines := strings.SplitN(commit.Commit.Message, "\n", 2)
+
+	attachement.Ts = json.Number(fmt.Sprint(commit.Commit.Author.Date.Unix()))
+	attachement.FooterIcon = GitHubIcon
+	attachement.Footer = "GitHub"
+	attachement.AuthorName = commit.Commit.Author.Name
+	if commit.Author != nil {
+		attachement.AuthorLink = commit.Author.HTMLURL
+		attachement.AuthorIcon = commit.Author.AvatarURL
+	}
+	attachement.Title = lines[0]
+	attachement.TitleLink = commit.HTMLURL
+	if len(lines) > 1 {
+		attachement.Text = strings.TrimSpace(lines[1])
+	}
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Commit",
+			Value: commit.ShortSHA(),
+			Short: true,
+		},
+	}
+	if checks, err := u.GitHub.Checks(ctx, owner, repo, commit.SHA); err != nil {
+		u.Logger.WithError(err).WithField("commit", fmt.Sprintf("%s/%s@%s", owner, repo, commit.ShortSHA())).Warn("Failed to get commit checks")
+	} else {
+		attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(githubStatusList(checks))...)
+	}
+	attachement.Fields = append(attachement.Fields,
+		changesField(len(commit.Files), false, commit.Stats.Additions, commit.Stats.Deletions))
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(commit.Commit.Message)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// githubBlobLink returns a Slack Attachment for links to a file, with the
+// lines selected in the link fragment, e.g. #L10-L20.
+func (u *Unfurl) githubBlobLink(ctx context.Context, URL *url.URL, owner string, repo string, ref string, path string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	content, err := u.GitHub.Contents(ctx, owner, repo, path, ref)
+	if err != nil {
+		return attachement, err
+	}
+
+	title := fmt.Sprintf("%s in %s/%s@%s", path, owner, repo, ref)
+
+	start, end := blobLineRange(URL.Fragment)
+	if start > 0 {
+		text, err := content.Decode()
+		if err != nil {
+			return attachement, err
+		}
+
+		if start == end {
+			title = fmt.Sprintf("%s:%d in %s/%s@%s", path, start, owner, repo, ref)
+		} else {
+			title = fmt.Sprintf("%s:%d-%d in %s/%s@%s", path, start, end, owner, repo, ref)
+		}
+
+		if excerpt := blobExcerpt(text, start, end); excerpt != "" {
+			attachement.Text = fmt.Sprintf("```\n%s\n```", excerpt)
+		}
+	}
+
+	attachement.FooterIcon = GitHubIcon
+	attachement.Footer = "GitHub"
+	attachement.Title = title
+	attachement.TitleLink = URL.String()
+
+	return attachement, nil
+}
+
+// githubRunLink returns a Slack Attachment for GitHub Actions workflow run
+// links
+func (u *Unfurl) githubRunLink(ctx context.Context, owner string, repo string, id int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	run, err := u.GitHub.WorkflowRun(ctx, owner, repo, id)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Status",
+			Value: fmt.Sprintf("%s %s", statusEmoji(githubCheckState(run.Status, run.Conclusion)), run.Result()),
+			Short: true,
+		},
+		{
+			Title: "Duration",
+			Value: run.Duration(time.Now()).String(),
+			Short: true,
+		},
+		{
+			Title: "Branch",
+			Value: run.HeadBranch,
+			Short: true,
+		},
+		{
+			Title: "Commit",
+			Value: github.Commit{SHA: run.HeadSHA}.ShortSHA(),
+			Short: true,
+		},
+		{
+			Title: "Event",
+			Value: run.Event,
+			Short: true,
+		},
+	}
+
+	if run.RunAttempt > 1 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Attempt",
+			Value: fmt.Sprint(run.RunAttempt),
+			Short: true,
+		})
+	}
+
+	if run.Actor != nil {
+		attachement.AuthorName = run.Actor.Login
+		attachement.AuthorLink = run.Actor.HTMLURL
+		attachement.AuthorIcon = run.Actor.AvatarURL
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(run.CreatedAt.Unix()))
+	attachement.FooterIcon = GitHubIcon
+	attachement.Footer = "GitHub Actions"
+	attachement.Title = fmt.Sprintf("%s #%d", run.Name, run.RunNumber)
+	attachement.TitleLink = run.HTMLURL
+	attachement.Text = run.DisplayTitle
+
+	return attachement, nil
+}
+
+// githubStatusList returns the check runs and commit statuses of a ref as
+// Bitbucket build statuses, so they are combined and listed like builds.
+func githubStatusList(checks github.Checks) bitbucket.StatusList {
+	var st bitbucket.StatusList
+
+	for _, run := range checks.CheckRuns.CheckRuns {
+		st.Values = append(st.Values, bitbucket.Status{
+			State:       githubCheckState(run.Status, run.Conclusion),
+			Key:         run.Name,
+			Name:        run.Name,
+			URL:         run.HTMLURL,
+			Description: run.Output.Title,
+		})
+	}
+
+	for _, s := range checks.Status.Statuses {
+		st.Values = append(st.Values, bitbucket.Status{
+			State:       githubCheckState(github.CheckStatusCompleted, s.State),
+			Key:         s.Context,
+			Name:        s.Context,
+			URL:         s.TargetURL,
+			Description: s.Description,
+		})
+	}
+
+	st.Size = len(st.Values)
+	st.IsLastPage = true
+
+	return st
+}
+
+// githubCheckState returns the Bitbucket build state for the status and
+// conclusion of a check run or workflow run, or the state of a commit status.
+func githubCheckState(status string, conclusion string) string {
+	if status != github.CheckStatusCompleted {
+		return bitbucket.StatusInProgress
+	}
+
+	switch conclusion {
+	case github.ConclusionSuccess, github.ConclusionNeutral, github.ConclusionSkipped:
+		return bitbucket.StatusSuccess
+	case github.ConclusionFailure, github.ConclusionTimedOut, github.ConclusionActionRequired, github.StatusStateError:
+		return bitbucket.StatusFailed
+	case github.ConclusionCancelled:
+		return bitbucket.StatusCancelled
+	case github.StatusStatePending:
+		return bitbucket.StatusInProgress
+	}
+
+	return bitbucket.StatusUnknown
+}
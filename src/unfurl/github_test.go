@@ -0,0 +1,240 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/github"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+const (
+	githubServer = "github.corp.org"
+	githubOwner  = "my-org"
+	githubSHA    = "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+)
+
+var gu = Unfurl{
+	Logger: logrus.StandardLogger(),
+	GitHub: &github.Client{Server: githubServer, Token: "my-token"},
+}
+
+// githubAPI returns the mocked GitHub API URL for a path in the test repo
+func githubAPI(path string, args ...interface{}) string {
+	args = append([]interface{}{githubOwner, repo}, args...)
+
+	return fmt.Sprintf(github.APIPaths["base"], gu.GitHub.BaseURL(), fmt.Sprintf(github.APIPaths[path], args...))
+}
+
+// githubURL returns a GitHub link in the test repo
+func githubURL(path string) *url.URL {
+	URL, _ := url.Parse(fmt.Sprintf("https://%s/%s/%s%s", githubServer, githubOwner, repo, path))
+
+	return URL
+}
+
+// mockGitHubChecks mocks the check runs and commit statuses of a commit
+func mockGitHubChecks(sha string) {
+	httpmock.RegisterResponder("GET", githubAPI("checkRuns", sha),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-check-runs.json")))
+	httpmock.RegisterResponder("GET", githubAPI("status", sha),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-commit-status.json")))
+}
+
+func TestGitHubLinkType(t *testing.T) {
+	typeLinks := map[string][]string{
+		GitHubURLPullRequestType: {
+			"/my-org/my-repo/pull/7",
+			"/my-org/my-repo/pull/7/files",
+		},
+		GitHubURLIssueType: {
+			"/my-org/my-repo/issues/12",
+		},
+		GitHubURLCommitType: {
+			"/my-org/my-repo/commit/c2646bb9a628c4fd935e6e0e7bca2da01afecde7",
+			"/my-org/my-repo/commit/c2646bb",
+		},
+		GitHubURLBlobType: {
+			"/my-org/my-repo/blob/main/main.go",
+			"/my-org/my-repo/blob/c2646bb/src/unfurl/github.go",
+		},
+		GitHubURLRunType: {
+			"/my-org/my-repo/actions/runs/30433642",
+			"/my-org/my-repo/actions/runs/30433642/jobs/1",
+		},
+		GitHubURLUnknownType: {
+			"/my-org",
+			"/my-org/my-repo",
+			"/my-org/my-repo/issues",
+		},
+	}
+
+	for expected, links := range typeLinks {
+		for _, link := range links {
+			URL, err := url.Parse("https://" + githubServer + link)
+			assert.NilError(t, err)
+
+			actual, _ := githubLinkType(URL)
+			assert.Equal(t, actual, expected, link)
+		}
+	}
+}
+
+func TestGitHubPRLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", githubAPI("pullRequest", 7),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-pull-request-7.json")))
+	httpmock.RegisterResponder("GET", githubAPI("reviews", 7),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-reviews-7.json")))
+	mockGitHubChecks(githubSHA)
+
+	a, err := gu.githubLink(githubURL("/pull/7"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "#7 ACE-789 Add GitHub unfurls")
+	assert.Equal(t, a.TitleLink, "https://github.corp.org/my-org/my-repo/pull/7")
+	assert.Equal(t, a.AuthorName, "octocat")
+	assert.Equal(t, a.Footer, "GitHub")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"PR State", "Build Status", "Reviewers", "Review Status", "Changes"})
+	assert.Equal(t, a.Fields[0].Value, "OPEN")
+	assert.Equal(t, a.Fields[1].Value, "FAILED")
+	assert.Equal(t, a.Fields[2].Value, "alice (APPROVED), carol (APPROVED)")
+	assert.Equal(t, a.Fields[3].Value, ":white_check_mark: Approved")
+	assert.Equal(t, a.Fields[4].Value, "9 files, +1,840 −12")
+
+	t.Run("should skip checks and reviews that fail", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", githubAPI("reviews", 7),
+			httpmock.NewStringResponder(500, `{"message":"Server Error"}`))
+		httpmock.RegisterResponder("GET", githubAPI("checkRuns", githubSHA),
+			httpmock.NewStringResponder(500, `{"message":"Server Error"}`))
+
+		a, err := gu.githubLink(githubURL("/pull/7"))
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"PR State", "Changes"})
+	})
+}
+
+func TestGitHubIssueLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", githubAPI("issue", 12),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-issue-12.json")))
+
+	a, err := gu.githubLink(githubURL("/issues/12"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "#12 Links to Actions runs are not unfurled")
+	assert.Equal(t, a.Text, "Run links show nothing.")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"State", "Assignees", "Labels", "Milestone", "Comments"})
+	assert.Equal(t, a.Fields[0].Value, "CLOSED")
+	assert.Equal(t, a.Fields[1].Value, "alice, bob")
+	assert.Equal(t, a.Fields[2].Value, "bug, github")
+}
+
+func TestGitHubCommitLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+	httpmock.RegisterResponder("GET", githubAPI("commit", sha),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-commit.json")))
+	mockGitHubChecks(sha)
+
+	a, err := gu.githubLink(githubURL("/commit/" + sha))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "ACE-789 Unfurl commits")
+	assert.Equal(t, a.Text, "Commits show their checks.")
+	assert.Equal(t, a.AuthorName, "Octo Cat")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Commit", "Build Status", "Changes"})
+	assert.Equal(t, a.Fields[0].Value, "c2646bb")
+	assert.Equal(t, a.Fields[2].Value, "1 file, +10 −2")
+
+	t.Run("should skip checks that fail", func(t *testing.T) {
+		httpmock.RegisterResponder("GET", githubAPI("status", sha),
+			httpmock.NewStringResponder(500, `{"message":"Server Error"}`))
+
+		a, err := gu.githubLink(githubURL("/commit/" + sha))
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Commit", "Changes"})
+	})
+}
+
+func TestGitHubBlobLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponderWithQuery("GET", githubAPI("contents", "main.go"), "ref=main",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-contents.json")))
+
+	t.Run("should show the selected lines", func(t *testing.T) {
+		a, err := gu.githubLink(githubURL("/blob/main/main.go#L5-L7"))
+		assert.NilError(t, err)
+
+		assert.Equal(t, a.Title, "main.go:5-7 in my-org/my-repo@main")
+		assert.Equal(t, a.Text, "```\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n```")
+	})
+
+	t.Run("should show a single line", func(t *testing.T) {
+		a, err := gu.githubLink(githubURL("/blob/main/main.go#L1"))
+		assert.NilError(t, err)
+
+		assert.Equal(t, a.Title, "main.go:1 in my-org/my-repo@main")
+		assert.Equal(t, a.Text, "```\npackage main\n```")
+	})
+
+	t.Run("should not show lines without a range", func(t *testing.T) {
+		a, err := gu.githubLink(githubURL("/blob/main/main.go"))
+		assert.NilError(t, err)
+
+		assert.Equal(t, a.Title, "main.go in my-org/my-repo@main")
+		assert.Equal(t, a.Text, "")
+	})
+}
+
+func TestGitHubRunLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", githubAPI("run", 30433642),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-workflow-run.json")))
+
+	a, err := gu.githubLink(githubURL("/actions/runs/30433642"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "CI #562")
+	assert.Equal(t, a.Text, "ACE-789 Add GitHub unfurls")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Branch", "Commit", "Event", "Attempt"})
+	assert.Equal(t, a.Fields[0].Value, ":x: failure")
+	assert.Equal(t, a.Fields[1].Value, "11m30s")
+	assert.Equal(t, a.Fields[3].Value, "a1b2c3d")
+}
+
+func TestGitHubVisibility(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", githubAPI("repo"),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-repo.json")))
+	httpmock.RegisterResponder("GET", githubAPI("issue", 12),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("github-issue-12.json")))
+
+	uu := &Unfurl{
+		GitHub: gu.GitHub,
+		Slack:  &fakeSlack{},
+		Config: &utils.Config{VisibilityPolicy: VisibilityPolicyRestricted},
+	}
+
+	a, err := uu.sharedWith("U123", "C123").githubLink(githubURL("/issues/12"))
+	assert.NilError(t, err)
+	assert.Equal(t, a.Title, ":lock: Restricted")
+}
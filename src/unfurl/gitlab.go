@@ -0,0 +1,524 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/gitlab"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+const (
+	GitLabIcon = "https://about.gitlab.com/images/press/logo/png/gitlab-icon-rgb.png"
+
+	GitLabURLMergeRequestType = "merge_requests"
+	GitLabURLIssueType        = "issues"
+	GitLabURLCommitType       = "commit"
+	GitLabURLBlobType         = "blob"
+	GitLabURLPipelineType     = "pipelines"
+	GitLabURLJobType          = "jobs"
+	GitLabURLUnknownType      = "unknown"
+)
+
+var (
+	// gitlabPath matches links to a resource of a project. Projects may be
+	// in nested groups, and resources are after a /-/ separator in newer
+	// versions of GitLab.
+	gitlabPath = regexp.MustCompile(`^/(.+?)/(?:-/)?(merge_requests|issues|commit|blob|pipelines|jobs)/(.+)$`)
+
+	gitlabResourcePaths = map[string]*regexp.Regexp{
+		GitLabURLMergeRequestType: regexp.MustCompile(`^([0-9]+)(?:/.*)?$`),
+		GitLabURLIssueType:        regexp.MustCompile(`^([0-9]+)(?:/.*)?$`),
+		GitLabURLCommitType:       regexp.MustCompile(`^([0-9a-f]{7,40})(?:/.*)?$`),
+		GitLabURLBlobType:         regexp.MustCompile(`^([^/]+)/(.+)$`),
+		GitLabURLPipelineType:     regexp.MustCompile(`^([0-9]+)(?:/.*)?$`),
+		GitLabURLJobType:          regexp.MustCompile(`^([0-9]+)(?:/.*)?$`),
+	}
+)
+
+// gitlabLinkType returns the type of GitLab link, the project path and the
+// matches of the resource, e.g. the merge request IID.
+func gitlabLinkType(URL *url.URL) (string, string, []string) {
+	m := gitlabPath.FindStringSubmatch(URL.Path)
+	if m == nil {
+		return GitLabURLUnknownType, "", []string{}
+	}
+
+	resource := gitlabResourcePaths[m[2]].FindStringSubmatch(m[3])
+	if resource == nil {
+		return GitLabURLUnknownType, "", []string{}
+	}
+
+	return m[2], m[1], resource
+}
+
+// gitlabLink returns a Slack Attachment for GitLab links
+func (u *Unfurl) gitlabLink(URL *url.URL) (slack.Attachment, error) {
+	ctx := context.Background()
+
+	linkType, project, matches := gitlabLinkType(URL)
+	if linkType == GitLabURLUnknownType {
+		return slack.Attachment{}, errors.New("gitlab link not supported")
+	}
+
+	return u.publicOnlyLink(func() (bool, error) {
+		p, err := u.GitLab.Project(ctx, project)
+
+		return p.Public(), err
+	}, func() (slack.Attachment, error) {
+		switch linkType {
+		case GitLabURLCommitType:
+			return u.gitlabCommitLink(ctx, project, matches[1])
+		case GitLabURLBlobType:
+			return u.gitlabBlobLink(ctx, URL, project, matches[1], matches[2])
+		}
+
+		id, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return slack.Attachment{}, err
+		}
+
+		switch linkType {
+		case GitLabURLMergeRequestType:
+			return u.gitlabMRLink(ctx, project, id)
+		case GitLabURLIssueType:
+			return u.gitlabIssueLink(ctx, project, id)
+		case GitLabURLPipelineType:
+			return u.gitlabPipelineLink(ctx, project, id)
+		}
+
+		return u.gitlabJobLink(ctx, project, id)
+	})
+}
+
+// gitlabMRLink returns a Slack Attachment for GitLab merge request links, laid
+// out like Bitbucket pull requests.
+func (u *Unfurl) gitlabMRLink(ctx context.Context, project string, iid int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	mr, err := u.GitLab.MergeRequest(ctx, project, iid)
+	if err != nil {
+		return attachement, err
+	}
+
+	fields := []slack.AttachmentField{
+		{
+			Title: "MR State",
+			Value: mr.StateName(),
+			Short: true,
+		},
+	}
+	fields = append(fields, u.bitbucketBuildFields(gitlabStatusList(mr.HeadPipeline))...)
+
+	if approvals, err := u.GitLab.Approvals(ctx, project, iid); err != nil {
+		u.Logger.WithError(err).WithField("mr", fmt.Sprintf("%s!%d", project, iid)).Warn("Failed to get merge request approvals")
+	} else {
+		fields = append(
+			fields,
+			slack.AttachmentField{
+				Title: "Reviewers",
+				Value: approvals.ReviewedBy(),
+				Short: true,
+			}, slack.AttachmentField{
+				Title: "Review Status",
+				Value: approvals.ReviewState(true),
+				Short: true,
+			},
+		)
+	}
+
+	if stat, err := u.GitLab.DiffStat(ctx, project, iid); err != nil {
+		u.Logger.WithError(err).WithField("mr", fmt.Sprintf("%s!%d", project, iid)).Warn("Failed to get merge request diff statistics")
+	} else {
+		files, more := stat.Files, stat.Truncated
+		if n, m, err := mr.FilesChanged(); err == nil {
+			files, more = n, m
+		}
+
+		// Lines are only shown when all diffs could be counted
+		if stat.Truncated {
+			fields = append(fields, slack.AttachmentField{Title: "Changes", Value: filesChanged(files, more), Short: true})
+		} else {
+			fields = append(fields, changesField(files, more, stat.Additions, stat.Deletions))
+		}
+	}
+
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(mr.Title, mr.SourceBranch)); ok {
+		fields = append(fields, field)
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(mr.CreatedAt.Unix()))
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab"
+	attachement.AuthorName = mr.Author.Name
+	attachement.AuthorLink = mr.Author.WebURL
+	attachement.AuthorIcon = mr.Author.AvatarURL
+	attachement.Title = fmt.Sprintf("!%d %s", mr.IID, mr.Title)
+	attachement.TitleLink = mr.WebURL
+	attachement.Text = mr.Description
+	attachement.Fields = fields
+
+	return attachement, nil
+}
+
+// gitlabIssueLink returns a Slack Attachment for GitLab issue links
+func (u *Unfurl) gitlabIssueLink(ctx context.Context, project string, iid int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	issue, err := u.GitLab.Issue(ctx, project, iid)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "State",
+			Value: strings.ToUpper(issue.State),
+			Short: true,
+		},
+	}
+
+	if len(issue.Assignees) > 0 {
+		assignees := make([]string, len(issue.Assignees))
+		for i, a := range issue.Assignees {
+			assignees[i] = a.Name
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Assignees",
+			Value: strings.Join(assignees, ", "),
+			Short: true,
+		})
+	}
+
+	if len(issue.Labels) > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Labels",
+			Value: strings.Join(issue.Labels, ", "),
+			Short: true,
+		})
+	}
+
+	if issue.Milestone != nil {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Milestone",
+			Value: issue.Milestone.Title,
+			Short: true,
+		})
+	}
+
+	if issue.DueDate != "" {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Due Date",
+			Value: issue.DueDate,
+			Short: true,
+		})
+	}
+
+	if issue.UserNotesCount > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Comments",
+			Value: formatNumber(issue.UserNotesCount),
+			Short: true,
+		})
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(issue.CreatedAt.Unix()))
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab"
+	attachement.AuthorName = issue.Author.Name
+	attachement.AuthorLink = issue.Author.WebURL
+	attachement.AuthorIcon = issue.Author.AvatarURL
+	attachement.Title = fmt.Sprintf("#%d %s", issue.IID, issue.Title)
+	attachement.TitleLink = issue.WebURL
+	attachement.Text = issue.Description
+
+	return attachement, nil
+}
+
+// gitlabCommitLink returns a Slack Attachment for GitLab commit links, laid
+// out like Bitbucket commits.
+func (u *Unfurl) gitlabCommitLink(ctx context.Context, project string, sha string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	commit, err := u.GitLab.Commit(ctx, project, sha)
+	if err != nil {
+		return attachement, err
+	}
+
+	lines := strings.SplitN(commit.Message, "\n", 2)
+
+	attachement.Ts = json.Number(fmt.Sprint(commit.AuthoredDate.Unix()))
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab"
+	attachement.AuthorName = commit.AuthorName
+	attachement.Title = lines[0]
+	attachement.TitleLink = commit.WebURL
+	if len(lines) > 1 {
+		attachement.Text = strings.TrimSpace(lines[1])
+	}
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Commit",
+			Value: commit.ShortID,
+			Short: true,
+		},
+	}
+	attachement.Fields = append(attachement.Fields, u.bitbucketBuildFields(gitlabStatusList(commit.LastPipeline))...)
+	attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+		Title: "Changes",
+		Value: fmt.Sprintf("+%s −%s", formatNumber(commit.Stats.Additions), formatNumber(commit.Stats.Deletions)),
+		Short: true,
+	})
+	if field, ok := u.jiraIssuesField(jira.IssueKeys(commit.Message)); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	return attachement, nil
+}
+
+// gitlabBlobLink returns a Slack Attachment for links to a file, with the
+// lines selected in the link fragment, e.g. #L10-20.
+func (u *Unfurl) gitlabBlobLink(ctx context.Context, URL *url.URL, project string, ref string, path string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	title := fmt.Sprintf("%s in %s@%s", path, project, ref)
+
+	start, end := blobLineRange(URL.Fragment)
+	if start > 0 {
+		// Files too large to read are unfurled without an excerpt
+		text, err := u.GitLab.RawFile(ctx, project, path, ref)
+		if err != nil && !errors.Is(err, gitlab.ErrResponseTooLarge) {
+			return attachement, err
+		}
+
+		if start == end {
+			title = fmt.Sprintf("%s:%d in %s@%s", path, start, project, ref)
+		} else {
+			title = fmt.Sprintf("%s:%d-%d in %s@%s", path, start, end, project, ref)
+		}
+
+		if excerpt := blobExcerpt(text, start, end); excerpt != "" {
+			attachement.Text = fmt.Sprintf("```\n%s\n```", excerpt)
+		}
+	}
+
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab"
+	attachement.Title = title
+	attachement.TitleLink = URL.String()
+
+	return attachement, nil
+}
+
+// gitlabPipelineLink returns a Slack Attachment for GitLab pipeline links,
+// with the status of each stage.
+func (u *Unfurl) gitlabPipelineLink(ctx context.Context, project string, id int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	pipeline, err := u.GitLab.Pipeline(ctx, project, id)
+	if err != nil {
+		return attachement, err
+	}
+
+	jobs, err := u.GitLab.PipelineJobs(ctx, project, id)
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Status",
+			Value: fmt.Sprintf("%s %s", statusEmoji(gitlabState(pipeline.Status, false)), pipeline.Status),
+			Short: true,
+		},
+		{
+			Title: "Duration",
+			Value: (time.Duration(pipeline.Duration) * time.Second).String(),
+			Short: true,
+		},
+		{
+			Title: "Ref",
+			Value: pipeline.Ref,
+			Short: true,
+		},
+		{
+			Title: "Commit",
+			Value: gitlabShortSHA(pipeline.SHA),
+			Short: true,
+		},
+		{
+			Title: "Source",
+			Value: pipeline.Source,
+			Short: true,
+		},
+	}
+
+	if stages := gitlab.Stages(jobs); len(stages) > 0 {
+		lines := make([]string, len(stages))
+		for i, stage := range stages {
+			lines[i] = gitlabStageLine(stage)
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Stages",
+			Value: strings.Join(lines, "\n"),
+		})
+	}
+
+	if pipeline.User != nil {
+		attachement.AuthorName = pipeline.User.Name
+		attachement.AuthorLink = pipeline.User.WebURL
+		attachement.AuthorIcon = pipeline.User.AvatarURL
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(pipeline.CreatedAt.Unix()))
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab CI"
+	attachement.Title = fmt.Sprintf("%s: Pipeline #%d", project, pipeline.ID)
+	attachement.TitleLink = pipeline.WebURL
+
+	return attachement, nil
+}
+
+// gitlabStageLine returns a stage as a single line with its failed jobs,
+// e.g. ":x: test – 1 of 3 jobs failed: <https://gitlab/job/1|unit>"
+func gitlabStageLine(stage gitlab.Stage) string {
+	st := bitbucket.StatusList{}
+	var failed []string
+	for _, job := range stage.Jobs {
+		st.Values = append(st.Values, bitbucket.Status{State: gitlabState(job.Status, job.AllowFailure)})
+		if job.Status == gitlab.StatusFailed && !job.AllowFailure {
+			failed = append(failed, fmt.Sprintf("<%s|%s>", job.WebURL, job.Name))
+		}
+	}
+
+	line := fmt.Sprintf("%s %s", statusEmoji(st.State()), stage.Name)
+	if len(failed) > 0 {
+		line += fmt.Sprintf(" – %d of %d jobs failed: %s", len(failed), len(stage.Jobs), strings.Join(failed, ", "))
+	}
+
+	return line
+}
+
+// gitlabJobLink returns a Slack Attachment for GitLab CI job links
+func (u *Unfurl) gitlabJobLink(ctx context.Context, project string, id int) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	job, err := u.GitLab.Job(ctx, project, id)
+	if err != nil {
+		return attachement, err
+	}
+
+	status := job.Status
+	if job.FailureReason != "" {
+		status = fmt.Sprintf("%s (%s)", status, strings.ReplaceAll(job.FailureReason, "_", " "))
+	}
+
+	attachement.Fields = []slack.AttachmentField{
+		{
+			Title: "Status",
+			Value: fmt.Sprintf("%s %s", statusEmoji(gitlabState(job.Status, job.AllowFailure)), status),
+			Short: true,
+		},
+		{
+			Title: "Duration",
+			Value: (time.Duration(job.Duration) * time.Second).Round(time.Second).String(),
+			Short: true,
+		},
+		{
+			Title: "Stage",
+			Value: job.Stage,
+			Short: true,
+		},
+		{
+			Title: "Ref",
+			Value: job.Ref,
+			Short: true,
+		},
+	}
+
+	if job.Pipeline != nil {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "Pipeline",
+			Value: fmt.Sprintf("<%s|#%d>", job.Pipeline.WebURL, job.Pipeline.ID),
+			Short: true,
+		})
+	}
+
+	if job.User != nil {
+		attachement.AuthorName = job.User.Name
+		attachement.AuthorLink = job.User.WebURL
+		attachement.AuthorIcon = job.User.AvatarURL
+	}
+
+	attachement.Ts = json.Number(fmt.Sprint(job.CreatedAt.Unix()))
+	attachement.FooterIcon = GitLabIcon
+	attachement.Footer = "GitLab CI"
+	attachement.Title = fmt.Sprintf("%s #%d", job.Name, job.ID)
+	attachement.TitleLink = job.WebURL
+
+	return attachement, nil
+}
+
+// gitlabStatusList returns the pipeline of a merge request or commit as a
+// Bitbucket build status, so it is shown like builds.
+func gitlabStatusList(pipeline *gitlab.PipelineRef) bitbucket.StatusList {
+	st := bitbucket.StatusList{IsLastPage: true}
+	if pipeline == nil {
+		return st
+	}
+
+	st.Values = []bitbucket.Status{
+		{
+			State:       gitlabState(pipeline.Status, false),
+			Key:         "pipeline",
+			Name:        fmt.Sprintf("Pipeline #%d", pipeline.ID),
+			URL:         pipeline.WebURL,
+			Description: pipeline.Status,
+		},
+	}
+	st.Size = 1
+
+	return st
+}
+
+// gitlabState returns the Bitbucket build state of a pipeline or job status.
+// Failed jobs that are allowed to fail do not fail their stage.
+func gitlabState(status string, allowFailure bool) string {
+	switch status {
+	case gitlab.StatusSuccess:
+		return bitbucket.StatusSuccess
+	case gitlab.StatusFailed:
+		if allowFailure {
+			return bitbucket.StatusSuccess
+		}
+
+		return bitbucket.StatusFailed
+	case gitlab.StatusCanceled:
+		return bitbucket.StatusCancelled
+	case gitlab.StatusCreated, gitlab.StatusWaitingForResource, gitlab.StatusPreparing,
+		gitlab.StatusPending, gitlab.StatusRunning, gitlab.StatusScheduled:
+		return bitbucket.StatusInProgress
+	}
+
+	return bitbucket.StatusUnknown
+}
+
+// gitlabShortSHA returns the abbreviated commit hash
+func gitlabShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+
+	return sha
+}
@@ -0,0 +1,210 @@
+package unfurl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/gitlab"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"gotest.tools/assert"
+)
+
+const (
+	gitlabServer  = "gitlab.corp.org"
+	gitlabProject = "my-group/sub-group/my-project"
+	gitlabAPI     = "/api/v4/projects/my-group%2Fsub-group%2Fmy-project"
+)
+
+// gitlabStub returns an Unfurl reading from a local GitLab API, which serves
+// testdata files by escaped path and query.
+func gitlabStub(t *testing.T, routes map[string]string) *Unfurl {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			route += "?" + r.URL.RawQuery
+		}
+
+		body, ok := routes[route]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"404 Not Found"}`))
+			return
+		}
+
+		if strings.HasSuffix(body, ".json") {
+			body = utils.ReadTestdataFile(body)
+		}
+
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return &Unfurl{
+		GitLab: &gitlab.Client{Server: gitlabServer, APIURL: srv.URL + gitlab.APIPrefix, Token: "my-token"},
+	}
+}
+
+// gitlabURL returns a GitLab link in the test project
+func gitlabURL(path string) *url.URL {
+	URL, _ := url.Parse("https://" + gitlabServer + "/" + gitlabProject + path)
+
+	return URL
+}
+
+func TestGitLabLinkType(t *testing.T) {
+	typeLinks := map[string][]string{
+		GitLabURLMergeRequestType: {
+			"/my-group/my-project/-/merge_requests/5",
+			"/my-group/sub-group/my-project/-/merge_requests/5/diffs",
+			"/my-group/my-project/merge_requests/5",
+		},
+		GitLabURLIssueType: {
+			"/my-group/my-project/-/issues/3",
+		},
+		GitLabURLCommitType: {
+			"/my-group/my-project/-/commit/c2646bb9a628c4fd935e6e0e7bca2da01afecde7",
+		},
+		GitLabURLBlobType: {
+			"/my-group/my-project/-/blob/main/src/main.go",
+		},
+		GitLabURLPipelineType: {
+			"/my-group/my-project/-/pipelines/101",
+			"/my-group/my-project/-/pipelines/101/failures",
+		},
+		GitLabURLJobType: {
+			"/my-group/my-project/-/jobs/903",
+		},
+		GitLabURLUnknownType: {
+			"/my-group/my-project",
+			"/my-group/my-project/-/merge_requests",
+			"/my-group/my-project/-/commit/not-a-sha",
+		},
+	}
+
+	for expected, links := range typeLinks {
+		for _, link := range links {
+			URL, err := url.Parse("https://" + gitlabServer + link)
+			assert.NilError(t, err)
+
+			actual, _, _ := gitlabLinkType(URL)
+			assert.Equal(t, actual, expected, link)
+		}
+	}
+
+	URL, _ := url.Parse("https://" + gitlabServer + "/my-group/sub-group/my-project/-/merge_requests/5")
+	_, project, matches := gitlabLinkType(URL)
+	assert.Equal(t, project, gitlabProject)
+	assert.Equal(t, matches[1], "5")
+}
+
+func TestGitLabMRLink(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/merge_requests/5":                           "gitlab-merge-request-5.json",
+		gitlabAPI + "/merge_requests/5/approvals":                 "gitlab-mr-approvals-5.json",
+		gitlabAPI + "/merge_requests/5/diffs?page=1&per_page=100": "gitlab-mr-diffs-5.json",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/merge_requests/5"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "!5 ACE-321 Add GitLab unfurls")
+	assert.Equal(t, a.AuthorName, "Jane Doe")
+	assert.Equal(t, a.Footer, "GitLab")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"MR State", "Build Status", "Reviewers", "Review Status", "Changes"})
+	assert.Equal(t, a.Fields[0].Value, "OPEN")
+	assert.Equal(t, a.Fields[1].Value, "FAILED")
+	assert.Equal(t, a.Fields[2].Value, "asmith (APPROVED)")
+	assert.Equal(t, a.Fields[3].Value, ":disappointed: Unapproved, 1 approval left")
+	assert.Equal(t, a.Fields[4].Value, "2 files, +4 −1")
+}
+
+func TestGitLabIssueLink(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/issues/3": "gitlab-issue-3.json",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/issues/3"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "#3 Pipeline links are not unfurled")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"State", "Assignees", "Labels", "Milestone", "Due Date", "Comments"})
+	assert.Equal(t, a.Fields[0].Value, "OPENED")
+	assert.Equal(t, a.Fields[2].Value, "bug, ci")
+}
+
+func TestGitLabCommitLink(t *testing.T) {
+	sha := "c2646bb9a628c4fd935e6e0e7bca2da01afecde7"
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/repository/commits/" + sha + "?stats=true": "gitlab-commit.json",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/commit/" + sha))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "ACE-321 Unfurl commits")
+	assert.Equal(t, a.Text, "Commits show their pipeline.")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Commit", "Build Status", "Changes"})
+	assert.Equal(t, a.Fields[0].Value, "c2646bb9")
+	assert.Equal(t, a.Fields[1].Value, "SUCCESSFUL")
+	assert.Equal(t, a.Fields[2].Value, "+15 −3")
+}
+
+func TestGitLabBlobLink(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/repository/files/src%2Fmain.go/raw?ref=main": "package main\n\nfunc main() {\n}\n",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/blob/main/src/main.go#L3-4"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "src/main.go:3-4 in my-group/sub-group/my-project@main")
+	assert.Equal(t, a.Text, "```\nfunc main() {\n}\n```")
+}
+
+func TestGitLabPipelineLink(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/pipelines/101":                   "gitlab-pipeline-101.json",
+		gitlabAPI + "/pipelines/101/jobs?per_page=100": "gitlab-pipeline-jobs-101.json",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/pipelines/101"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "my-group/sub-group/my-project: Pipeline #101")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Ref", "Commit", "Source", "Stages"})
+	assert.Equal(t, a.Fields[0].Value, ":x: failed")
+	assert.Equal(t, a.Fields[1].Value, "8m30s")
+	assert.Equal(t, a.Fields[5].Value, ":white_check_mark: build\n"+
+		":x: test – 1 of 3 jobs failed: <https://gitlab.corp.org/my-group/sub-group/my-project/-/jobs/903|unit>\n"+
+		":grey_question: deploy")
+}
+
+func TestGitLabJobLink(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI + "/jobs/903": "gitlab-job-903.json",
+	})
+
+	a, err := u.gitlabLink(gitlabURL("/-/jobs/903"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, a.Title, "unit #903")
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Stage", "Ref", "Pipeline"})
+	assert.Equal(t, a.Fields[0].Value, ":x: failed (script failure)")
+	assert.Equal(t, a.Fields[1].Value, "2m5s")
+}
+
+func TestGitLabVisibility(t *testing.T) {
+	u := gitlabStub(t, map[string]string{
+		gitlabAPI:               "gitlab-project.json",
+		gitlabAPI + "/issues/3": "gitlab-issue-3.json",
+	})
+	u.Slack = &fakeSlack{}
+	u.Config = &utils.Config{VisibilityPolicy: VisibilityPolicyRestricted}
+
+	a, err := u.sharedWith("U123", "C123").gitlabLink(gitlabURL("/-/issues/3"))
+	assert.NilError(t, err)
+	assert.Equal(t, a.Title, ":lock: Restricted")
+}
@@ -0,0 +1,155 @@
+package unfurl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// SlackClient is the part of the Slack API used for unfurling links and
+// responding to interactions with them.
+type SlackClient interface {
+	OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+	PostEphemeral(channelID, userID string, options ...slack.MsgOption) (string, error)
+	GetConversationInfo(channelID string, includeLocale bool) (*slack.Channel, error)
+	GetUserInfo(user string) (*slack.User, error)
+}
+
+// actionValue is the payload stored in the value of an interactive unfurl
+// action and in the private metadata of the modals it opens.
+type actionValue struct {
+	// Link is the shared link the unfurl belongs to, and Domain the domain
+	// Slack reported for it. They are filled in by Links() so that providers
+	// do not need to know about them.
+	Link   string            `json:"link,omitempty"`
+	Domain string            `json:"domain,omitempty"`
+	Params map[string]string `json:"params"`
+}
+
+// encodeActionValue returns the action value for a set of action parameters.
+func encodeActionValue(params map[string]string) string {
+	b, _ := json.Marshal(actionValue{Params: params})
+	return string(b)
+}
+
+// decodeActionValue parses an action value created by encodeActionValue.
+func decodeActionValue(value string) (actionValue, error) {
+	var v actionValue
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return v, fmt.Errorf("invalid action value: %w", err)
+	}
+
+	return v, nil
+}
+
+// withActionLink stores the shared link and its domain in all interactive
+// actions of the attachment so the unfurl can be found again when an action is
+// clicked.
+func withActionLink(attachement slack.Attachment, link string, domain string) slack.Attachment {
+	for i, action := range attachement.Actions {
+		if action.URL != "" || action.Value == "" {
+			continue
+		}
+
+		v, err := decodeActionValue(action.Value)
+		if err != nil {
+			continue
+		}
+
+		v.Link = link
+		v.Domain = domain
+		b, _ := json.Marshal(v)
+		attachement.Actions[i].Value = string(b)
+	}
+
+	return attachement
+}
+
+// Interaction handles a Slack interaction with an unfurled link, such as a
+// button click or a modal submission.
+func (u *Unfurl) Interaction(cb slack.InteractionCallback) error {
+	switch cb.Type {
+	case slack.InteractionTypeInteractionMessage:
+		if len(cb.ActionCallback.AttachmentActions) == 0 {
+			return fmt.Errorf("interaction %s has no actions", cb.CallbackID)
+		}
+
+		action := cb.ActionCallback.AttachmentActions[0]
+		value, err := decodeActionValue(action.Value)
+		if err != nil {
+			return err
+		}
+
+		switch cb.CallbackID {
+		case JenkinsInputCallbackID:
+			return u.jenkinsInputAction(cb, action.Name, value)
+		case JenkinsBuildCallbackID:
+			return u.jenkinsBuildAction(cb, action.Name, value)
+		case BitbucketPullRequestCallbackID:
+			return u.bitbucketPRAction(cb, action.Name, value)
+		}
+
+	case slack.InteractionTypeViewSubmission:
+		value, err := decodeActionValue(cb.View.PrivateMetadata)
+		if err != nil {
+			return err
+		}
+
+		switch cb.View.CallbackID {
+		case JenkinsInputCallbackID:
+			return u.jenkinsInputSubmission(cb, value)
+		}
+	}
+
+	return fmt.Errorf("unsupported interaction %s for callback %s", cb.Type, cb.CallbackID)
+}
+
+// reply sends a message only visible to the user that interacted with the
+// unfurl.
+func (u *Unfurl) reply(cb slack.InteractionCallback, channel string, text string) {
+	if channel == "" {
+		channel = cb.User.ID
+	}
+
+	if _, err := u.Slack.PostEphemeral(channel, cb.User.ID, slack.MsgOptionText(text, false)); err != nil {
+		u.Logger.WithError(err).WithField("user", cb.User.ID).Error("Failed to post Slack ephemeral message")
+	}
+}
+
+// refresh unfurls a link again and replaces the existing unfurl in the
+// message it was shared in. The link is unfurled for the user who interacted
+// with it and the channel, so the visibility policy applies as when it was
+// shared. Links that are now hidden by the policy are left as they are.
+func (u *Unfurl) refresh(cb slack.InteractionCallback, channel string, ts string, value actionValue) error {
+	if channel == "" || ts == "" || value.Link == "" {
+		return nil
+	}
+
+	URL, err := url.Parse(value.Link)
+	if err != nil {
+		return err
+	}
+
+	// Unfurls from before the domain was stored only have the link
+	domain := value.Domain
+	if domain == "" {
+		domain = URL.Host
+	}
+
+	attachement, err := u.sharedWith(cb.User.ID, channel).link(URL, domain)
+	if errors.Is(err, errRestricted) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	unfurls := map[string]slack.Attachment{value.Link: withActionLink(attachement, value.Link, domain)}
+	_, _, err = u.Slack.PostMessage(channel, slack.MsgOptionUnfurl(ts, unfurls))
+
+	return err
+}
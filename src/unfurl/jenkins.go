@@ -5,71 +5,95 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
 	"strconv"
 	"time"
 
+	"github.com/bndr/gojenkins"
 	"github.com/slack-go/slack"
 	"github.com/xeonx/timeago"
 )
 
 const (
 	JenkinsURLBuildType  = "build"
+	JenkinsURLJobType    = "job"
 	JenkinsURLUknownType = "unknown"
 )
 
-// jenkinsLinkType returns the type of Bitbucket link and the matches
-func (u *Unfurl) jenkinsLinkType(URL *url.URL) (string, []string) {
-	// /job/k8s/job/tf-dockyard/job/master/821/
-	var isBuild = regexp.MustCompile(`^/job/([^/]+)/job/([^/]+)/job/([^/]+)/([0-9]+)`)
+// jenkinsLinkType returns the type of Jenkins link and the job and build
+func jenkinsLinkType(URL *url.URL) (string, jenkinsPath) {
+	p, ok := parseJenkinsPath(URL)
 
-	if isBuild.MatchString(URL.Path) {
-		return JenkinsURLBuildType, isBuild.FindStringSubmatch(URL.Path)
+	switch {
+	case !ok:
+		return JenkinsURLUknownType, p
+	case p.Build != "":
+		return JenkinsURLBuildType, p
 	}
 
-	return JenkinsURLUknownType, []string{}
+	return JenkinsURLJobType, p
 }
 
 // jenkinsLink returns a slack.Attachment for a Jenkins link
 func (u *Unfurl) jenkinsLink(URL *url.URL) (slack.Attachment, error) {
-	attachement := slack.Attachment{}
+	linkType, p := jenkinsLinkType(URL)
 
-	// Parse what type of link this is
-	linkType, matches := u.jenkinsLinkType(URL)
-	fmt.Printf("linkType=%s matches=%s", linkType, matches)
 	switch linkType {
 	case JenkinsURLBuildType:
-		project := matches[1]
-		repo := matches[2]
-		branch := matches[3]
-		buildID, err := strconv.Atoi(matches[4])
+		return u.jenkinsBuildLink(p)
+
+	case JenkinsURLJobType:
+		return u.jenkinsJobLink(p)
+
+	default:
+		return slack.Attachment{}, errors.New("jenkins link not supported")
+	}
+}
+
+// jenkinsBuild returns the build of a Jenkins link, resolving permalinks such
+// as lastBuild to the build they currently point to.
+func (u *Unfurl) jenkinsBuild(ctx context.Context, p jenkinsPath) (*gojenkins.Build, error) {
+	job, err := u.Jenkins.GetJob(ctx, p.JobName())
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.IsAlias() {
+		number, err := strconv.ParseInt(p.Build, 10, 64)
 		if err != nil {
-			return attachement, err
+			return nil, err
 		}
 
-		fmt.Printf("project=%s repo=%s branch=%s buildID=%d", project, repo, branch, buildID)
+		return job.GetBuild(ctx, number)
+	}
 
-		return u.jenkinsBuildLink(project, repo, branch, buildID)
+	builds := map[string]gojenkins.JobBuild{
+		"firstBuild":            job.Raw.FirstBuild,
+		"lastBuild":             job.Raw.LastBuild,
+		"lastCompletedBuild":    job.Raw.LastCompletedBuild,
+		"lastFailedBuild":       job.Raw.LastFailedBuild,
+		"lastStableBuild":       job.Raw.LastStableBuild,
+		"lastSuccessfulBuild":   job.Raw.LastSuccessfulBuild,
+		"lastUnstableBuild":     job.Raw.LastUnstableBuild,
+		"lastUnsuccessfulBuild": job.Raw.LastUnsuccessfulBuild,
+	}
 
-	default:
-		return attachement, errors.New("jenkins link not supported")
+	build := builds[p.Build]
+	if build.Number == 0 {
+		return nil, fmt.Errorf("jenkins job %s has no %s", p.JobName(), p.Build)
 	}
+
+	return job.GetBuild(ctx, build.Number)
 }
 
-func (u *Unfurl) jenkinsBuildLink(project, repo, branch string, buildNumber int) (slack.Attachment, error) {
+func (u *Unfurl) jenkinsBuildLink(p jenkinsPath) (slack.Attachment, error) {
 	attachement := slack.Attachment{}
 	ctx := context.Background()
 
-	jobName := fmt.Sprintf("%s/job/%s/job/%s", project, repo, branch)
-	fmt.Printf("jobName=%s", jobName)
-
-	build, err := u.Jenkins.GetBuild(ctx, jobName, int64(buildNumber))
+	build, err := u.jenkinsBuild(ctx, p)
 	if err != nil {
 		return attachement, err
 	}
 
-	fmt.Printf("build=%+v", build.Raw)
-
 	// Build result
 	result := build.GetResult()
 	if result == "" {
@@ -94,7 +118,34 @@ func (u *Unfurl) jenkinsBuildLink(project, repo, branch string, buildNumber int)
 	// attachement.Text = build.Raw.ChangeSet.Items[0].Msg
 
 	// check if jenkins build is waiting for input
+	var inputs []jenkinsInput
 	if build.Raw.Building {
+		inputs, err = u.jenkinsPendingInputs(ctx, build)
+		if err != nil {
+			u.Logger.WithError(err).WithField("build", build.Base).Warn("Failed to get pending Jenkins inputs")
+		}
+	}
+
+	run, err := u.jenkinsDescribe(ctx, build)
+	if err != nil {
+		u.Logger.WithError(err).WithField("build", build.Base).Warn("Failed to get Jenkins pipeline stages")
+	}
+
+	// test results tell flaky tests from compile errors in failed builds
+	failedTests := 5
+	if u.Config != nil {
+		failedTests = u.Config.JenkinsFailedTests
+	}
+
+	var report *jenkinsTestReport
+	if result == gojenkins.RESULT_STATUS_FAILURE || result == "UNSTABLE" {
+		report, err = u.jenkinsTestResults(ctx, build)
+		if err != nil {
+			u.Logger.WithError(err).WithField("build", build.Base).Warn("Failed to get Jenkins test results")
+		}
+	}
+
+	if len(inputs) > 0 {
 		attachement.Text = "Waiting for input"
 	} else {
 		attachement.Text = fmt.Sprintf("%s\n%s", result, duration.String())
@@ -117,8 +168,28 @@ func (u *Unfurl) jenkinsBuildLink(project, repo, branch string, buildNumber int)
 			Short: true,
 		},
 	}
+	if field, ok := jenkinsStagesField(run); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+	attachement.Fields = append(attachement.Fields, jenkinsTestFields(report, failedTests)...)
+
+	// the console log of failed builds is shown inline or behind a button
+	consoleLog := ""
+	if u.Config != nil && result == gojenkins.RESULT_STATUS_FAILURE {
+		consoleLog = u.Config.JenkinsConsoleLog
+	}
+	if consoleLog == JenkinsConsoleInline {
+		if field, ok := u.jenkinsConsoleField(ctx, build); ok {
+			attachement.Fields = append(attachement.Fields, field)
+		}
+	}
+	attachement.Fields = append(attachement.Fields, jenkinsInputFields(inputs)...)
+
+	attachement.CallbackID = JenkinsBuildCallbackID
+	if len(inputs) > 0 {
+		attachement.CallbackID = JenkinsInputCallbackID
+	}
 
-	attachement.CallbackID = "jenkins_build"
 	attachement.Actions = []slack.AttachmentAction{
 		{
 			Name: "build log",
@@ -133,6 +204,10 @@ func (u *Unfurl) jenkinsBuildLink(project, repo, branch string, buildNumber int)
 			URL:  build.GetUrl() + "changes",
 		},
 	}
+	if consoleLog == JenkinsConsoleButton {
+		attachement.Actions = append(attachement.Actions, jenkinsConsoleAction(build))
+	}
+	attachement.Actions = append(attachement.Actions, jenkinsInputActions(build, inputs)...)
 
 	return attachement, nil
 }
@@ -0,0 +1,249 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// JenkinsBuildCallbackID is the callback ID for Jenkins build actions.
+	JenkinsBuildCallbackID = "jenkins_build"
+
+	jenkinsConsoleShow = "show log"
+
+	// Console logs are shown in the unfurl, behind a button opening a modal,
+	// or not at all.
+	JenkinsConsoleInline = "inline"
+	JenkinsConsoleButton = "button"
+	JenkinsConsoleOff    = "off"
+
+	// jenkinsConsoleModalLines is the number of lines shown in the console
+	// log modal. Slack limits the text of a section to 3000 characters.
+	jenkinsConsoleModalLines = 50
+	jenkinsConsoleModalChars = 2900
+
+	// jenkinsConsoleTailBytes is how much of the end of a console log is
+	// read, so that long logs are not downloaded in full.
+	jenkinsConsoleTailBytes = 64 << 10
+)
+
+var (
+	// jenkinsConsoleNote matches the hidden annotations Jenkins embeds in
+	// console logs, e.g. ESC[8mha:////4G...ESC[0m
+	jenkinsConsoleNote = regexp.MustCompile(`\x1b\[8mha:[^\x1b]*\x1b\[0m`)
+
+	// jenkinsConsoleANSI matches ANSI escape codes such as colors
+	jenkinsConsoleANSI = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+	// jenkinsConsoleTimestamp matches timestamps added by the Timestamper
+	// plugin, e.g. [2022-04-12T09:21:03.123Z] or 09:21:03, keeping the
+	// indentation of the line.
+	jenkinsConsoleTimestamp = regexp.MustCompile(`^(\[(\d{4}-\d{2}-\d{2}[T ])?\d{2}:\d{2}:\d{2}(\.\d+)?Z?\] |\d{2}:\d{2}:\d{2}  ?)`)
+)
+
+// jenkinsConsoleLines returns the lines of a console log without annotations,
+// ANSI codes, timestamps and the [Pipeline] lines of pipeline steps. Only the
+// last part of lines rewritten with carriage returns, like progress bars, is
+// kept.
+func jenkinsConsoleLines(log string) []string {
+	log = jenkinsConsoleNote.ReplaceAllString(log, "")
+	log = jenkinsConsoleANSI.ReplaceAllString(log, "")
+
+	var lines []string
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+
+		line = strings.TrimRight(jenkinsConsoleTimestamp.ReplaceAllString(line, ""), " \t")
+		if line == "" || strings.HasPrefix(line, "[Pipeline]") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// jenkinsConsoleTail returns the last limit lines of a console log. Up to
+// half of them are the last lines matching an error pattern, so the cause of
+// a failure is shown even when it is followed by cleanup output. Lines that
+// were left out are marked with an ellipsis.
+func jenkinsConsoleTail(lines []string, patterns []*regexp.Regexp, limit int) []string {
+	chosen := map[int]bool{}
+
+	matched := 0
+	for i := len(lines) - 1; i >= 0 && matched < limit/2; i-- {
+		for _, re := range patterns {
+			if re.MatchString(lines[i]) {
+				chosen[i] = true
+				matched++
+				break
+			}
+		}
+	}
+
+	for i := len(lines) - 1; i >= 0 && len(chosen) < limit; i-- {
+		chosen[i] = true
+	}
+
+	indexes := make([]int, 0, len(chosen))
+	for i := range chosen {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	tail := make([]string, 0, len(indexes)+1)
+	for n, i := range indexes {
+		if (n == 0 && i > 0) || (n > 0 && i > indexes[n-1]+1) {
+			tail = append(tail, "…")
+		}
+		tail = append(tail, lines[i])
+	}
+
+	return tail
+}
+
+// CompileJenkinsErrorPatterns compiles the configured console log error
+// patterns.
+func CompileJenkinsErrorPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Jenkins error pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+
+	return res, nil
+}
+
+// jenkinsConsoleText returns the end of the console log of a build. The size
+// of the log is read first, and only the last jenkinsConsoleTailBytes are
+// requested. The first line is left out when it may be cut off.
+func jenkinsConsoleText(ctx context.Context, build *gojenkins.Build) (string, error) {
+	var text string
+
+	req := gojenkins.NewAPIRequest("HEAD", build.Base+"/logText/progressiveText", nil)
+	rsp, err := build.Jenkins.Requester.Do(ctx, req, &text, map[string]string{"start": "0"})
+	if err != nil {
+		return "", err
+	}
+
+	size, err := strconv.ParseInt(rsp.Header.Get("X-Text-Size"), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the console log size: %w", err)
+	}
+
+	start := size - jenkinsConsoleTailBytes
+	if start < 0 {
+		start = 0
+	}
+
+	console, err := build.GetConsoleOutputFromIndex(ctx, start)
+	if err != nil {
+		return "", err
+	}
+
+	if start > 0 {
+		if i := strings.Index(console.Content, "\n"); i >= 0 {
+			return console.Content[i+1:], nil
+		}
+	}
+
+	return console.Content, nil
+}
+
+// jenkinsConsole returns the last lines of the console log of a build, with
+// lines matching the configured error patterns prioritised and secrets
+// redacted.
+func (u *Unfurl) jenkinsConsole(ctx context.Context, build *gojenkins.Build, limit int) (string, error) {
+	console, err := jenkinsConsoleText(ctx, build)
+	if err != nil {
+		return "", err
+	}
+
+	tail := jenkinsConsoleTail(jenkinsConsoleLines(console), u.JenkinsErrorPatterns, limit)
+
+	return u.redactor().String(strings.Join(tail, "\n")), nil
+}
+
+// jenkinsConsoleField returns the console log tail of a failed build
+func (u *Unfurl) jenkinsConsoleField(ctx context.Context, build *gojenkins.Build) (slack.AttachmentField, bool) {
+	log, err := u.jenkinsConsole(ctx, build, u.Config.JenkinsConsoleLines)
+	if err != nil {
+		u.Logger.WithError(err).WithField("build", build.Base).Warn("Failed to get Jenkins console log")
+		return slack.AttachmentField{}, false
+	}
+
+	if log == "" {
+		return slack.AttachmentField{}, false
+	}
+
+	return slack.AttachmentField{
+		Title: "Console Log",
+		Value: fmt.Sprintf("```\n%s\n```", log),
+		Short: false,
+	}, true
+}
+
+// jenkinsConsoleAction returns the button opening the console log modal
+func jenkinsConsoleAction(build *gojenkins.Build) slack.AttachmentAction {
+	return slack.AttachmentAction{
+		Name:  jenkinsConsoleShow,
+		Text:  ":mag: Show Log",
+		Type:  "button",
+		Value: encodeActionValue(map[string]string{"build": build.Base}),
+	}
+}
+
+// jenkinsBuildAction handles a click on one of the build buttons.
+func (u *Unfurl) jenkinsBuildAction(cb slack.InteractionCallback, name string, value actionValue) error {
+	if name != jenkinsConsoleShow {
+		return fmt.Errorf("unsupported jenkins build action %s", name)
+	}
+
+	build := &gojenkins.Build{Jenkins: u.Jenkins, Base: value.Params["build"]}
+
+	log, err := u.jenkinsConsole(context.Background(), build, jenkinsConsoleModalLines)
+	if err != nil {
+		u.reply(cb, cb.Channel.ID, fmt.Sprintf(":warning: Failed to get the console log: %s", err))
+		return err
+	}
+
+	_, err = u.Slack.OpenView(cb.TriggerID, jenkinsConsoleModal(log))
+
+	return err
+}
+
+// jenkinsConsoleModal returns a modal showing a console log tail. The start of
+// logs that are too long for Slack is cut off.
+func jenkinsConsoleModal(log string) slack.ModalViewRequest {
+	if r := []rune(log); len(r) > jenkinsConsoleModalChars {
+		log = "…" + string(r[len(r)-jenkinsConsoleModalChars:])
+	}
+	if log == "" {
+		log = "The console log is empty."
+	} else {
+		log = fmt.Sprintf("```\n%s\n```", log)
+	}
+
+	return slack.ModalViewRequest{
+		Type:  slack.VTModal,
+		Title: slack.NewTextBlockObject(slack.PlainTextType, "Console log", false, false),
+		Close: slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, log, false, false), nil, nil),
+		}},
+	}
+}
@@ -0,0 +1,223 @@
+package unfurl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+const jenkinsConsoleLog = "Started by user \x1b[8mha:////4AAAAlh+LCAAAAAAAAP9b\x1b[0mJane Doe\n" +
+	"[Pipeline] Start of Pipeline\n" +
+	"[2022-04-12T09:21:03.123Z] + go test ./...\n" +
+	"[2022-04-12T09:21:04.001Z] \x1b[32mok  \x1b[0m\tgithub.com/corp/dockyard/cluster\t0.012s\n" +
+	"[2022-04-12T09:21:05.337Z] --- FAIL: TestScaleUp (0.01s)\n" +
+	"[2022-04-12T09:21:05.337Z]     cluster_test.go:42: expected 3 nodes, got 2\n" +
+	"[2022-04-12T09:21:05.340Z] \x1b[31mFAIL\x1b[0m\tgithub.com/corp/dockyard/nodepool\t0.020s\n" +
+	"Downloading 10%\rDownloading 50%\rDownloading 100%\r\n" +
+	"09:21:06  Cleaning up workspace\n" +
+	"09:21:06  Using DB_PASSWORD=hunter2\n" +
+	"[Pipeline] }\n" +
+	"[Pipeline] // node\n" +
+	"ERROR: script returned exit code 1\n" +
+	"Finished: FAILURE\n"
+
+func TestJenkinsConsoleLines(t *testing.T) {
+	assert.DeepEqual(t, jenkinsConsoleLines(jenkinsConsoleLog), []string{
+		"Started by user Jane Doe",
+		"+ go test ./...",
+		"ok  \tgithub.com/corp/dockyard/cluster\t0.012s",
+		"--- FAIL: TestScaleUp (0.01s)",
+		"    cluster_test.go:42: expected 3 nodes, got 2",
+		"FAIL\tgithub.com/corp/dockyard/nodepool\t0.020s",
+		"Downloading 100%",
+		"Cleaning up workspace",
+		"Using DB_PASSWORD=hunter2",
+		"ERROR: script returned exit code 1",
+		"Finished: FAILURE",
+	})
+}
+
+func TestJenkinsConsoleTail(t *testing.T) {
+	lines := jenkinsConsoleLines(jenkinsConsoleLog)
+	patterns := []*regexp.Regexp{regexp.MustCompile(`(?i)\bfail(ed|ure)?\b`)}
+
+	t.Run("should prioritise lines matching error patterns", func(t *testing.T) {
+		assert.DeepEqual(t, jenkinsConsoleTail(lines, patterns, 6), []string{
+			"…",
+			"--- FAIL: TestScaleUp (0.01s)",
+			"…",
+			"FAIL\tgithub.com/corp/dockyard/nodepool\t0.020s",
+			"…",
+			"Cleaning up workspace",
+			"Using DB_PASSWORD=hunter2",
+			"ERROR: script returned exit code 1",
+			"Finished: FAILURE",
+		})
+	})
+
+	t.Run("should show the last lines without error patterns", func(t *testing.T) {
+		assert.DeepEqual(t, jenkinsConsoleTail(lines, nil, 2), []string{
+			"…",
+			"ERROR: script returned exit code 1",
+			"Finished: FAILURE",
+		})
+	})
+
+	t.Run("should show short logs in full", func(t *testing.T) {
+		assert.DeepEqual(t, jenkinsConsoleTail(lines[:2], patterns, 10), lines[:2])
+	})
+}
+
+func TestJenkinsConsoleText(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("HEAD", jenkinsServer+jenkinsBuildBase+"/logText/progressiveText/",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, "")
+			res.Header.Set("X-Text-Size", "100000")
+			return res, nil
+		})
+	httpmock.RegisterResponderWithQuery("GET", jenkinsServer+jenkinsBuildBase+"/logText/progressiveText/", "start=34464",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, "ut off line\nERROR: script returned exit code 1\nFinished: FAILURE\n")
+			res.Header.Set("X-Text-Size", "100000")
+			return res, nil
+		})
+
+	jenkins := gojenkins.CreateJenkins(nil, jenkinsServer+"/")
+	build := &gojenkins.Build{Jenkins: jenkins, Base: jenkinsBuildBase}
+
+	text, err := jenkinsConsoleText(context.Background(), build)
+	assert.NilError(t, err)
+	assert.Equal(t, "ERROR: script returned exit code 1\nFinished: FAILURE\n", text)
+}
+
+func TestCompileJenkinsErrorPatterns(t *testing.T) {
+	patterns, err := CompileJenkinsErrorPatterns([]string{`^ERROR:`, `(?i)\bfatal\b`})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, len(patterns))
+
+	_, err = CompileJenkinsErrorPatterns([]string{`(unclosed`})
+	assert.ErrorContains(t, err, "invalid Jenkins error pattern")
+}
+
+func TestJenkinsConsoleInteraction(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	u, s := jenkinsInputUnfurl(t)
+	u.JenkinsErrorPatterns = []*regexp.Regexp{regexp.MustCompile(`(?i)\bfail(ed|ure)?\b`)}
+
+	httpmock.RegisterResponder("HEAD", jenkinsServer+jenkinsBuildBase+"/logText/progressiveText/",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, "")
+			res.Header.Set("X-Text-Size", "1024")
+			return res, nil
+		})
+	httpmock.RegisterResponderWithQuery("GET", jenkinsServer+jenkinsBuildBase+"/logText/progressiveText/", "start=0",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, jenkinsConsoleLog)
+			res.Header.Set("X-Text-Size", "1024")
+			return res, nil
+		})
+
+	err := u.Interaction(slack.InteractionCallback{
+		Type:       slack.InteractionTypeInteractionMessage,
+		CallbackID: JenkinsBuildCallbackID,
+		User:       slack.User{ID: "U123"},
+		ActionCallback: slack.ActionCallbacks{
+			AttachmentActions: []*slack.AttachmentAction{func() *slack.AttachmentAction {
+				a := jenkinsConsoleAction(&gojenkins.Build{Base: jenkinsBuildBase})
+				return &a
+			}()},
+		},
+	})
+
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(s.views))
+
+	text := s.views[0].Blocks.BlockSet[0].(*slack.SectionBlock).Text.Text
+	assert.Assert(t, strings.HasPrefix(text, "```\nStarted by user Jane Doe\n"))
+	assert.Assert(t, strings.Contains(text, "DB_PASSWORD=[REDACTED"), text)
+	assert.Assert(t, !strings.Contains(text, "hunter2"))
+}
+
+func TestJenkinsBuildLinkConsoleLog(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", jenkinsServer+"/api/json",
+		httpmock.NewStringResponder(200, ""))
+	httpmock.RegisterResponder("GET", jenkinsServer+"/job/my-proj/job/my-repo/job/master/api/json",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jenkins-build.json")))
+
+	buildBase := jenkinsServer + "/job/my-proj/job/my-repo/job/master//791"
+	httpmock.RegisterResponder("GET", buildBase+"/api/json",
+		httpmock.NewStringResponder(200, `{"number":791,"result":"FAILURE","fullDisplayName":"My Proj » my-repo » master #791","url":"https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/791/","duration":61000}`))
+	httpmock.RegisterResponder("GET", buildBase+"/wfapi/describe/",
+		httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("GET", buildBase+"/testReport/api/json",
+		httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("HEAD", buildBase+"/logText/progressiveText/",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, "")
+			res.Header.Set("X-Text-Size", "1024")
+			return res, nil
+		})
+	httpmock.RegisterResponder("GET", buildBase+"/logText/progressiveText/",
+		func(req *http.Request) (*http.Response, error) {
+			res := httpmock.NewStringResponse(200, jenkinsConsoleLog)
+			res.Header.Set("X-Text-Size", "1024")
+			return res, nil
+		})
+
+	jenkins, err := gojenkins.CreateJenkins(nil, jenkinsServer+"/").Init(context.Background())
+	assert.NilError(t, err)
+
+	URL, _ := url.Parse(jenkinsServer + "/job/my-proj/job/my-repo/job/master/791/")
+
+	t.Run("should show the log tail in the unfurl", func(t *testing.T) {
+		u := Unfurl{
+			Jenkins: jenkins,
+			Config: &utils.Config{
+				JenkinsServer:       "jenkins.corp.org",
+				JenkinsConsoleLog:   JenkinsConsoleInline,
+				JenkinsConsoleLines: 2,
+			},
+			JenkinsErrorPatterns: []*regexp.Regexp{regexp.MustCompile(`^ERROR:`)},
+		}
+
+		a, err := u.link(URL, "jenkins.corp.org")
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Started", "Console Log"})
+		assert.Equal(t, a.Fields[3].Value, "```\n…\nERROR: script returned exit code 1\nFinished: FAILURE\n```")
+	})
+
+	t.Run("should offer a button opening the log", func(t *testing.T) {
+		httpmock.ZeroCallCounters()
+
+		u := Unfurl{
+			Jenkins: jenkins,
+			Config:  &utils.Config{JenkinsConsoleLog: JenkinsConsoleButton},
+		}
+
+		a, err := u.jenkinsLink(URL)
+		assert.NilError(t, err)
+
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Started"})
+		assert.Equal(t, a.CallbackID, JenkinsBuildCallbackID)
+		assert.Equal(t, a.Actions[2].Name, jenkinsConsoleShow)
+		assert.Equal(t, httpmock.GetCallCountInfo()["GET "+buildBase+"/logText/progressiveText/"], 0)
+	})
+}
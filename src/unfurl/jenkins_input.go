@@ -0,0 +1,448 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// JenkinsInputCallbackID is the callback ID for Jenkins input actions.
+	JenkinsInputCallbackID = "jenkins_input"
+
+	jenkinsInputProceed = "proceed"
+	jenkinsInputAbort   = "abort"
+	jenkinsInputRespond = "respond"
+
+	jenkinsParamBoolean  = "BooleanParameterDefinition"
+	jenkinsParamChoice   = "ChoiceParameterDefinition"
+	jenkinsParamText     = "TextParameterDefinition"
+	jenkinsParamPassword = "PasswordParameterDefinition"
+)
+
+// jenkinsInput is a pending input step as returned by the Pipeline REST API
+// `wfapi/pendingInputActions` endpoint.
+type jenkinsInput struct {
+	ID          string                  `json:"id"`
+	Message     string                  `json:"message"`
+	ProceedText string                  `json:"proceedText"`
+	Inputs      []jenkinsInputParameter `json:"inputs"`
+}
+
+// jenkinsInputParameter is a parameter requested by a pending input step.
+type jenkinsInputParameter struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Definition  struct {
+		DefaultParameterValue struct {
+			Value interface{} `json:"value"`
+		} `json:"defaultParameterValue"`
+		Choices []string `json:"choices"`
+	} `json:"definition"`
+}
+
+// DefaultValue returns the default value of the parameter as a string.
+func (p jenkinsInputParameter) DefaultValue() string {
+	if p.Definition.DefaultParameterValue.Value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(p.Definition.DefaultParameterValue.Value)
+}
+
+// String returns the parameter as a string
+func (p jenkinsInputParameter) String() string {
+	s := fmt.Sprintf("`%s`", p.Name)
+	if p.Description != "" {
+		s = fmt.Sprintf("%s %s", s, p.Description)
+	}
+
+	return s
+}
+
+// jenkinsPendingInputs returns the pending input steps for a pipeline build.
+func (u *Unfurl) jenkinsPendingInputs(ctx context.Context, build *gojenkins.Build) ([]jenkinsInput, error) {
+	var inputs []jenkinsInput
+
+	// Requester.GetJSON() appends `api/json` to the endpoint which the
+	// Pipeline REST API does not support.
+	res, err := u.Jenkins.Requester.Get(ctx, build.Base+"/wfapi/pendingInputActions", &inputs, nil)
+	if err != nil {
+		return inputs, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return inputs, fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+	}
+
+	return inputs, nil
+}
+
+// jenkinsInputFields returns attachment fields describing pending inputs.
+func jenkinsInputFields(inputs []jenkinsInput) []slack.AttachmentField {
+	var fields []slack.AttachmentField
+
+	for _, input := range inputs {
+		value := input.Message
+		if len(input.Inputs) > 0 {
+			params := make([]string, len(input.Inputs))
+			for i, param := range input.Inputs {
+				params[i] = fmt.Sprintf("• %s", param)
+			}
+			value = fmt.Sprintf("%s\n%s", value, strings.Join(params, "\n"))
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: fmt.Sprintf("Input: %s", input.ID),
+			Value: value,
+			Short: false,
+		})
+	}
+
+	return fields
+}
+
+// jenkinsInputActions returns the attachment actions for responding to the
+// first pending input. Slack does not let us tell the buttons of multiple
+// inputs apart so the remaining ones are handled after the first is done.
+func jenkinsInputActions(build *gojenkins.Build, inputs []jenkinsInput) []slack.AttachmentAction {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	input := inputs[0]
+	value := encodeActionValue(map[string]string{
+		"build": build.Base,
+		"input": input.ID,
+	})
+
+	proceed := slack.AttachmentAction{
+		Name:  jenkinsInputProceed,
+		Text:  input.ProceedText,
+		Type:  "button",
+		Style: "primary",
+		Value: value,
+		Confirm: &slack.ConfirmationField{
+			Title:       input.ID,
+			Text:        input.Message,
+			OkText:      input.ProceedText,
+			DismissText: "Cancel",
+		},
+	}
+
+	if proceed.Text == "" {
+		proceed.Text = "Proceed"
+		proceed.Confirm.OkText = "Proceed"
+	}
+
+	if len(input.Inputs) > 0 {
+		proceed.Name = jenkinsInputRespond
+		proceed.Text = fmt.Sprintf("%s…", proceed.Text)
+		proceed.Confirm = nil
+	}
+
+	return []slack.AttachmentAction{
+		proceed,
+		{
+			Name:  jenkinsInputAbort,
+			Text:  "Abort",
+			Type:  "button",
+			Style: "danger",
+			Value: value,
+			Confirm: &slack.ConfirmationField{
+				Title:       input.ID,
+				Text:        fmt.Sprintf("Abort the build at _%s_?", input.Message),
+				OkText:      "Abort",
+				DismissText: "Cancel",
+			},
+		},
+	}
+}
+
+// jenkinsInputUser returns the Jenkins user of a Slack user, and a Jenkins
+// client acting as that user, or why the Slack user may not respond to input
+// steps. Responses are only sent with the linked account of the user, never
+// the bot credentials, so Jenkins enforces the submitters of the input step.
+// When JENKINS_INPUT_USERS is set, only the listed users may respond, as the
+// Jenkins user they are mapped to.
+func (u *Unfurl) jenkinsInputUser(slackUserID string) (string, *gojenkins.Jenkins, string) {
+	var allowed map[string]string
+	if u.Config != nil {
+		allowed = u.Config.JenkinsInputUsers
+	}
+
+	mapped, listed := allowed[slackUserID]
+	if len(allowed) > 0 && !listed {
+		return "", nil, ":no_entry: You are not allowed to respond to Jenkins input steps."
+	}
+
+	if u.Accounts == nil {
+		return "", nil, ":no_entry: Responding to Jenkins input steps requires account linking."
+	}
+
+	credential, ok, err := u.Accounts.Jenkins(slackUserID)
+	if err != nil {
+		u.Logger.WithError(err).WithField("slackUser", slackUserID).Warn("Failed to get linked Jenkins account")
+	}
+
+	if !ok {
+		return "", nil, ":link: Link your Jenkins account to respond to Jenkins input steps."
+	}
+
+	if listed && mapped != "" && mapped != credential.User {
+		return "", nil, fmt.Sprintf(":no_entry: You may only respond to Jenkins input steps as %s.", mapped)
+	}
+
+	client := gojenkins.CreateJenkins(u.Jenkins.Requester.Client, u.Jenkins.Server, credential.User, credential.Token)
+	return credential.User, client, ""
+}
+
+// jenkinsInput returns a pending input step by its ID.
+func (u *Unfurl) jenkinsInput(ctx context.Context, buildBase string, inputID string) (jenkinsInput, error) {
+	build := &gojenkins.Build{Jenkins: u.Jenkins, Base: buildBase}
+
+	inputs, err := u.jenkinsPendingInputs(ctx, build)
+	if err != nil {
+		return jenkinsInput{}, err
+	}
+
+	for _, input := range inputs {
+		if input.ID == inputID {
+			return input, nil
+		}
+	}
+
+	return jenkinsInput{}, fmt.Errorf("input %s is no longer pending", inputID)
+}
+
+// jenkinsInputAction handles a click on one of the input step buttons.
+func (u *Unfurl) jenkinsInputAction(cb slack.InteractionCallback, name string, value actionValue) error {
+	ctx := context.Background()
+
+	jenkinsUser, jenkins, denied := u.jenkinsInputUser(cb.User.ID)
+	if denied != "" {
+		u.reply(cb, cb.Channel.ID, denied)
+		return nil
+	}
+
+	input, err := u.jenkinsInput(ctx, value.Params["build"], value.Params["input"])
+	if err != nil {
+		u.reply(cb, cb.Channel.ID, fmt.Sprintf(":warning: %s", err))
+		return err
+	}
+
+	switch name {
+	case jenkinsInputRespond:
+		metadata := value
+		metadata.Params["channel"] = cb.Channel.ID
+		metadata.Params["ts"] = cb.MessageTs
+
+		_, err := u.Slack.OpenView(cb.TriggerID, jenkinsInputModal(input, metadata))
+		return err
+
+	case jenkinsInputProceed:
+		err = jenkinsSubmitInput(ctx, jenkins, value.Params["build"], input, nil)
+
+	case jenkinsInputAbort:
+		err = jenkinsAbortInput(ctx, jenkins, value.Params["build"], input)
+
+	default:
+		return fmt.Errorf("unsupported jenkins input action %s", name)
+	}
+
+	u.jenkinsInputDone(cb, cb.Channel.ID, cb.MessageTs, value, jenkinsUser, name, input, err)
+
+	return err
+}
+
+// jenkinsInputSubmission handles the submission of the parameter modal.
+func (u *Unfurl) jenkinsInputSubmission(cb slack.InteractionCallback, value actionValue) error {
+	ctx := context.Background()
+	channel := value.Params["channel"]
+
+	jenkinsUser, jenkins, denied := u.jenkinsInputUser(cb.User.ID)
+	if denied != "" {
+		u.reply(cb, channel, denied)
+		return nil
+	}
+
+	input, err := u.jenkinsInput(ctx, value.Params["build"], value.Params["input"])
+	if err != nil {
+		u.reply(cb, channel, fmt.Sprintf(":warning: %s", err))
+		return err
+	}
+
+	params := map[string]string{}
+	if cb.View.State != nil {
+		for _, param := range input.Inputs {
+			action := cb.View.State.Values[param.Name][jenkinsInputProceed]
+			if action.SelectedOption.Value != "" {
+				params[param.Name] = action.SelectedOption.Value
+			} else {
+				params[param.Name] = action.Value
+			}
+		}
+	}
+
+	err = jenkinsSubmitInput(ctx, jenkins, value.Params["build"], input, params)
+	u.jenkinsInputDone(cb, channel, value.Params["ts"], value, jenkinsUser, jenkinsInputProceed, input, err)
+
+	return err
+}
+
+// jenkinsInputDone tells the user how responding to the input went and
+// refreshes the unfurl.
+func (u *Unfurl) jenkinsInputDone(cb slack.InteractionCallback, channel, ts string, value actionValue, jenkinsUser, action string, input jenkinsInput, err error) {
+	logger := u.Logger.WithFields(logrus.Fields{
+		"slackUser":   cb.User.ID,
+		"jenkinsUser": jenkinsUser,
+		"input":       input.ID,
+		"action":      action,
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Failed to respond to Jenkins input")
+		u.reply(cb, channel, fmt.Sprintf(":x: Failed to %s _%s_: %s", action, input.Message, err))
+		return
+	}
+
+	logger.Info("Responded to Jenkins input")
+	verb := "Proceeded"
+	if action == jenkinsInputAbort {
+		verb = "Aborted"
+	}
+	u.reply(cb, channel, fmt.Sprintf(":white_check_mark: %s _%s_ as %s", verb, input.Message, jenkinsUser))
+
+	if err := u.refresh(cb, channel, ts, value); err != nil {
+		logger.WithError(err).Warn("Failed to refresh unfurl")
+	}
+}
+
+// jenkinsSubmitInput proceeds a pending input step with the given
+// parameters.
+func jenkinsSubmitInput(ctx context.Context, jenkins *gojenkins.Jenkins, buildBase string, input jenkinsInput, params map[string]string) error {
+	endpoint := fmt.Sprintf("%s/input/%s/proceedEmpty", buildBase, url.PathEscape(input.ID))
+	var payload io.Reader
+
+	if len(input.Inputs) > 0 {
+		type parameter struct {
+			Name  string      `json:"name"`
+			Value interface{} `json:"value"`
+		}
+
+		parameters := make([]parameter, len(input.Inputs))
+		for i, param := range input.Inputs {
+			var value interface{} = params[param.Name]
+			if param.Type == jenkinsParamBoolean {
+				value = params[param.Name] == "true"
+			}
+			parameters[i] = parameter{Name: param.Name, Value: value}
+		}
+
+		b, err := json.Marshal(map[string]interface{}{"parameter": parameters})
+		if err != nil {
+			return err
+		}
+
+		form := url.Values{}
+		form.Set("json", string(b))
+		form.Set("proceed", input.ProceedText)
+
+		endpoint = fmt.Sprintf("%s/input/%s/submit", buildBase, url.PathEscape(input.ID))
+		payload = strings.NewReader(form.Encode())
+	}
+
+	return jenkinsPost(ctx, jenkins, endpoint, payload)
+}
+
+// jenkinsAbortInput aborts a pending input step, and with it the build.
+func jenkinsAbortInput(ctx context.Context, jenkins *gojenkins.Jenkins, buildBase string, input jenkinsInput) error {
+	return jenkinsPost(ctx, jenkins, fmt.Sprintf("%s/input/%s/abort", buildBase, url.PathEscape(input.ID)), nil)
+}
+
+// jenkinsPost sends a form POST request to Jenkins.
+func jenkinsPost(ctx context.Context, jenkins *gojenkins.Jenkins, endpoint string, payload io.Reader) error {
+	res, err := jenkins.Requester.Post(ctx, endpoint, payload, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// jenkinsInputModal returns a modal asking for the parameters of an input.
+func jenkinsInputModal(input jenkinsInput, metadata actionValue) slack.ModalViewRequest {
+	b, _ := json.Marshal(metadata)
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, input.Message, false, false), nil, nil),
+	}
+
+	for _, param := range input.Inputs {
+		label := slack.NewTextBlockObject(slack.PlainTextType, param.Name, false, false)
+
+		var element slack.BlockElement
+		switch param.Type {
+		case jenkinsParamBoolean, jenkinsParamChoice:
+			choices := param.Definition.Choices
+			if param.Type == jenkinsParamBoolean {
+				choices = []string{"true", "false"}
+			}
+
+			options := make([]*slack.OptionBlockObject, len(choices))
+			for i, choice := range choices {
+				options[i] = slack.NewOptionBlockObject(choice, slack.NewTextBlockObject(slack.PlainTextType, choice, false, false), nil)
+			}
+
+			sel := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, jenkinsInputProceed, options...)
+			for _, option := range options {
+				if option.Value == param.DefaultValue() {
+					sel.InitialOption = option
+				}
+			}
+			element = sel
+
+		default:
+			text := slack.NewPlainTextInputBlockElement(nil, jenkinsInputProceed)
+			text.Multiline = param.Type == jenkinsParamText
+			if param.Type != jenkinsParamPassword {
+				text.InitialValue = param.DefaultValue()
+			}
+			element = text
+		}
+
+		block := slack.NewInputBlock(param.Name, label, element)
+		if param.Description != "" {
+			block.Hint = slack.NewTextBlockObject(slack.PlainTextType, param.Description, false, false)
+		}
+		blocks = append(blocks, block)
+	}
+
+	submit := input.ProceedText
+	if submit == "" {
+		submit = "Proceed"
+	}
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      JenkinsInputCallbackID,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, "Jenkins input", false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, submit, false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: string(b),
+	}
+}
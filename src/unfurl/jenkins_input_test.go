@@ -0,0 +1,253 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/accounts"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+const (
+	jenkinsServer    = "https://jenkins.corp.org"
+	jenkinsBuildBase = "/job/my-proj/job/my-repo/job/master/789"
+)
+
+// fakeSlack records the calls made to the Slack API.
+type fakeSlack struct {
+	views      []slack.ModalViewRequest
+	messages   []string
+	unfurls    []string
+	ephemerals []string
+
+	channel slack.Channel
+	user    slack.User
+}
+
+func (f *fakeSlack) OpenView(triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+	f.views = append(f.views, view)
+	return &slack.ViewResponse{}, nil
+}
+
+func (f *fakeSlack) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, _ := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	f.messages = append(f.messages, channelID)
+	f.unfurls = append(f.unfurls, values.Get("unfurls"))
+	return channelID, "", nil
+}
+
+func (f *fakeSlack) PostEphemeral(channelID, userID string, options ...slack.MsgOption) (string, error) {
+	_, values, _ := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	f.ephemerals = append(f.ephemerals, values.Get("text"))
+	return "", nil
+}
+
+func (f *fakeSlack) GetConversationInfo(channelID string, includeLocale bool) (*slack.Channel, error) {
+	return &f.channel, nil
+}
+
+func (f *fakeSlack) GetUserInfo(user string) (*slack.User, error) {
+	return &f.user, nil
+}
+
+// testAccounts returns an account manager with the given linked accounts
+func testAccounts(t *testing.T, linked ...accounts.Account) *accounts.Manager {
+	store, err := accounts.NewFileStore(filepath.Join(t.TempDir(), "accounts.json"), "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	assert.NilError(t, err)
+
+	for _, account := range linked {
+		assert.NilError(t, store.Put(account))
+	}
+
+	return &accounts.Manager{Logger: logrus.StandardLogger(), Store: store, Cipher: store.Cipher}
+}
+
+func jenkinsInputUnfurl(t *testing.T) (Unfurl, *fakeSlack) {
+	httpmock.RegisterResponder("GET", jenkinsServer+"/api/json",
+		httpmock.NewStringResponder(200, ""))
+	// Requester.SetCrumb() appends `api/json` twice to the crumb issuer URL.
+	httpmock.RegisterResponder("GET", jenkinsServer+"/crumbIssuer/api/json/api/json",
+		httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponder("GET", jenkinsServer+jenkinsBuildBase+"/wfapi/pendingInputActions/",
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jenkins-pending-input-actions.json")))
+
+	jenkins, err := gojenkins.CreateJenkins(nil, jenkinsServer+"/").Init(context.Background())
+	if err != nil {
+		t.Fatalf("Error creating Jenkins client: %s", err)
+	}
+
+	s := &fakeSlack{}
+	return Unfurl{
+		Logger:  logrus.StandardLogger(),
+		Jenkins: jenkins,
+		Slack:   s,
+		Config: &utils.Config{
+			JenkinsInputUsers: map[string]string{"U123": "jdoe", "U456": "asmith", "U789": "bwayne"},
+		},
+		Accounts: testAccounts(t,
+			accounts.Account{SlackUserID: "U123", Jenkins: &accounts.Credential{User: "jdoe", Token: "jdoe-token"}},
+			accounts.Account{SlackUserID: "U789", Jenkins: &accounts.Credential{User: "jdoe", Token: "jdoe-token"}},
+		),
+	}, s
+}
+
+func TestJenkinsPendingInputs(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	u, _ := jenkinsInputUnfurl(t)
+
+	build := &gojenkins.Build{Jenkins: u.Jenkins, Base: jenkinsBuildBase}
+	inputs, err := u.jenkinsPendingInputs(context.Background(), build)
+	if err != nil {
+		t.Fatalf("Error getting pending inputs: %s", err)
+	}
+
+	assert.Equal(t, 1, len(inputs))
+	assert.Equal(t, "Deploy", inputs[0].ID)
+	assert.Equal(t, "Deploy to production?", inputs[0].Message)
+	assert.Equal(t, 2, len(inputs[0].Inputs))
+	assert.Equal(t, "staging", inputs[0].Inputs[0].DefaultValue())
+	assert.Equal(t, "true", inputs[0].Inputs[1].DefaultValue())
+
+	t.Run("should offer a modal for parameterised inputs", func(t *testing.T) {
+		actions := jenkinsInputActions(build, inputs)
+
+		assert.Equal(t, 2, len(actions))
+		assert.Equal(t, jenkinsInputRespond, actions[0].Name)
+		assert.Equal(t, "Deploy…", actions[0].Text)
+		assert.Equal(t, jenkinsInputAbort, actions[1].Name)
+	})
+
+	t.Run("should offer proceed for inputs without parameters", func(t *testing.T) {
+		input := inputs[0]
+		input.Inputs = nil
+		actions := jenkinsInputActions(build, []jenkinsInput{input})
+
+		assert.Equal(t, jenkinsInputProceed, actions[0].Name)
+		assert.Equal(t, "Deploy", actions[0].Text)
+	})
+}
+
+func TestJenkinsInputInteraction(t *testing.T) {
+	value := encodeActionValue(map[string]string{
+		"build": jenkinsBuildBase,
+		"input": "Deploy",
+	})
+
+	t.Run("should reject users that are not mapped", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		u, s := jenkinsInputUnfurl(t)
+
+		err := u.Interaction(slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: JenkinsInputCallbackID,
+			User:       slack.User{ID: "U999"},
+			Channel:    slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C123"}}},
+			ActionCallback: slack.ActionCallbacks{
+				AttachmentActions: []*slack.AttachmentAction{{Name: jenkinsInputAbort, Value: value}},
+			},
+		})
+
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(s.ephemerals))
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["POST "+jenkinsServer+jenkinsBuildBase+"/input/Deploy/abort"])
+	})
+
+	t.Run("should not respond with the bot credentials for users without a linked account", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		u, s := jenkinsInputUnfurl(t)
+
+		for _, user := range []string{"U456", "U789"} {
+			err := u.Interaction(slack.InteractionCallback{
+				Type:       slack.InteractionTypeInteractionMessage,
+				CallbackID: JenkinsInputCallbackID,
+				User:       slack.User{ID: user},
+				ActionCallback: slack.ActionCallbacks{
+					AttachmentActions: []*slack.AttachmentAction{{Name: jenkinsInputAbort, Value: value}},
+				},
+			})
+			assert.NilError(t, err)
+		}
+
+		assert.DeepEqual(t, []string{
+			":link: Link your Jenkins account to respond to Jenkins input steps.",
+			":no_entry: You may only respond to Jenkins input steps as bwayne.",
+		}, s.ephemerals)
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["POST "+jenkinsServer+jenkinsBuildBase+"/input/Deploy/abort"])
+	})
+
+	t.Run("should open a modal for parameterised inputs", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		u, s := jenkinsInputUnfurl(t)
+
+		err := u.Interaction(slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: JenkinsInputCallbackID,
+			User:       slack.User{ID: "U123"},
+			ActionCallback: slack.ActionCallbacks{
+				AttachmentActions: []*slack.AttachmentAction{{Name: jenkinsInputRespond, Value: value}},
+			},
+		})
+
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(s.views))
+		assert.Equal(t, JenkinsInputCallbackID, s.views[0].CallbackID)
+		assert.Equal(t, 3, len(s.views[0].Blocks.BlockSet))
+	})
+
+	t.Run("should submit the modal parameters to Jenkins", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		u, s := jenkinsInputUnfurl(t)
+
+		var form url.Values
+		var user string
+		httpmock.RegisterResponder("POST", jenkinsServer+jenkinsBuildBase+"/input/Deploy/submit",
+			func(req *http.Request) (*http.Response, error) {
+				if err := req.ParseForm(); err != nil {
+					return nil, err
+				}
+				form = req.PostForm
+				user, _, _ = req.BasicAuth()
+				return httpmock.NewStringResponse(200, ""), nil
+			})
+
+		err := u.Interaction(slack.InteractionCallback{
+			Type: slack.InteractionTypeViewSubmission,
+			User: slack.User{ID: "U123"},
+			View: slack.View{
+				CallbackID:      JenkinsInputCallbackID,
+				PrivateMetadata: value,
+				State: &slack.ViewState{
+					Values: map[string]map[string]slack.BlockAction{
+						"ENVIRONMENT": {jenkinsInputProceed: {SelectedOption: slack.OptionBlockObject{Value: "production"}}},
+						"DRY_RUN":     {jenkinsInputProceed: {SelectedOption: slack.OptionBlockObject{Value: "false"}}},
+					},
+				},
+			},
+		})
+
+		assert.NilError(t, err)
+		assert.Equal(t, "jdoe", user)
+		assert.Equal(t, "Deploy", form.Get("proceed"))
+		assert.Equal(t, `{"parameter":[{"name":"ENVIRONMENT","value":"production"},{"name":"DRY_RUN","value":false}]}`, form.Get("json"))
+		assert.Equal(t, fmt.Sprintf(":white_check_mark: Proceeded _%s_ as jdoe", "Deploy to production?"), s.ephemerals[0])
+	})
+}
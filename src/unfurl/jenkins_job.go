@@ -0,0 +1,271 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// jenkinsMultiBranchClass is the class of multibranch pipeline jobs
+	jenkinsMultiBranchClass = "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+
+	// jenkinsJobChildren is the max number of branches, pull requests or jobs
+	// listed in job unfurls.
+	jenkinsJobChildren = 10
+)
+
+// jenkinsResultEmojis are the emojis for build results
+var jenkinsResultEmojis = map[string]string{
+	"SUCCESS":   ":white_check_mark:",
+	"FAILURE":   ":x:",
+	"UNSTABLE":  ":warning:",
+	"ABORTED":   ":no_entry_sign:",
+	"NOT_BUILT": ":white_circle:",
+}
+
+// jenkinsColorResults are the build results of job colors
+var jenkinsColorResults = map[string]string{
+	"blue":     "SUCCESS",
+	"red":      "FAILURE",
+	"yellow":   "UNSTABLE",
+	"aborted":  "ABORTED",
+	"notbuilt": "NOT_BUILT",
+}
+
+// jenkinsChangeRequest matches the names of pull request jobs of multibranch
+// pipelines, e.g. PR-12 or MR-3.
+var jenkinsChangeRequest = regexp.MustCompile(`^(PR|MR)-[0-9]+$`)
+
+// jenkinsJobBuild is a build in the history of a job
+type jenkinsJobBuild struct {
+	Number   int64  `json:"number"`
+	Result   string `json:"result"`
+	Building bool   `json:"building"`
+	URL      string `json:"url"`
+}
+
+// jenkinsResultEmoji returns the emoji for a build result
+func jenkinsResultEmoji(result string, building bool) string {
+	if building {
+		return ":arrows_counterclockwise:"
+	}
+
+	if emoji, ok := jenkinsResultEmojis[result]; ok {
+		return emoji
+	}
+
+	return ":grey_question:"
+}
+
+// jenkinsColorEmoji returns the emoji for the color of a job. Colors of jobs
+// with a build in progress end with _anime.
+func jenkinsColorEmoji(color string) string {
+	if strings.HasSuffix(color, "_anime") {
+		return jenkinsResultEmoji("", true)
+	}
+
+	return jenkinsResultEmoji(jenkinsColorResults[color], false)
+}
+
+// jenkinsHealthEmoji returns the weather emoji Jenkins uses for a health
+// score, e.g. sunny above 80.
+func jenkinsHealthEmoji(score int64) string {
+	switch {
+	case score > 80:
+		return ":sunny:"
+	case score > 60:
+		return ":mostly_sunny:"
+	case score > 40:
+		return ":cloud:"
+	case score > 20:
+		return ":rain_cloud:"
+	}
+
+	return ":thunder_cloud_and_rain:"
+}
+
+// jenkinsSparkline returns the results of builds, newest first, as a line of
+// emojis with the newest build last.
+func jenkinsSparkline(builds []jenkinsJobBuild) string {
+	var sb strings.Builder
+	for i := len(builds) - 1; i >= 0; i-- {
+		sb.WriteString(jenkinsResultEmoji(builds[i].Result, builds[i].Building))
+	}
+
+	return sb.String()
+}
+
+// jenkinsJobBuilds returns the last builds of a job, newest first
+func (u *Unfurl) jenkinsJobBuilds(ctx context.Context, job *gojenkins.Job, limit int) ([]jenkinsJobBuild, error) {
+	var history struct {
+		Builds []jenkinsJobBuild `json:"builds"`
+	}
+
+	res, err := u.Jenkins.Requester.GetJSON(ctx, job.Base, &history, map[string]string{
+		"tree": fmt.Sprintf("builds[number,result,building,url]{0,%d}", limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+	}
+
+	return history.Builds, nil
+}
+
+// jenkinsBuildRef returns a link to a build of a job, or None
+func jenkinsBuildRef(build gojenkins.JobBuild) string {
+	if build.Number == 0 {
+		return "None"
+	}
+
+	return fmt.Sprintf("<%s|#%d>", build.URL, build.Number)
+}
+
+// jenkinsHealthField returns the health of a job. The health is the lowest
+// score of its reports, such as build stability and test results.
+func jenkinsHealthField(job *gojenkins.Job) (slack.AttachmentField, bool) {
+	reports := job.Raw.HealthReport
+	if len(reports) == 0 {
+		return slack.AttachmentField{}, false
+	}
+
+	worst := reports[0]
+	for _, r := range reports[1:] {
+		if r.Score < worst.Score {
+			worst = r
+		}
+	}
+
+	return slack.AttachmentField{
+		Title: "Health",
+		Value: fmt.Sprintf("%s %d%% – %s", jenkinsHealthEmoji(worst.Score), worst.Score, worst.Description),
+	}, true
+}
+
+// jenkinsChildrenFields returns the branches and pull requests of a
+// multibranch pipeline, or the jobs of a folder, with their status.
+func jenkinsChildrenFields(job *gojenkins.Job) []slack.AttachmentField {
+	var branches, changeRequests []string
+	for _, child := range job.Raw.Jobs {
+		name, err := url.PathUnescape(child.Name)
+		if err != nil {
+			name = child.Name
+		}
+
+		line := fmt.Sprintf("%s <%s|%s>", jenkinsColorEmoji(child.Color), child.Url, name)
+		if job.Raw.Class == jenkinsMultiBranchClass && jenkinsChangeRequest.MatchString(child.Name) {
+			changeRequests = append(changeRequests, line)
+		} else {
+			branches = append(branches, line)
+		}
+	}
+
+	title := "Jobs"
+	if job.Raw.Class == jenkinsMultiBranchClass {
+		title = "Branches"
+	}
+
+	var fields []slack.AttachmentField
+	if len(branches) > 0 {
+		fields = append(fields, slack.AttachmentField{
+			Title: title,
+			Value: jenkinsChildrenList(branches),
+			Short: true,
+		})
+	}
+	if len(changeRequests) > 0 {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Pull Requests",
+			Value: jenkinsChildrenList(changeRequests),
+			Short: true,
+		})
+	}
+
+	return fields
+}
+
+// jenkinsChildrenList returns up to jenkinsJobChildren lines, and how many
+// were left out.
+func jenkinsChildrenList(lines []string) string {
+	if len(lines) <= jenkinsJobChildren {
+		return strings.Join(lines, "\n")
+	}
+
+	return fmt.Sprintf("%s\n…and %d more", strings.Join(lines[:jenkinsJobChildren], "\n"), len(lines)-jenkinsJobChildren)
+}
+
+// jenkinsJobLink returns a Slack Attachment for a Jenkins job, folder or
+// multibranch pipeline.
+func (u *Unfurl) jenkinsJobLink(p jenkinsPath) (slack.Attachment, error) {
+	attachement := slack.Attachment{}
+	ctx := context.Background()
+
+	job, err := u.Jenkins.GetJob(ctx, p.JobName())
+	if err != nil {
+		return attachement, err
+	}
+
+	attachement.Title = job.Raw.FullDisplayName
+	if attachement.Title == "" {
+		attachement.Title = job.Raw.FullName
+	}
+	attachement.TitleLink = job.Raw.URL
+	attachement.Text = job.Raw.Description
+
+	if field, ok := jenkinsHealthField(job); ok {
+		attachement.Fields = append(attachement.Fields, field)
+	}
+
+	if len(job.Raw.Jobs) > 0 {
+		attachement.Fields = append(attachement.Fields, jenkinsChildrenFields(job)...)
+
+		return attachement, nil
+	}
+
+	attachement.Fields = append(attachement.Fields, []slack.AttachmentField{
+		{
+			Title: "Last Build",
+			Value: jenkinsBuildRef(job.Raw.LastBuild),
+			Short: true,
+		},
+		{
+			Title: "Last Successful Build",
+			Value: jenkinsBuildRef(job.Raw.LastSuccessfulBuild),
+			Short: true,
+		},
+		{
+			Title: "Last Failed Build",
+			Value: jenkinsBuildRef(job.Raw.LastFailedBuild),
+			Short: true,
+		},
+	}...)
+
+	limit := 10
+	if u.Config != nil {
+		limit = u.Config.JenkinsJobHistory
+	}
+
+	builds, err := u.jenkinsJobBuilds(ctx, job, limit)
+	if err != nil {
+		return attachement, err
+	}
+
+	if len(builds) > 0 {
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: "History",
+			Value: jenkinsSparkline(builds),
+		})
+	}
+
+	return attachement, nil
+}
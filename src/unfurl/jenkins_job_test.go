@@ -0,0 +1,85 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestJenkinsSparkline(t *testing.T) {
+	builds := []jenkinsJobBuild{
+		{Number: 5, Building: true},
+		{Number: 4, Result: "SUCCESS"},
+		{Number: 3, Result: "FAILURE"},
+		{Number: 2, Result: "UNSTABLE"},
+		{Number: 1, Result: "ABORTED"},
+	}
+
+	assert.Equal(t, jenkinsSparkline(builds), ":no_entry_sign::warning::x::white_check_mark::arrows_counterclockwise:")
+	assert.Equal(t, jenkinsSparkline(nil), "")
+}
+
+func TestJenkinsColorEmoji(t *testing.T) {
+	assert.Equal(t, jenkinsColorEmoji("blue"), ":white_check_mark:")
+	assert.Equal(t, jenkinsColorEmoji("red"), ":x:")
+	assert.Equal(t, jenkinsColorEmoji("red_anime"), ":arrows_counterclockwise:")
+	assert.Equal(t, jenkinsColorEmoji("disabled"), ":grey_question:")
+}
+
+func TestJenkinsJobLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://jenkins.corp.org/api/json",
+		httpmock.NewStringResponder(200, ""))
+
+	jobUrl := "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/api/json"
+	httpmock.RegisterResponder("GET", jobUrl,
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "jenkins-build.json")).String()))
+	httpmock.RegisterResponderWithQuery("GET", jobUrl, "tree=builds[number,result,building,url]{0,10}",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "jenkins-job-builds.json")).String()))
+
+	httpmock.RegisterResponder("GET", "https://jenkins.corp.org/job/my-proj/job/my-repo/api/json",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "jenkins-multibranch.json")).String()))
+
+	ctx := context.Background()
+	jenkins, err := gojenkins.CreateJenkins(nil, "https://jenkins.corp.org/").Init(ctx)
+	assert.NilError(t, err)
+
+	u := Unfurl{
+		Jenkins: jenkins,
+	}
+
+	t.Run("should unfurl a job with its history", func(t *testing.T) {
+		URL, _ := url.Parse("https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/")
+		a, err := u.jenkinsLink(URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, a.Title, "My Proj » my-repo » master")
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Health", "Last Build", "Last Successful Build", "Last Failed Build", "History"})
+		assert.Equal(t, a.Fields[0].Value, ":mostly_sunny: 80% – Build stability: 1 out of the last 5 builds failed.")
+		assert.Equal(t, a.Fields[1].Value, "<https://jenkins.corp.org.no/job/my-proj/job/my-repo/job/master/834/|#834>")
+		assert.Equal(t, a.Fields[3].Value, "<https://jenkins.corp.org.no/job/my-proj/job/my-repo/job/master/833/|#833>")
+		assert.Equal(t, a.Fields[4].Value, ":white_check_mark::white_check_mark::x::white_check_mark::arrows_counterclockwise:")
+	})
+
+	t.Run("should list the branches and pull requests of a multibranch pipeline", func(t *testing.T) {
+		URL, _ := url.Parse("https://jenkins.corp.org/job/my-proj/job/my-repo/")
+		a, err := u.jenkinsLink(URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, a.Title, "My Proj » my-repo")
+		assert.Equal(t, a.Text, "Terraform for the dockyard cluster")
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Health", "Branches", "Pull Requests"})
+		assert.Equal(t, a.Fields[0].Value, ":cloud: 60% – Worst health: my-proj » my-repo » PR-14: Build stability: 2 out of the last 5 builds failed.")
+		assert.Equal(t, a.Fields[1].Value, ":arrows_counterclockwise: <https://jenkins.corp.org/job/my-proj/job/my-repo/job/feature%252Fterraform-1.2/|feature/terraform-1.2>\n"+
+			":white_check_mark: <https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/|master>")
+		assert.Equal(t, a.Fields[2].Value, ":x: <https://jenkins.corp.org/job/my-proj/job/my-repo/job/PR-14/|PR-14>\n"+
+			":white_circle: <https://jenkins.corp.org/job/my-proj/job/my-repo/job/PR-15/|PR-15>")
+	})
+}
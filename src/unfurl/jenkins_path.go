@@ -0,0 +1,135 @@
+package unfurl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// jenkinsBuildAliases are the permalinks Jenkins resolves to a build number
+var jenkinsBuildAliases = map[string]bool{
+	"firstBuild":            true,
+	"lastBuild":             true,
+	"lastCompletedBuild":    true,
+	"lastFailedBuild":       true,
+	"lastStableBuild":       true,
+	"lastSuccessfulBuild":   true,
+	"lastUnstableBuild":     true,
+	"lastUnsuccessfulBuild": true,
+}
+
+var jenkinsBuildNumber = regexp.MustCompile(`^[0-9]+$`)
+
+// jenkinsPath is a Jenkins job, by the names of its folders and itself, and
+// optionally one of its builds. Names are kept escaped as in the link, so
+// that branch jobs such as feature%252Ffoo are requested as they were linked.
+type jenkinsPath struct {
+	Job   []string
+	Build string
+}
+
+// JobName returns the job name as expected by gojenkins, e.g.
+// k8s/job/tf-dockyard/job/master
+func (p jenkinsPath) JobName() string {
+	return strings.Join(p.Job, "/job/")
+}
+
+// IsAlias returns true if the build is a permalink such as lastBuild rather
+// than a build number.
+func (p jenkinsPath) IsAlias() bool {
+	return jenkinsBuildAliases[p.Build]
+}
+
+// isJenkinsBuild returns true for build numbers and permalinks
+func isJenkinsBuild(segment string) bool {
+	return jenkinsBuildNumber.MatchString(segment) || jenkinsBuildAliases[segment]
+}
+
+// parseJenkinsPath parses the job and build of a Jenkins link. Classic links
+// with any number of folders, optionally below views or a context path, and
+// Blue Ocean links are supported.
+func parseJenkinsPath(URL *url.URL) (jenkinsPath, bool) {
+	var segments []string
+	for _, s := range strings.Split(URL.EscapedPath(), "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	for i := 0; i < len(segments); i++ {
+		switch {
+		case segments[i] == "job":
+			return parseClassicJenkinsPath(segments[i:])
+		case segments[i] == "blue" && i+1 < len(segments) && segments[i+1] == "organizations":
+			return parseBlueOceanJenkinsPath(segments[i+2:])
+		case segments[i] == "view":
+			i++
+		}
+	}
+
+	return jenkinsPath{}, false
+}
+
+// parseClassicJenkinsPath parses /job/<folder>/job/<job>/<build>/... where
+// folders may contain views.
+func parseClassicJenkinsPath(segments []string) (jenkinsPath, bool) {
+	var p jenkinsPath
+
+	i := 0
+	for i+1 < len(segments) {
+		switch segments[i] {
+		case "job":
+			p.Job = append(p.Job, segments[i+1])
+		case "view":
+		default:
+			if isJenkinsBuild(segments[i]) {
+				p.Build = segments[i]
+			}
+
+			return p, len(p.Job) > 0
+		}
+
+		i += 2
+	}
+
+	if i < len(segments) && isJenkinsBuild(segments[i]) {
+		p.Build = segments[i]
+	}
+
+	return p, len(p.Job) > 0
+}
+
+// parseBlueOceanJenkinsPath parses
+// <organization>/<pipeline>/detail/<branch>/<build>/... where the pipeline is
+// the folder path separated by %2F. Jobs that are not multibranch repeat
+// their own name as the branch.
+func parseBlueOceanJenkinsPath(segments []string) (jenkinsPath, bool) {
+	var p jenkinsPath
+
+	if len(segments) < 2 {
+		return p, false
+	}
+
+	pipeline, err := url.PathUnescape(segments[1])
+	if err != nil || pipeline == "" {
+		return p, false
+	}
+
+	for _, name := range strings.Split(pipeline, "/") {
+		p.Job = append(p.Job, url.PathEscape(name))
+	}
+
+	if len(segments) < 4 || segments[2] != "detail" {
+		return p, true
+	}
+
+	if branch := segments[3]; branch != p.Job[len(p.Job)-1] {
+		p.Job = append(p.Job, branch)
+	}
+
+	if len(segments) > 4 && isJenkinsBuild(segments[4]) {
+		p.Build = segments[4]
+	}
+
+	return p, true
+}
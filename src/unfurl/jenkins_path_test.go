@@ -0,0 +1,64 @@
+package unfurl
+
+import (
+	"net/url"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestJenkinsLinkType(t *testing.T) {
+	tests := []struct {
+		link     string
+		linkType string
+		job      []string
+		build    string
+	}{
+		{"/job/k8s/job/tf-dockyard/job/master/821/", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "master"}, "821"},
+		{"/job/k8s/job/tf-dockyard/job/master/821/console", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "master"}, "821"},
+		{"/job/deploy/42", JenkinsURLBuildType, []string{"deploy"}, "42"},
+		{"/job/a/job/b/job/c/job/d/job/e/7/testReport/", JenkinsURLBuildType, []string{"a", "b", "c", "d", "e"}, "7"},
+		{"/job/k8s/job/tf-dockyard/job/feature%2Ffoo/3/", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "feature%2Ffoo"}, "3"},
+		{"/job/k8s/job/tf-dockyard/job/feature%252Ffoo/3/", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "feature%252Ffoo"}, "3"},
+		{"/job/k8s/job/tf-dockyard/job/master/lastBuild/", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "master"}, "lastBuild"},
+		{"/job/deploy/lastSuccessfulBuild/console", JenkinsURLBuildType, []string{"deploy"}, "lastSuccessfulBuild"},
+		{"/job/deploy/lastUnstableBuild", JenkinsURLBuildType, []string{"deploy"}, "lastUnstableBuild"},
+		{"/job/k8s/job/tf-dockyard/job/master/821/display/redirect", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "master"}, "821"},
+		{"/view/All/job/deploy/42/", JenkinsURLBuildType, []string{"deploy"}, "42"},
+		{"/view/team/view/nightly/job/k8s/job/deploy/42/", JenkinsURLBuildType, []string{"k8s", "deploy"}, "42"},
+		{"/job/k8s/view/prod/job/deploy/42/", JenkinsURLBuildType, []string{"k8s", "deploy"}, "42"},
+		{"/user/jdoe/my-views/view/all/job/deploy/42/", JenkinsURLBuildType, []string{"deploy"}, "42"},
+		{"/jenkins/job/deploy/42/", JenkinsURLBuildType, []string{"deploy"}, "42"},
+		{"/blue/organizations/jenkins/k8s%2Ftf-dockyard/detail/master/821/pipeline", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "master"}, "821"},
+		{"/blue/organizations/jenkins/k8s%2Ftf-dockyard/detail/feature%252Ffoo/3/pipeline/57", JenkinsURLBuildType, []string{"k8s", "tf-dockyard", "feature%252Ffoo"}, "3"},
+		{"/blue/organizations/jenkins/deploy/detail/deploy/42/tests", JenkinsURLBuildType, []string{"deploy"}, "42"},
+		{"/blue/organizations/jenkins/ops%2Fdeploy/detail/deploy/42/", JenkinsURLBuildType, []string{"ops", "deploy"}, "42"},
+		{"/job/deploy/", JenkinsURLJobType, []string{"deploy"}, ""},
+		{"/job/k8s/job/tf-dockyard/job/master/changes", JenkinsURLJobType, []string{"k8s", "tf-dockyard", "master"}, ""},
+		{"/view/All/job/k8s/job/tf-dockyard/", JenkinsURLJobType, []string{"k8s", "tf-dockyard"}, ""},
+		{"/blue/organizations/jenkins/k8s%2Ftf-dockyard/activity", JenkinsURLJobType, []string{"k8s", "tf-dockyard"}, ""},
+		{"/blue/organizations/jenkins/k8s%2Ftf-dockyard/detail/master/activity", JenkinsURLJobType, []string{"k8s", "tf-dockyard", "master"}, ""},
+		{"/", JenkinsURLUknownType, nil, ""},
+		{"/view/All/", JenkinsURLUknownType, nil, ""},
+		{"/manage/", JenkinsURLUknownType, nil, ""},
+		{"/blue/organizations/jenkins/", JenkinsURLUknownType, nil, ""},
+	}
+
+	for _, test := range tests {
+		URL, err := url.Parse("https://jenkins.corp.org" + test.link)
+		assert.NilError(t, err)
+
+		linkType, p := jenkinsLinkType(URL)
+		assert.Equal(t, linkType, test.linkType, test.link)
+		assert.DeepEqual(t, p.Job, test.job)
+		assert.Equal(t, p.Build, test.build, test.link)
+	}
+}
+
+func TestJenkinsPathJobName(t *testing.T) {
+	p := jenkinsPath{Job: []string{"k8s", "tf-dockyard", "feature%252Ffoo"}, Build: "lastBuild"}
+
+	assert.Equal(t, p.JobName(), "k8s/job/tf-dockyard/job/feature%252Ffoo")
+	assert.Assert(t, p.IsAlias())
+	assert.Assert(t, !jenkinsPath{Build: "42"}.IsAlias())
+}
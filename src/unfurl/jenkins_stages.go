@@ -0,0 +1,166 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bndr/gojenkins"
+	"github.com/slack-go/slack"
+)
+
+// jenkinsStageResults are the build results of Pipeline REST API statuses
+var jenkinsStageResults = map[string]string{
+	"SUCCESS":      "SUCCESS",
+	"FAILED":       "FAILURE",
+	"UNSTABLE":     "UNSTABLE",
+	"ABORTED":      "ABORTED",
+	"NOT_EXECUTED": "NOT_BUILT",
+}
+
+// jenkinsErrorLength is the max length of stage and test error messages
+const jenkinsErrorLength = 200
+
+// jenkinsRun is a pipeline build as returned by the Pipeline REST API
+// (wfapi/describe).
+type jenkinsRun struct {
+	ID     string         `json:"id"`
+	Name   string         `json:"name"`
+	Status string         `json:"status"`
+	Stages []jenkinsStage `json:"stages"`
+}
+
+// jenkinsStage is a stage of a pipeline build
+type jenkinsStage struct {
+	ID                  string             `json:"id"`
+	Name                string             `json:"name"`
+	Status              string             `json:"status"`
+	StartTimeMillis     int64              `json:"startTimeMillis"`
+	DurationMillis      int64              `json:"durationMillis"`
+	PauseDurationMillis int64              `json:"pauseDurationMillis"`
+	Error               *jenkinsStageError `json:"error"`
+}
+
+// jenkinsStageError is the error that failed a stage
+type jenkinsStageError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// Emoji returns the emoji for the status of the stage
+func (s jenkinsStage) Emoji() string {
+	switch s.Status {
+	case "IN_PROGRESS":
+		return jenkinsResultEmoji("", true)
+	case "PAUSED_PENDING_INPUT":
+		return ":double_vertical_bar:"
+	}
+
+	return jenkinsResultEmoji(jenkinsStageResults[s.Status], false)
+}
+
+// Duration returns how long the stage ran, not counting time waiting for
+// input.
+func (s jenkinsStage) Duration() time.Duration {
+	return (time.Duration(s.DurationMillis-s.PauseDurationMillis) * time.Millisecond).Round(time.Second)
+}
+
+// ErrorMessage returns the first line of the error of a failed stage
+func (s jenkinsStage) ErrorMessage() string {
+	if s.Error == nil {
+		return ""
+	}
+
+	return jenkinsErrorLine(s.Error.Message)
+}
+
+// jenkinsErrorLine returns the first line of an error message, shortened to
+// jenkinsErrorLength characters.
+func jenkinsErrorLine(msg string) string {
+	msg = strings.TrimSpace(msg)
+	if i := strings.Index(msg, "\n"); i >= 0 {
+		msg = strings.TrimSpace(msg[:i])
+	}
+	if r := []rune(msg); len(r) > jenkinsErrorLength {
+		msg = string(r[:jenkinsErrorLength]) + "…"
+	}
+
+	return msg
+}
+
+// failedStage returns the index of the stage that broke the build, the first
+// failed stage or else the first unstable one, or -1.
+func (r jenkinsRun) failedStage() int {
+	unstable := -1
+	for i, s := range r.Stages {
+		switch s.Status {
+		case "FAILED":
+			return i
+		case "UNSTABLE":
+			if unstable < 0 {
+				unstable = i
+			}
+		}
+	}
+
+	return unstable
+}
+
+// jenkinsDescribe returns the stages of a pipeline build. Builds of other job
+// types, such as freestyle jobs, have no stages.
+func (u *Unfurl) jenkinsDescribe(ctx context.Context, build *gojenkins.Build) (jenkinsRun, error) {
+	var run jenkinsRun
+
+	// Requester.GetJSON() appends `api/json` to the endpoint which the
+	// Pipeline REST API does not support.
+	res, err := u.Jenkins.Requester.Get(ctx, build.Base+"/wfapi/describe", &run, nil)
+	if err != nil {
+		return run, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return run, nil
+	case http.StatusNotFound:
+		return jenkinsRun{}, nil
+	}
+
+	return run, fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+}
+
+// jenkinsStagesField returns a field listing each stage of a pipeline build
+// with its status and duration. The stage that broke the build is in bold,
+// followed by its error message.
+func jenkinsStagesField(run jenkinsRun) (slack.AttachmentField, bool) {
+	if len(run.Stages) == 0 {
+		return slack.AttachmentField{}, false
+	}
+
+	failed := run.failedStage()
+
+	lines := make([]string, 0, len(run.Stages))
+	for i, s := range run.Stages {
+		name := s.Name
+		if i == failed {
+			name = fmt.Sprintf("*%s*", name)
+		}
+
+		line := fmt.Sprintf("%s %s", s.Emoji(), name)
+		if s.Status != "NOT_EXECUTED" {
+			line += fmt.Sprintf(" (%s)", s.Duration())
+		}
+		if msg := s.ErrorMessage(); i == failed && msg != "" {
+			line += fmt.Sprintf("\n> %s", msg)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return slack.AttachmentField{
+		Title: "Stages",
+		Value: strings.Join(lines, "\n"),
+		Short: false,
+	}, true
+}
@@ -0,0 +1,59 @@
+package unfurl
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestJenkinsStagesField(t *testing.T) {
+	t.Run("should highlight the first failed stage", func(t *testing.T) {
+		run := jenkinsRun{Stages: []jenkinsStage{
+			{Name: "Lint", Status: "UNSTABLE", DurationMillis: 2000},
+			{Name: "Unit tests", Status: "FAILED", DurationMillis: 61400},
+			{Name: "Integration tests", Status: "FAILED", DurationMillis: 1000},
+		}}
+		run.Stages[1].Error = &jenkinsStageError{Message: "script returned exit code 2"}
+
+		field, ok := jenkinsStagesField(run)
+		assert.Assert(t, ok)
+		assert.Equal(t, field.Value, ":warning: Lint (2s)\n"+
+			":x: *Unit tests* (1m1s)\n"+
+			"> script returned exit code 2\n"+
+			":x: Integration tests (1s)")
+	})
+
+	t.Run("should highlight an unstable stage if none failed", func(t *testing.T) {
+		run := jenkinsRun{Stages: []jenkinsStage{
+			{Name: "Build", Status: "SUCCESS", DurationMillis: 1000},
+			{Name: "Tests", Status: "UNSTABLE", DurationMillis: 1000},
+		}}
+
+		field, _ := jenkinsStagesField(run)
+		assert.Equal(t, field.Value, ":white_check_mark: Build (1s)\n:warning: *Tests* (1s)")
+	})
+
+	t.Run("should not count time waiting for input", func(t *testing.T) {
+		run := jenkinsRun{Stages: []jenkinsStage{
+			{Name: "Approve", Status: "PAUSED_PENDING_INPUT", DurationMillis: 600000, PauseDurationMillis: 598000},
+			{Name: "Deploy", Status: "IN_PROGRESS", DurationMillis: 3000},
+		}}
+
+		field, _ := jenkinsStagesField(run)
+		assert.Equal(t, field.Value, ":double_vertical_bar: Approve (2s)\n:arrows_counterclockwise: Deploy (3s)")
+	})
+
+	t.Run("should skip builds without stages", func(t *testing.T) {
+		_, ok := jenkinsStagesField(jenkinsRun{})
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestJenkinsStageErrorMessage(t *testing.T) {
+	s := jenkinsStage{}
+	assert.Equal(t, s.ErrorMessage(), "")
+
+	s.Error = &jenkinsStageError{Message: strings.Repeat("x", 300)}
+	assert.Equal(t, s.ErrorMessage(), strings.Repeat("x", jenkinsErrorLength)+"…")
+}
@@ -3,6 +3,7 @@ package unfurl
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/bndr/gojenkins"
@@ -38,6 +39,13 @@ func TestJenkinsBuildLink(t *testing.T) {
 		httpmock.RegisterResponder("GET", buildUrl,
 			httpmock.NewStringResponder(200, httpmock.File(buildJson).String()))
 
+		// Mock the Pipeline REST API
+		describeUrl := "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master//789/wfapi/describe/"
+		describeJson := fmt.Sprintf("%s/%s", testdataDir, "jenkins-describe-789.json")
+
+		httpmock.RegisterResponder("GET", describeUrl,
+			httpmock.NewStringResponder(200, httpmock.File(describeJson).String()))
+
 		// Initialize the Jenkins client
 		ctx := context.Background()
 		jenkins, jenkinsErr := gojenkins.CreateJenkins(nil, "https://jenkins.corp.org/").Init(ctx)
@@ -50,7 +58,7 @@ func TestJenkinsBuildLink(t *testing.T) {
 		u := Unfurl{
 			Jenkins: jenkins,
 		}
-		a, err := u.jenkinsBuildLink("my-proj", "my-repo", "master", 789)
+		a, err := u.jenkinsBuildLink(jenkinsPath{Job: []string{"my-proj", "my-repo", "master"}, Build: "789"})
 
 		if err != nil {
 			t.Errorf("Error building link: %v", err)
@@ -58,5 +66,51 @@ func TestJenkinsBuildLink(t *testing.T) {
 
 		assert.Equal(t, a.Title, "My Proj » my-repo » master #789")
 		assert.Equal(t, a.TitleLink, "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/789/")
+
+		assert.Equal(t, a.Fields[3].Title, "Stages")
+		assert.Equal(t, a.Fields[3].Value, ":white_check_mark: Checkout (4s)\n"+
+			":white_check_mark: Build (2m23s)\n"+
+			":x: *Unit tests* (3m43s)\n"+
+			"> script returned exit code 1\n"+
+			":white_circle: Deploy to prod")
+	})
+	t.Run("should resolve build permalinks", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("GET", "https://jenkins.corp.org/api/json",
+			httpmock.NewStringResponder(200, ""))
+
+		// A job in a folder, linked through a view with a build permalink
+		jobUrl := "https://jenkins.corp.org/job/ops/job/deploy/api/json"
+		httpmock.RegisterResponder("GET", jobUrl,
+			httpmock.NewStringResponder(200, `{"url":"https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/","lastBuild":{"number":789},"lastUnstableBuild":null}`))
+
+		buildUrl := "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master//789/api/json"
+		buildJson := fmt.Sprintf("%s/%s", testdataDir, "jenkins-build-789.json")
+		httpmock.RegisterResponder("GET", buildUrl,
+			httpmock.NewStringResponder(200, httpmock.File(buildJson).String()))
+
+		// Builds of freestyle jobs have no stages
+		httpmock.RegisterResponder("GET", "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master//789/wfapi/describe/",
+			httpmock.NewStringResponder(404, ""))
+
+		ctx := context.Background()
+		jenkins, err := gojenkins.CreateJenkins(nil, "https://jenkins.corp.org/").Init(ctx)
+		assert.NilError(t, err)
+
+		u := Unfurl{
+			Jenkins: jenkins,
+		}
+
+		URL, _ := url.Parse("https://jenkins.corp.org/view/All/job/ops/job/deploy/lastBuild/console")
+		a, err := u.jenkinsLink(URL)
+		assert.NilError(t, err)
+		assert.Equal(t, a.Title, "My Proj » my-repo » master #789")
+		assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Started"})
+
+		URL, _ = url.Parse("https://jenkins.corp.org/job/ops/job/deploy/lastUnstableBuild/")
+		_, err = u.jenkinsLink(URL)
+		assert.Error(t, err, "jenkins job ops/job/deploy has no lastUnstableBuild")
 	})
 }
@@ -0,0 +1,161 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bndr/gojenkins"
+	"github.com/slack-go/slack"
+)
+
+// jenkinsTestReport is the JUnit test report of a build, limited to what is
+// shown in unfurls as reports include the output of each test.
+type jenkinsTestReport struct {
+	FailCount int `json:"failCount"`
+	PassCount int `json:"passCount"`
+	SkipCount int `json:"skipCount"`
+	Suites    []struct {
+		Cases []jenkinsTestCase `json:"cases"`
+	} `json:"suites"`
+}
+
+// jenkinsTestCase is a test case in a test report
+type jenkinsTestCase struct {
+	ClassName    string `json:"className"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	Age          int    `json:"age"`
+	ErrorDetails string `json:"errorDetails"`
+}
+
+// Failed returns true if the test case failed in this build. Regressions are
+// test cases that passed in the previous build.
+func (c jenkinsTestCase) Failed() bool {
+	return c.Status == gojenkins.RESULT_STATUS_FAILED || c.Status == gojenkins.STATUS_REGRESSION
+}
+
+// String returns the test case with its class name without package, e.g.
+// UserServiceTest.testCreate
+func (c jenkinsTestCase) String() string {
+	class := c.ClassName
+	if i := strings.LastIndex(class, "."); i >= 0 {
+		class = class[i+1:]
+	}
+
+	if class == "" {
+		return c.Name
+	}
+
+	return fmt.Sprintf("%s.%s", class, c.Name)
+}
+
+// Total returns the number of test cases
+func (r jenkinsTestReport) Total() int {
+	return r.FailCount + r.PassCount + r.SkipCount
+}
+
+// Failed returns the failed test cases in report order
+func (r jenkinsTestReport) Failed() []jenkinsTestCase {
+	var failed []jenkinsTestCase
+	for _, s := range r.Suites {
+		for _, c := range s.Cases {
+			if c.Failed() {
+				failed = append(failed, c)
+			}
+		}
+	}
+
+	return failed
+}
+
+// Regressions returns the number of test cases failing since this build
+func (r jenkinsTestReport) Regressions() int {
+	n := 0
+	for _, c := range r.Failed() {
+		if c.Status == gojenkins.STATUS_REGRESSION {
+			n++
+		}
+	}
+
+	return n
+}
+
+// jenkinsTestResults returns the test report of a build, or nil if the build
+// did not publish test results.
+func (u *Unfurl) jenkinsTestResults(ctx context.Context, build *gojenkins.Build) (*jenkinsTestReport, error) {
+	var report jenkinsTestReport
+
+	res, err := u.Jenkins.Requester.GetJSON(ctx, build.Base+"/testReport", &report, map[string]string{
+		"tree": "failCount,passCount,skipCount,suites[cases[className,name,status,age,errorDetails]]",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return &report, nil
+	case http.StatusNotFound:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("HTTP request failed with unexpected status code %d", res.StatusCode)
+}
+
+// jenkinsTestFields returns the test counts of a report, and the first
+// limit failed test cases with their error and how many builds they have
+// been failing for.
+func jenkinsTestFields(report *jenkinsTestReport, limit int) []slack.AttachmentField {
+	if report == nil || report.Total() == 0 {
+		return nil
+	}
+
+	summary := fmt.Sprintf("%d tests, %d failed", report.Total(), report.FailCount)
+	if n := report.Regressions(); n > 0 {
+		summary += fmt.Sprintf(" (%d new)", n)
+	}
+	if report.SkipCount > 0 {
+		summary += fmt.Sprintf(", %d skipped", report.SkipCount)
+	}
+
+	fields := []slack.AttachmentField{
+		{
+			Title: "Tests",
+			Value: summary,
+			Short: true,
+		},
+	}
+
+	failed := report.Failed()
+	if len(failed) == 0 || limit <= 0 {
+		return fields
+	}
+
+	lines := []string{}
+	for i, c := range failed {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(failed)-limit))
+			break
+		}
+
+		age := "new"
+		if c.Age > 1 {
+			age = fmt.Sprintf("failing for %d builds", c.Age)
+		}
+
+		line := fmt.Sprintf("• `%s` (%s)", c, age)
+		if msg := jenkinsErrorLine(c.ErrorDetails); msg != "" {
+			line += fmt.Sprintf("\n> %s", msg)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return append(fields, slack.AttachmentField{
+		Title: "Failed Tests",
+		Value: strings.Join(lines, "\n"),
+		Short: false,
+	})
+}
@@ -0,0 +1,79 @@
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/bndr/gojenkins"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"gotest.tools/assert"
+)
+
+func TestJenkinsTestFields(t *testing.T) {
+	var report jenkinsTestReport
+	assert.NilError(t, json.Unmarshal([]byte(utils.ReadTestdataFile("jenkins-test-report-790.json")), &report))
+
+	t.Run("should summarise the test results", func(t *testing.T) {
+		fields := jenkinsTestFields(&report, 5)
+
+		assert.DeepEqual(t, fieldTitles(fields), []string{"Tests", "Failed Tests"})
+		assert.Equal(t, fields[0].Value, "42 tests, 3 failed (2 new), 1 skipped")
+		assert.Equal(t, fields[1].Value, "• `ClusterTest.testScaleUp` (failing for 4 builds)\n"+
+			"> java.net.SocketTimeoutException: Read timed out\n"+
+			"• `NodePoolTest.testAutoscale` (new)\n"+
+			"> expected:<3> but was:<2>\n"+
+			"• `NodePoolTest.testDrain` (new)")
+	})
+
+	t.Run("should list at most limit failed tests", func(t *testing.T) {
+		fields := jenkinsTestFields(&report, 1)
+
+		assert.Equal(t, fields[1].Value, "• `ClusterTest.testScaleUp` (failing for 4 builds)\n"+
+			"> java.net.SocketTimeoutException: Read timed out\n"+
+			"…and 2 more")
+	})
+
+	t.Run("should skip builds without test results", func(t *testing.T) {
+		assert.Equal(t, len(jenkinsTestFields(nil, 5)), 0)
+		assert.Equal(t, len(jenkinsTestFields(&jenkinsTestReport{}, 5)), 0)
+	})
+}
+
+func TestJenkinsBuildLinkTestResults(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "https://jenkins.corp.org/api/json",
+		httpmock.NewStringResponder(200, ""))
+
+	httpmock.RegisterResponder("GET", "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/api/json",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "jenkins-build.json")).String()))
+
+	buildBase := "https://jenkins.corp.org/job/my-proj/job/my-repo/job/master//790"
+	httpmock.RegisterResponder("GET", buildBase+"/api/json",
+		httpmock.NewStringResponder(200, `{"number":790,"result":"UNSTABLE","fullDisplayName":"My Proj » my-repo » master #790","url":"https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/790/","duration":61000}`))
+	httpmock.RegisterResponder("GET", buildBase+"/wfapi/describe/",
+		httpmock.NewStringResponder(404, ""))
+	httpmock.RegisterResponderWithQuery("GET", buildBase+"/testReport/api/json",
+		"tree=failCount,passCount,skipCount,suites[cases[className,name,status,age,errorDetails]]",
+		httpmock.NewStringResponder(200, httpmock.File(fmt.Sprintf("%s/%s", testdataDir, "jenkins-test-report-790.json")).String()))
+
+	jenkins, err := gojenkins.CreateJenkins(nil, "https://jenkins.corp.org/").Init(context.Background())
+	assert.NilError(t, err)
+
+	u := Unfurl{
+		Jenkins: jenkins,
+		Config:  &utils.Config{JenkinsFailedTests: 2},
+	}
+
+	URL, _ := url.Parse("https://jenkins.corp.org/job/my-proj/job/my-repo/job/master/790/")
+	a, err := u.jenkinsLink(URL)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, fieldTitles(a.Fields), []string{"Status", "Duration", "Started", "Tests", "Failed Tests"})
+	assert.Equal(t, a.Fields[3].Value, "42 tests, 3 failed (2 new), 1 skipped")
+}
@@ -0,0 +1,234 @@
+package unfurl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/slack-go/slack"
+)
+
+const (
+	JiraIcon = "https://wac-cdn.atlassian.com/assets/img/favicons/jira/favicon-32x32.png"
+
+	// jiraSprintField is the name used for the sprint field in the field
+	// list, as the ID of the sprint custom field differs between instances.
+	jiraSprintField = "sprint"
+)
+
+// jiraDefaultFields are the fields shown when no fields are configured
+var jiraDefaultFields = []string{"status", "priority", "assignee", "reporter", "issuetype", "fixVersions", jiraSprintField}
+
+// jiraFieldTitles are the titles of the system fields
+var jiraFieldTitles = map[string]string{
+	"status":        "Status",
+	"priority":      "Priority",
+	"assignee":      "Assignee",
+	"reporter":      "Reporter",
+	"issuetype":     "Type",
+	"fixVersions":   "Fix Versions",
+	"components":    "Components",
+	"labels":        "Labels",
+	"resolution":    "Resolution",
+	jiraSprintField: "Sprint",
+}
+
+// jiraStatusColors are the attachment colors for Jira status categories
+var jiraStatusColors = map[string]string{
+	"new":           "#42526E",
+	"indeterminate": "#0052CC",
+	"done":          "#36B37E",
+}
+
+// jiraIssueKey returns the issue key from /browse/KEY-123 links and from
+// board links with a ?selectedIssue=KEY-123 query.
+func jiraIssueKey(URL *url.URL) (string, bool) {
+	if key := URL.Query().Get("selectedIssue"); jira.IsIssueKey(key) {
+		return key, true
+	}
+
+	if strings.HasPrefix(URL.Path, "/browse/") {
+		key := strings.TrimSuffix(strings.TrimPrefix(URL.Path, "/browse/"), "/")
+		if jira.IsIssueKey(key) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// jiraFields returns the configured fields as field IDs and titles. Fields
+// may be given a title with "customfield_10200=Team".
+func (u *Unfurl) jiraFields() ([]string, map[string]string) {
+	configured := jiraDefaultFields
+	if u.Config != nil && len(u.Config.JiraFields) > 0 {
+		configured = u.Config.JiraFields
+	}
+
+	fields := make([]string, 0, len(configured))
+	titles := make(map[string]string, len(configured))
+	for _, f := range configured {
+		parts := strings.SplitN(strings.TrimSpace(f), "=", 2)
+		if parts[0] == "" {
+			continue
+		}
+
+		title, ok := jiraFieldTitles[parts[0]]
+		if !ok {
+			title = parts[0]
+		}
+		if len(parts) == 2 {
+			title = parts[1]
+		}
+
+		fields = append(fields, parts[0])
+		titles[parts[0]] = title
+	}
+
+	return fields, titles
+}
+
+// jiraSprintFieldID returns the ID of the sprint custom field
+func (u *Unfurl) jiraSprintFieldID(ctx context.Context) (string, error) {
+	if u.Config != nil && u.Config.JiraSprintField != "" {
+		return u.Config.JiraSprintField, nil
+	}
+
+	return u.Jira.SprintField(ctx)
+}
+
+// jiraLink returns a Slack Attachment for Jira issue links
+func (u *Unfurl) jiraLink(URL *url.URL) (slack.Attachment, error) {
+	var attachement slack.Attachment
+
+	key, ok := jiraIssueKey(URL)
+	if !ok {
+		return attachement, fmt.Errorf("jira link not supported")
+	}
+
+	ctx := context.Background()
+	fields, titles := u.jiraFields()
+
+	// Request only the fields we show, with the sprint field by its ID
+	sprintField := ""
+	request := []string{"summary"}
+	for _, f := range fields {
+		if f != jiraSprintField {
+			request = append(request, f)
+			continue
+		}
+
+		var err error
+		sprintField, err = u.jiraSprintFieldID(ctx)
+		if err != nil {
+			u.Logger.WithError(err).Warn("Failed to find the Jira sprint field")
+		}
+		if sprintField != "" {
+			request = append(request, sprintField)
+		}
+	}
+
+	issue, err := u.Jira.Issue(ctx, key, request...)
+	if err != nil {
+		return attachement, fmt.Errorf("failed to get jira issue %s: %w", key, err)
+	}
+
+	for _, f := range fields {
+		value := jiraFieldValue(issue, f, sprintField)
+		if value == "" {
+			continue
+		}
+
+		attachement.Fields = append(attachement.Fields, slack.AttachmentField{
+			Title: titles[f],
+			Value: value,
+			Short: true,
+		})
+	}
+
+	if issue.Fields.Status != nil {
+		attachement.Color = jiraStatusColors[issue.Fields.Status.StatusCategory.Key]
+	}
+
+	attachement.Title = fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary)
+	attachement.TitleLink = u.Jira.BrowseURL(issue.Key)
+	attachement.FooterIcon = JiraIcon
+	attachement.Footer = "Jira"
+
+	return attachement, nil
+}
+
+// jiraFieldValue returns the text shown for a field of an issue
+func jiraFieldValue(issue jira.Issue, field string, sprintField string) string {
+	switch field {
+	case "assignee":
+		if issue.Fields.Assignee == nil {
+			return "Unassigned"
+		}
+		return issue.Fields.Assignee.DisplayName
+
+	case jiraSprintField:
+		return jiraSprint(issue.Sprints(sprintField))
+	}
+
+	return issue.Value(field)
+}
+
+// jiraSprint returns the active sprint, or else the most recent one
+func jiraSprint(sprints []jira.Sprint) string {
+	if len(sprints) == 0 {
+		return ""
+	}
+
+	for _, s := range sprints {
+		if strings.EqualFold(s.State, "active") {
+			return s.Name
+		}
+	}
+
+	return sprints[len(sprints)-1].Name
+}
+
+// jiraIssuesField returns a field listing the Jira issues with the given keys
+// and their status, for issue keys mentioned in Bitbucket unfurls.
+func (u *Unfurl) jiraIssuesField(keys []string) (slack.AttachmentField, bool) {
+	if u.Jira == nil || len(keys) == 0 {
+		return slack.AttachmentField{}, false
+	}
+
+	limit := len(keys)
+	if u.Config != nil && u.Config.JiraIssueLimit > 0 && u.Config.JiraIssueLimit < limit {
+		limit = u.Config.JiraIssueLimit
+	}
+
+	issues, err := u.Jira.Issues(context.Background(), keys[:limit], "status")
+	if err != nil {
+		u.Logger.WithError(err).WithField("keys", keys).Warn("Failed to get Jira issues")
+		return slack.AttachmentField{}, false
+	}
+
+	if len(issues) == 0 {
+		return slack.AttachmentField{}, false
+	}
+
+	lines := make([]string, 0, len(issues)+1)
+	for _, issue := range issues {
+		line := fmt.Sprintf("<%s|%s>", u.Jira.BrowseURL(issue.Key), issue.Key)
+		if issue.Fields.Status != nil {
+			line = fmt.Sprintf("%s %s", line, issue.Fields.Status.Name)
+		}
+		lines = append(lines, line)
+	}
+
+	if more := len(keys) - limit; more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+
+	return slack.AttachmentField{
+		Title: "Jira",
+		Value: strings.Join(lines, "\n"),
+		Short: true,
+	}, true
+}
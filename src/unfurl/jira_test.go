@@ -0,0 +1,139 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+)
+
+const (
+	jiraServer = "jira.corp.org"
+	jiraIssue  = "MYPROJ-123"
+)
+
+func TestJiraIssueKey(t *testing.T) {
+	links := map[string]string{
+		"/browse/MYPROJ-123":  "MYPROJ-123",
+		"/browse/MYPROJ-123/": "MYPROJ-123",
+		"/secure/RapidBoard.jspa?rapidView=12&selectedIssue=MY_PROJ2-7": "MY_PROJ2-7",
+		"/projects/MYPROJ/issues/?selectedIssue=MYPROJ-9":               "MYPROJ-9",
+	}
+
+	for link, key := range links {
+		t.Run(fmt.Sprintf("should find %s in %s", key, link), func(t *testing.T) {
+			URL, _ := url.Parse(link)
+			k, ok := jiraIssueKey(URL)
+			assert.Equal(t, true, ok)
+			assert.Equal(t, key, k)
+		})
+	}
+
+	for _, link := range []string{"/browse/MYPROJ", "/browse/myproj-123", "/secure/Dashboard.jspa", "/browse/MYPROJ-123/foo", "/browse/MYPROJ-0"} {
+		t.Run(fmt.Sprintf("should not find a key in %s", link), func(t *testing.T) {
+			URL, _ := url.Parse(link)
+			_, ok := jiraIssueKey(URL)
+			assert.Equal(t, false, ok)
+		})
+	}
+}
+
+func TestJiraLink(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	issueAPI := fmt.Sprintf(jira.APIPaths["base"], jiraServer, fmt.Sprintf(jira.APIPaths["issue"], jiraIssue))
+	httpmock.RegisterResponder("GET", issueAPI,
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-issue-123.json")))
+	httpmock.RegisterResponder("GET", fmt.Sprintf(jira.APIPaths["base"], jiraServer, jira.APIPaths["fields"]),
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-fields.json")))
+
+	URL := url.URL{Scheme: "https", Host: jiraServer, Path: "/browse/" + jiraIssue}
+
+	unfurl := func(fields ...string) *Unfurl {
+		return &Unfurl{
+			Logger: logrus.StandardLogger(),
+			Jira:   &jira.Client{Server: jiraServer, PAT: "my-token"},
+			Config: &utils.Config{JiraServer: jiraServer, JiraFields: fields},
+		}
+	}
+
+	t.Run("should unfurl issue with default fields", func(t *testing.T) {
+		a, err := unfurl().jiraLink(&URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, "MYPROJ-123 Add support for unfurling Jira issues", a.Title)
+		assert.Equal(t, "https://jira.corp.org/browse/MYPROJ-123", a.TitleLink)
+		assert.Equal(t, "#0052CC", a.Color)
+
+		values := map[string]string{}
+		for _, f := range a.Fields {
+			values[f.Title] = f.Value
+		}
+		assert.DeepEqual(t, map[string]string{
+			"Status":       "In Progress",
+			"Priority":     "High",
+			"Assignee":     "User A",
+			"Reporter":     "User B",
+			"Type":         "Story",
+			"Fix Versions": "1.2.0, 1.3.0",
+			"Sprint":       "Sprint 42, the big one",
+		}, values)
+	})
+
+	t.Run("should unfurl configured fields in order", func(t *testing.T) {
+		a, err := unfurl("customfield_10200=Team", "status").jiraLink(&URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, 2, len(a.Fields))
+		assert.Equal(t, "Team", a.Fields[0].Title)
+		assert.Equal(t, "Platform", a.Fields[0].Value)
+		assert.Equal(t, "Status", a.Fields[1].Title)
+	})
+
+	t.Run("should request only the configured fields", func(t *testing.T) {
+		httpmock.RegisterResponderWithQuery("GET", issueAPI, "fields=summary,status",
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-issue-123.json")))
+
+		_, err := unfurl("status").jiraLink(&URL)
+		assert.NilError(t, err)
+
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["GET "+issueAPI+"?fields=summary%2Cstatus"])
+	})
+}
+
+func TestJiraIssuesField(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	searchAPI := fmt.Sprintf(jira.APIPaths["base"], jiraServer, jira.APIPaths["search"])
+	httpmock.RegisterResponder("GET", searchAPI,
+		httpmock.NewStringResponder(200, utils.ReadTestdataFile("jira-search.json")))
+
+	u := &Unfurl{
+		Logger: logrus.StandardLogger(),
+		Jira:   &jira.Client{Server: jiraServer, PAT: "my-token"},
+		Config: &utils.Config{JiraIssueLimit: 2},
+	}
+
+	t.Run("should cap the number of issues", func(t *testing.T) {
+		field, ok := u.jiraIssuesField([]string{"PROJ-1396", "PROJ-1400", "PROJ-1", "PROJ-2"})
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "<https://jira.corp.org/browse/PROJ-1396|PROJ-1396> In Review\n<https://jira.corp.org/browse/PROJ-1400|PROJ-1400> Done\nand 2 more", field.Value)
+	})
+
+	t.Run("should not add a field without keys", func(t *testing.T) {
+		_, ok := u.jiraIssuesField([]string{})
+		assert.Equal(t, false, ok)
+	})
+
+	t.Run("should not add a field without Jira", func(t *testing.T) {
+		_, ok := (&Unfurl{}).jiraIssuesField([]string{"PROJ-1396"})
+		assert.Equal(t, false, ok)
+	})
+}
@@ -1,22 +1,49 @@
 package unfurl
 
 import (
+	"errors"
 	"net/url"
+	"regexp"
 
 	"github.com/bndr/gojenkins"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/accounts"
 	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket/cloud"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/confluence"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/github"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/gitlab"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/jira"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/redact"
 	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
 )
 
+// errUnsupportedDomain is returned when a link does not belong to any of the
+// configured servers.
+var errUnsupportedDomain = errors.New("unsupported link domain")
+
 // Unfurl is an inverted control structure for the unfurl package
 type Unfurl struct {
-	Logger    *logrus.Logger
-	Jenkins   *gojenkins.Jenkins
-	Bitbucket *bitbucket.Client
-	Config    *utils.Config
+	Logger         *logrus.Logger
+	Jenkins        *gojenkins.Jenkins
+	Bitbucket      *bitbucket.Client
+	BitbucketCloud bitbucket.Backend
+	GitHub         *github.Client
+	GitLab         *gitlab.Client
+	Jira           *jira.Client
+	Confluence     *confluence.Client
+	Config         *utils.Config
+	Slack          SlackClient
+	Accounts       *accounts.Manager
+	Redactor       *redact.Redactor
+
+	// JenkinsErrorPatterns are the compiled JenkinsConsoleErrorPatterns
+	JenkinsErrorPatterns []*regexp.Regexp
+
+	// audience is who the links being unfurled are shared with
+	audience *audience
 }
 
 // Links unfurls a all links from a Slack LinkSharedEvent and returns a
@@ -25,39 +52,166 @@ func (u *Unfurl) Links(event *slackevents.LinkSharedEvent) (map[string]slack.Att
 	// Create a new map to store link unfurled data as Slack attachments
 	unfurls := make(map[string]slack.Attachment, len(event.Links))
 
+	// Read as the user sharing the links if they have linked their account
+	u = u.sharedWith(event.User, event.Channel)
+
 	// Unfurl all the shared links
 	for _, link := range event.Links {
-		var attachement slack.Attachment
-		var err error
-
 		// Parse the link
 		URL, urlErr := url.Parse(link.URL)
 		if urlErr != nil {
-			u.Logger.Errorf("Error parsing url: %s", err)
+			u.Logger.Errorf("Error parsing url: %s", urlErr)
 			continue
 		}
 
 		u.Logger.Infof("Unfurling link: %s", URL.String())
 
-		// Check the link domain, discard if not supported
-		switch link.Domain {
-		case u.Config.BitbucketServer:
-			attachement, err = u.bitbucketLink(URL)
-
-		case u.Config.JenkinsServer:
-			attachement, err = u.jenkinsLink(URL)
-
-		default:
+		attachement, err := u.link(URL, link.Domain)
+		if errors.Is(err, errUnsupportedDomain) {
 			u.Logger.Debugf("Unsupported link domain: %s", link.Domain)
 			continue
 		}
 
+		if errors.Is(err, errRestricted) {
+			u.Logger.WithField("link", link).Info("Not unfurling restricted link")
+			continue
+		}
+
 		if err != nil {
 			u.Logger.WithError(err).WithField("link", link).Error("Failed to unfurl link")
 		} else {
-			unfurls[link.URL] = attachement
+			unfurls[link.URL] = withActionLink(attachement, link.URL, link.Domain)
 		}
 	}
 
 	return unfurls, nil
 }
+
+// link unfurls a single link by dispatching it to the provider for the
+// link domain. Secrets are redacted from the unfurled link.
+func (u *Unfurl) link(URL *url.URL, domain string) (slack.Attachment, error) {
+	var attachement slack.Attachment
+	var err error
+
+	// Check the link domain, discard if not supported
+	switch domain {
+	case u.Config.BitbucketServer:
+		attachement, err = u.bitbucketLink(URL)
+
+	case cloud.Domain:
+		if u.BitbucketCloud == nil {
+			return slack.Attachment{}, errUnsupportedDomain
+		}
+		attachement, err = u.bitbucketCloudLink(URL)
+
+	case u.Config.JenkinsServer:
+		attachement, err = u.jenkinsLink(URL)
+
+	case u.Config.GitHubServer:
+		if u.GitHub == nil {
+			return slack.Attachment{}, errUnsupportedDomain
+		}
+		attachement, err = u.githubLink(URL)
+
+	case u.Config.GitLabServer:
+		if u.GitLab == nil {
+			return slack.Attachment{}, errUnsupportedDomain
+		}
+		attachement, err = u.gitlabLink(URL)
+
+	case u.Config.JiraServer:
+		if u.Jira == nil {
+			return slack.Attachment{}, errUnsupportedDomain
+		}
+		attachement, err = u.jiraLink(URL)
+
+	case u.Config.ConfluenceServer:
+		if u.Confluence == nil {
+			return slack.Attachment{}, errUnsupportedDomain
+		}
+		attachement, err = u.confluenceLink(URL)
+
+	default:
+		return slack.Attachment{}, errUnsupportedDomain
+	}
+
+	return u.redact(attachement), err
+}
+
+// redactor returns the configured redactor, or the default detectors
+func (u *Unfurl) redactor() *redact.Redactor {
+	if u.Redactor == nil {
+		return redact.Default
+	}
+
+	return u.Redactor
+}
+
+// redact returns the attachment with secrets redacted from all the text
+// shown in Slack.
+func (u *Unfurl) redact(attachement slack.Attachment) slack.Attachment {
+	r := u.redactor()
+
+	attachement.Fallback = r.String(attachement.Fallback)
+	attachement.Pretext = r.String(attachement.Pretext)
+	attachement.AuthorName = r.String(attachement.AuthorName)
+	attachement.Title = r.String(attachement.Title)
+	attachement.Text = r.String(attachement.Text)
+	attachement.Footer = r.String(attachement.Footer)
+
+	fields := make([]slack.AttachmentField, len(attachement.Fields))
+	for i, field := range attachement.Fields {
+		field.Title = r.String(field.Title)
+		field.Value = r.String(field.Value)
+		fields[i] = field
+	}
+	if attachement.Fields != nil {
+		attachement.Fields = fields
+	}
+
+	actions := make([]slack.AttachmentAction, len(attachement.Actions))
+	for i, action := range attachement.Actions {
+		action.Text = r.String(action.Text)
+		if action.Confirm != nil {
+			confirm := *action.Confirm
+			confirm.Title = r.String(confirm.Title)
+			confirm.Text = r.String(confirm.Text)
+			action.Confirm = &confirm
+		}
+		actions[i] = action
+	}
+	if attachement.Actions != nil {
+		attachement.Actions = actions
+	}
+
+	return attachement
+}
+
+// sharedWith returns a copy of the Unfurl for links shared by a Slack user in
+// a channel. Bitbucket is read as the user when they have linked their
+// account.
+func (u *Unfurl) sharedWith(slackUserID string, channelID string) *Unfurl {
+	uu := *u
+	uu.audience = &audience{SlackUserID: slackUserID, ChannelID: channelID}
+
+	if client, ok := u.bitbucketUserClient(slackUserID); ok {
+		uu.Bitbucket = client
+		uu.audience.Linked = true
+		uu.audience.bot = u.Bitbucket
+	}
+
+	return &uu
+}
+
+// asBot returns a copy of the Unfurl that reads from Bitbucket with the bot
+// credentials.
+func (u *Unfurl) asBot() *Unfurl {
+	if u.audience == nil || u.audience.bot == nil {
+		return u
+	}
+
+	uu := *u
+	uu.Bitbucket = u.audience.bot
+
+	return &uu
+}
@@ -0,0 +1,36 @@
+package unfurl
+
+import (
+	"testing"
+
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+func TestRedact(t *testing.T) {
+	secret := "AKIA" + "IOSFODNN7EXAMPLE"
+
+	u := Unfurl{}
+	a := u.redact(slack.Attachment{
+		Title:  "#1 Rotate " + secret,
+		Text:   "password=hunter2",
+		Fields: []slack.AttachmentField{{Title: "Key", Value: secret}},
+		Actions: []slack.AttachmentAction{{
+			Text:    "Merge",
+			Confirm: &slack.ConfirmationField{Text: "Merge _Rotate " + secret + "_?"},
+		}},
+	})
+
+	t.Run("should redact text", func(t *testing.T) {
+		assert.Equal(t, "#1 Rotate [REDACTED]", a.Title)
+		assert.Equal(t, "password=[REDACTED]", a.Text)
+	})
+
+	t.Run("should redact fields", func(t *testing.T) {
+		assert.Equal(t, "[REDACTED]", a.Fields[0].Value)
+	})
+
+	t.Run("should redact action confirmations", func(t *testing.T) {
+		assert.Equal(t, "Merge _Rotate [REDACTED]_?", a.Actions[0].Confirm.Text)
+	})
+}
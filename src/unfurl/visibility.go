@@ -0,0 +1,194 @@
+package unfurl
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// VisibilityPolicyOff unfurls all links in full
+	VisibilityPolicyOff = "off"
+	// VisibilityPolicyTitle unfurls restricted links with their title only
+	VisibilityPolicyTitle = "title"
+	// VisibilityPolicyRestricted unfurls restricted links as a placeholder
+	VisibilityPolicyRestricted = "restricted"
+	// VisibilityPolicyHide does not unfurl restricted links at all
+	VisibilityPolicyHide = "hide"
+)
+
+// errRestricted is returned for links that should not be unfurled at all
+// because of the visibility policy.
+var errRestricted = errors.New("link is restricted")
+
+// audience is who an unfurl is shared with
+type audience struct {
+	// SlackUserID is the user who shared the link
+	SlackUserID string
+	// ChannelID is the channel the link was shared in
+	ChannelID string
+	// Linked is true when Bitbucket is read as the user who shared the link
+	Linked bool
+
+	// bot is the Bitbucket client with the bot credentials when Bitbucket is
+	// read as the user who shared the link
+	bot *bitbucket.Client
+}
+
+// visibilityPolicy returns the configured visibility policy
+func (u *Unfurl) visibilityPolicy() string {
+	if u.Config == nil || u.Config.VisibilityPolicy == "" {
+		return VisibilityPolicyOff
+	}
+
+	return u.Config.VisibilityPolicy
+}
+
+// bitbucketVisible returns true if the content of a repo can be shown to
+// everyone the link was shared with. That is when the repo is public, or when
+// the channel is private and the user who shared the link can read the repo.
+func (u *Unfurl) bitbucketVisible(proj string, repo string) (bool, error) {
+	if u.audience == nil || u.visibilityPolicy() == VisibilityPolicyOff {
+		return true, nil
+	}
+
+	r, err := u.Bitbucket.Repository(proj, repo)
+	if err != nil {
+		// The user who shared the link can not read the repo
+		if u.audience.Linked {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if r.Public {
+		return true, nil
+	}
+
+	return u.bitbucketAudienceCanRead(func(email string) (bool, error) {
+		return u.Bitbucket.HasRepositoryPermission(proj, repo, email, bitbucket.PermissionRepoRead)
+	})
+}
+
+// bitbucketProjectVisible returns true if the content of a project can be
+// shown to everyone the link was shared with, the same way as for repos.
+func (u *Unfurl) bitbucketProjectVisible(key string) (bool, error) {
+	if u.audience == nil || u.visibilityPolicy() == VisibilityPolicyOff {
+		return true, nil
+	}
+
+	p, err := u.Bitbucket.Project(key)
+	if err != nil {
+		// The user who shared the link can not read the project
+		if u.audience.Linked {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if p.Public {
+		return true, nil
+	}
+
+	return u.bitbucketAudienceCanRead(func(email string) (bool, error) {
+		return u.Bitbucket.HasProjectPermission(key, email, bitbucket.PermissionProjectRead)
+	})
+}
+
+// bitbucketUserVisible returns true if the profile of a user, such as their
+// email address, can be shown to everyone the link was shared with. That is
+// when the channel is private and the user who shared the link can log in to
+// Bitbucket, as every Bitbucket user can see other users.
+func (u *Unfurl) bitbucketUserVisible() (bool, error) {
+	if u.audience == nil || u.visibilityPolicy() == VisibilityPolicyOff {
+		return true, nil
+	}
+
+	return u.bitbucketAudienceCanRead(func(email string) (bool, error) {
+		return u.Bitbucket.HasGlobalPermission(email, bitbucket.PermissionLicensedUser)
+	})
+}
+
+// bitbucketAudienceCanRead returns true if the link was shared in a private
+// channel by a user who can read it. Linked users read Bitbucket with their
+// own account, otherwise canRead checks the user by their email address.
+func (u *Unfurl) bitbucketAudienceCanRead(canRead func(email string) (bool, error)) (bool, error) {
+	channel, err := u.Slack.GetConversationInfo(u.audience.ChannelID, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to get channel info: %w", err)
+	}
+
+	// Anyone in the workspace can read public channels
+	if !channel.IsPrivate && !channel.IsIM && !channel.IsMpIM {
+		return false, nil
+	}
+
+	if u.audience.Linked {
+		return true, nil
+	}
+
+	user, err := u.Slack.GetUserInfo(u.audience.SlackUserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	if user.Profile.Email == "" {
+		return false, nil
+	}
+
+	return canRead(user.Profile.Email)
+}
+
+// publicOnlyLink returns the attachment from unfurl, restricted by the
+// visibility policy unless public reports the repo as public. It is used for
+// providers that are always read with the bot credentials, where only public
+// repos are known to be visible to everyone in the channel.
+func (u *Unfurl) publicOnlyLink(public func() (bool, error), unfurl func() (slack.Attachment, error)) (slack.Attachment, error) {
+	attachement, err := unfurl()
+	if err != nil {
+		return attachement, err
+	}
+
+	if u.audience == nil || u.visibilityPolicy() == VisibilityPolicyOff {
+		return attachement, nil
+	}
+
+	visible, err := public()
+	if err != nil {
+		return slack.Attachment{}, err
+	}
+
+	if visible {
+		return attachement, nil
+	}
+
+	return u.restrict(attachement)
+}
+
+// restrict returns a reduced attachment according to the visibility policy
+func (u *Unfurl) restrict(attachement slack.Attachment) (slack.Attachment, error) {
+	switch u.visibilityPolicy() {
+	case VisibilityPolicyTitle:
+		return slack.Attachment{
+			Title:      attachement.Title,
+			TitleLink:  attachement.TitleLink,
+			Footer:     attachement.Footer,
+			FooterIcon: attachement.FooterIcon,
+		}, nil
+
+	case VisibilityPolicyRestricted:
+		return slack.Attachment{
+			Title: ":lock: Restricted",
+			Text:  "This link is not visible to everyone in this channel.",
+		}, nil
+
+	case VisibilityPolicyHide:
+		return slack.Attachment{}, errRestricted
+	}
+
+	return attachement, nil
+}
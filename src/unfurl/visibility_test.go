@@ -0,0 +1,153 @@
+package unfurl
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/evry-ace/link-unfurl-slack-bot/src/bitbucket"
+	"github.com/evry-ace/link-unfurl-slack-bot/src/utils"
+	"github.com/jarcoal/httpmock"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"gotest.tools/assert"
+)
+
+func TestBitbucketVisibility(t *testing.T) {
+	linkPath := fmt.Sprintf(bitbucket.APIPaths["pullRequest"], project, repo, pr)
+	linkUrl := url.URL{Path: "/" + linkPath, Scheme: "https", Host: server}
+
+	repoAPI := fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo))
+	usersAPI := fmt.Sprintf(bitbucket.APIPaths["base"], server, bitbucket.APIPaths["users"])
+
+	privateRepo := strings.Replace(utils.ReadTestdataFile("bitbucket-repo.json"), `"public": true`, `"public": false`, -1)
+
+	mock := func(repoJSON string) {
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, linkPath),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-pull-requests-297.json")))
+		httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.StatusPaths["base"], server,
+			fmt.Sprintf(bitbucket.StatusPaths["status"], "a68adcc6e8461db084acf7e76401d3c1542bb8ad")),
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-build-status-654382.json")))
+		httpmock.RegisterResponder("GET", repoAPI, httpmock.NewStringResponder(200, repoJSON))
+		httpmock.RegisterResponder("GET", usersAPI,
+			httpmock.NewStringResponder(200, utils.ReadTestdataFile("bitbucket-users-permission.json")))
+	}
+
+	unfurl := func(policy string, private bool, email string) *Unfurl {
+		s := &fakeSlack{}
+		s.channel.IsPrivate = private
+		s.user.Profile.Email = email
+
+		u := &Unfurl{
+			Logger:    logrus.StandardLogger(),
+			Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+			Slack:     s,
+			Config:    &utils.Config{BitbucketServer: server, VisibilityPolicy: policy},
+		}
+
+		return u.sharedWith("U123", "C123")
+	}
+
+	t.Run("should unfurl public repos in public channels", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(utils.ReadTestdataFile("bitbucket-repo.json"))
+
+		a, err := unfurl(VisibilityPolicyRestricted, false, "").bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+		assert.Equal(t, "My awesome description", a.Text)
+	})
+
+	t.Run("should restrict private repos in public channels", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(privateRepo)
+
+		a, err := unfurl(VisibilityPolicyRestricted, false, "user.d@corp.org").bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+		assert.Equal(t, ":lock: Restricted", a.Title)
+		assert.Equal(t, 0, len(a.Fields))
+	})
+
+	t.Run("should only show the title with the title policy", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(privateRepo)
+
+		a, err := unfurl(VisibilityPolicyTitle, false, "user.d@corp.org").bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+		assert.Equal(t, "#297 My new feature", a.Title)
+		assert.Equal(t, "", a.Text)
+		assert.Equal(t, 0, len(a.Actions))
+	})
+
+	t.Run("should unfurl private repos in private channels when the user has access", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(privateRepo)
+
+		a, err := unfurl(VisibilityPolicyHide, true, "user.d@corp.org").bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+		assert.Equal(t, "My awesome description", a.Text)
+	})
+
+	t.Run("should hide private repos in private channels when the user has no access", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(privateRepo)
+
+		_, err := unfurl(VisibilityPolicyHide, true, "user.x@corp.org").bitbucketLink(&linkUrl)
+		assert.Equal(t, errRestricted, err)
+	})
+
+	t.Run("should not check anything when the policy is off", func(t *testing.T) {
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+		mock(privateRepo)
+
+		a, err := unfurl(VisibilityPolicyOff, false, "").bitbucketLink(&linkUrl)
+		assert.NilError(t, err)
+		assert.Equal(t, "My awesome description", a.Text)
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["GET "+repoAPI])
+	})
+}
+
+func TestRefreshVisibility(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	privateRepo := strings.Replace(utils.ReadTestdataFile("bitbucket-repo.json"), `"public": true`, `"public": false`, -1)
+	httpmock.RegisterResponder("GET", fmt.Sprintf(bitbucket.APIPaths["base"], server, fmt.Sprintf(bitbucket.APIPaths["repo"], project, repo)),
+		httpmock.NewStringResponder(200, privateRepo))
+
+	s := &fakeSlack{}
+	u := &Unfurl{
+		Logger:    logrus.StandardLogger(),
+		Bitbucket: &bitbucket.Client{Server: server, PAT: "my-token"},
+		Slack:     s,
+		Config:    &utils.Config{BitbucketServer: server, VisibilityPolicy: VisibilityPolicyRestricted},
+	}
+
+	value := actionValue{
+		Link:   fmt.Sprintf("https://%s:443/projects/%s/repos/%s/browse", server, project, repo),
+		Domain: server,
+	}
+
+	err := u.refresh(slack.InteractionCallback{User: slack.User{ID: "U123"}}, "C123", "1650000000.000100", value)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, len(s.unfurls))
+	assert.Assert(t, strings.Contains(s.unfurls[0], ":lock: Restricted"), s.unfurls[0])
+}
+
+func TestRestrict(t *testing.T) {
+	attachement := slack.Attachment{Title: "#297 My new feature", Text: "My awesome description"}
+
+	t.Run("should keep the attachment with an unknown policy", func(t *testing.T) {
+		u := Unfurl{Config: &utils.Config{VisibilityPolicy: "foo"}}
+		a, err := u.restrict(attachement)
+		assert.NilError(t, err)
+		assert.Equal(t, attachement.Text, a.Text)
+	})
+}
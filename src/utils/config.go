@@ -12,9 +12,113 @@ type Config struct {
 	BitbucketPAT    string `envconfig:"BITBUCKET_PAT" required:"true"`
 	BitbucketServer string `envconfig:"BITBUCKET_SERVER" required:"true"`
 	JenkinsServer   string `envconfig:"JENKINS_SERVER" required:"true"`
+	JenkinsUser     string `envconfig:"JENKINS_USER"`
+	JenkinsToken    string `envconfig:"JENKINS_TOKEN"`
 	SlackAppToken   string `envconfig:"SLACK_APP_TOKEN" required:"true"`
 	SLackBotToken   string `envconfig:"SLACK_BOT_TOKEN" required:"true"`
 	ChannelRegex    string `envconfig:"CHANNEL_REGEX" default:"^devops-([a-zA-Z0-9_]+)$"`
+
+	// ConfluenceServer enables unfurling of Confluence pages when set
+	ConfluenceServer string `envconfig:"CONFLUENCE_SERVER"`
+	ConfluencePAT    string `envconfig:"CONFLUENCE_PAT"`
+
+	// BitbucketCloudUsername and BitbucketCloudAppPassword, or
+	// BitbucketCloudToken, enable unfurling of bitbucket.org links
+	BitbucketCloudUsername    string `envconfig:"BITBUCKET_CLOUD_USERNAME"`
+	BitbucketCloudAppPassword string `envconfig:"BITBUCKET_CLOUD_APP_PASSWORD"`
+	BitbucketCloudToken       string `envconfig:"BITBUCKET_CLOUD_TOKEN"`
+
+	// GitHubServer enables unfurling of GitHub Enterprise links when set.
+	// GitHubAPIURL defaults to https://<GitHubServer>/api/v3.
+	GitHubServer string `envconfig:"GITHUB_SERVER"`
+	GitHubToken  string `envconfig:"GITHUB_TOKEN"`
+	GitHubAPIURL string `envconfig:"GITHUB_API_URL"`
+
+	// GitLabServer enables unfurling of GitLab links when set. GitLabAPIURL
+	// defaults to https://<GitLabServer>/api/v4.
+	GitLabServer string `envconfig:"GITLAB_SERVER"`
+	GitLabToken  string `envconfig:"GITLAB_TOKEN"`
+	GitLabAPIURL string `envconfig:"GITLAB_API_URL"`
+
+	// JiraServer enables unfurling of Jira issues when set
+	JiraServer string `envconfig:"JIRA_SERVER"`
+	JiraPAT    string `envconfig:"JIRA_PAT"`
+
+	// JiraFields are the issue fields shown in unfurls. Custom fields may be
+	// given a title, e.g. "customfield_10200=Team".
+	JiraFields []string `envconfig:"JIRA_FIELDS" default:"status,priority,assignee,reporter,issuetype,fixVersions,sprint"`
+
+	// JiraIssueLimit is the max number of Jira issues shown in Bitbucket
+	// unfurls.
+	JiraIssueLimit int `envconfig:"JIRA_ISSUE_LIMIT" default:"5"`
+
+	// JiraSprintField is the ID of the sprint custom field. It is looked up
+	// from Jira when empty.
+	JiraSprintField string `envconfig:"JIRA_SPRINT_FIELD"`
+
+	// BitbucketDiffStats adds the number of changed files and lines to pull
+	// request unfurls, with the BitbucketDiffFiles most changed files. Pull
+	// requests changing more than BitbucketLargePRLines lines are flagged.
+	BitbucketDiffStats    bool `envconfig:"BITBUCKET_DIFF_STATS" default:"false"`
+	BitbucketDiffFiles    int  `envconfig:"BITBUCKET_DIFF_FILES" default:"5"`
+	BitbucketLargePRLines int  `envconfig:"BITBUCKET_LARGE_PR_LINES" default:"1000"`
+
+	// BitbucketBuildDetails lists each build status with a link to the build
+	// in Bitbucket unfurls, failed builds first.
+	BitbucketBuildDetails bool `envconfig:"BITBUCKET_BUILD_DETAILS" default:"false"`
+
+	// BitbucketInsights adds the Code Insights reports of the latest commit to
+	// pull request and commit unfurls.
+	BitbucketInsights bool `envconfig:"BITBUCKET_INSIGHTS" default:"true"`
+
+	// BitbucketCompareCommits is the max number of commits listed in compare
+	// unfurls.
+	BitbucketCompareCommits int `envconfig:"BITBUCKET_COMPARE_COMMITS" default:"10"`
+
+	// BitbucketProjectRepos is the number of most recently active repos
+	// listed in project unfurls.
+	BitbucketProjectRepos int `envconfig:"BITBUCKET_PROJECT_REPOS" default:"5"`
+
+	// VisibilityPolicy is how links to private repositories are unfurled
+	// when not everyone in the channel may read them: off, title, restricted
+	// or hide.
+	VisibilityPolicy string `envconfig:"VISIBILITY_POLICY" default:"off"`
+
+	// RedactPatterns are extra regular expressions for secrets to redact from
+	// unfurls and logs, in addition to the built-in detectors. A capture group
+	// named "secret" limits the redaction to that group.
+	RedactPatterns []string `envconfig:"REDACT_PATTERNS"`
+
+	// JenkinsInputUsers maps Slack user IDs to Jenkins user names for users
+	// allowed to respond to pipeline input steps, e.g. "U012AB3CD:jdoe". Users
+	// respond with their linked Jenkins account, which must be the mapped one.
+	JenkinsInputUsers map[string]string `envconfig:"JENKINS_INPUT_USERS"`
+
+	// JenkinsJobHistory is the number of recent builds shown in Jenkins job
+	// unfurls.
+	JenkinsJobHistory int `envconfig:"JENKINS_JOB_HISTORY" default:"10"`
+
+	// JenkinsFailedTests is the max number of failed tests listed in Jenkins
+	// build unfurls.
+	JenkinsFailedTests int `envconfig:"JENKINS_FAILED_TESTS" default:"5"`
+
+	// JenkinsConsoleLog is how the console log of failed Jenkins builds is
+	// shown: inline, button or off. JenkinsConsoleLines lines are shown
+	// inline, up to half of them the last lines matching one of
+	// JenkinsConsoleErrorPatterns. The button opens the log for the user who
+	// clicks it only, so it is the default.
+	JenkinsConsoleLog           string   `envconfig:"JENKINS_CONSOLE_LOG" default:"button"`
+	JenkinsConsoleLines         int      `envconfig:"JENKINS_CONSOLE_LINES" default:"10"`
+	JenkinsConsoleErrorPatterns []string `envconfig:"JENKINS_CONSOLE_ERROR_PATTERNS" default:"(?i)\\b(error|failed|failure|exception|fatal)\\b"`
+
+	// AccountsKey is the base64 encoded 32 byte key used for encrypting the
+	// tokens of linked accounts. Account linking is disabled when empty.
+	AccountsKey                string `envconfig:"ACCOUNTS_KEY"`
+	AccountsFile               string `envconfig:"ACCOUNTS_FILE" default:"accounts.json"`
+	BitbucketOAuthClientID     string `envconfig:"BITBUCKET_OAUTH_CLIENT_ID"`
+	BitbucketOAuthClientSecret string `envconfig:"BITBUCKET_OAUTH_CLIENT_SECRET"`
+	PublicURL                  string `envconfig:"PUBLIC_URL"`
+	HTTPAddr                   string `envconfig:"HTTP_ADDR" default:":8080"`
 }
 
 // ConfigFromEnvironment loads config from env variables and .env file
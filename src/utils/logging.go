@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"github.com/evry-ace/link-unfurl-slack-bot/src/redact"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,13 +26,22 @@ func SetupLogging() {
 		logrus.SetLevel(logrus.InfoLevel)
 	}
 
+	var formatter logrus.Formatter
 	switch c.LogFormat {
 	case "text":
-		logrus.SetFormatter(new(logrus.TextFormatter))
+		formatter = new(logrus.TextFormatter)
 	case "json":
-		logrus.SetFormatter(new(logrus.JSONFormatter))
+		formatter = new(logrus.JSONFormatter)
 	default:
 		logrus.WithField("log-format", c.LogFormat).Warning("invalid log format. defaulting to text.")
-		logrus.SetFormatter(new(logrus.TextFormatter))
+		formatter = new(logrus.TextFormatter)
 	}
+
+	redactor, err := redact.New(c.RedactPatterns)
+	if err != nil {
+		logrus.WithError(err).Warning("invalid redact patterns. defaulting to built-in detectors.")
+		redactor = redact.Default
+	}
+
+	logrus.SetFormatter(&redact.Formatter{Formatter: formatter, Redactor: redactor})
 }